@@ -0,0 +1,290 @@
+package bt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+/*
+BEEF (Background Evaluation Extended Format) binary format
+--------------------------------------------------------
+Field            Description                                                               Size
+
+version          0x0100BEEF                                                                4 bytes
+
+nBUMPs           the number of BUMPs                                                       1 - 9 bytes VI = VarInt
+
+BUMPs            the BUMPs proving the confirmed txs, see bump.go                          <nBUMPs>-many BUMPs
+
+nTransactions    the number of txs                                                         1 - 9 bytes VI = VarInt
+
+transactions     for each tx, the raw tx followed by a hasBUMP byte, then if hasBUMP is   <nTransactions>-many txs
+                 0x01, the index of the BUMP proving the tx as a VarInt
+--------------------------------------------------------
+The txs are in topological order, each tx following the txs it spends, so the
+last tx is the one the BEEF is about.
+
+See https://brc.dev/62
+*/
+
+// beefVersion is the version of the BEEF format, as it is serialised.
+var beefVersion = []byte{0x01, 0x00, 0xBE, 0xEF}
+
+// BEEF is a tx packaged with its ancestors, so it can be validated without a node.
+// Ancestors which are unconfirmed are themselves included, while confirmed ones are
+// proven by a BUMP, all the way back.
+type BEEF struct {
+	BUMPs []*BUMP
+	Txs   []*BEEFTx
+}
+
+// BEEFTx is a tx within a BEEF. BUMP is the BUMP, one of BEEF.BUMPs, proving the tx
+// was mined, or nil if it is unconfirmed.
+type BEEFTx struct {
+	Tx   *Tx
+	BUMP *BUMP
+}
+
+// NewBEEF packages the txs into a BEEF, ordering them so each tx follows the txs it
+// spends. Each tx flagged in one of the provided BUMPs is marked as confirmed, and
+// only the BUMPs proving one of the txs are kept.
+//
+// The tx the BEEF is about should be the last provided, with the rest being its
+// ancestors.
+func NewBEEF(txs Txs, bumps ...*BUMP) (*BEEF, error) {
+	if len(txs) == 0 {
+		return nil, ErrBEEFNoTxs
+	}
+
+	byID := make(map[string]*Tx, len(txs))
+	for _, tx := range txs {
+		byID[tx.TxID()] = tx
+	}
+
+	bumpByID := make(map[string]*BUMP)
+	for _, bump := range bumps {
+		for _, txID := range bump.TxIDs() {
+			if _, ok := byID[txID]; ok {
+				bumpByID[txID] = bump
+			}
+		}
+	}
+
+	beef := &BEEF{Txs: make([]*BEEFTx, 0, len(txs))}
+	used := make(map[*BUMP]struct{})
+	visited := make(map[string]struct{}, len(txs))
+
+	// Each tx is added after the txs it spends, found by walking its inputs depth first.
+	// Confirmed txs are proven by their BUMP, so their inputs need not be walked.
+	var visit func(tx *Tx)
+	visit = func(tx *Tx) {
+		txID := tx.TxID()
+		if _, ok := visited[txID]; ok {
+			return
+		}
+		visited[txID] = struct{}{}
+
+		bump := bumpByID[txID]
+		if bump == nil {
+			for _, in := range tx.Inputs {
+				if parent, ok := byID[in.PreviousTxIDStr()]; ok {
+					visit(parent)
+				}
+			}
+		} else {
+			used[bump] = struct{}{}
+		}
+
+		beef.Txs = append(beef.Txs, &BEEFTx{Tx: tx, BUMP: bump})
+	}
+	for _, tx := range txs {
+		visit(tx)
+	}
+
+	for _, bump := range bumps {
+		if _, ok := used[bump]; ok {
+			beef.BUMPs = append(beef.BUMPs, bump)
+			delete(used, bump)
+		}
+	}
+
+	return beef, nil
+}
+
+// NewBEEFFromString takes a hex string representation of a binary BEEF and
+// returns a BEEF object.
+func NewBEEFFromString(str string) (*BEEF, error) {
+	bb, err := hex.DecodeString(str)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewBEEFFromBytes(bb)
+}
+
+// NewBEEFFromBytes takes an array of bytes of a binary BEEF, constructs a
+// BEEF and returns it.
+func NewBEEFFromBytes(b []byte) (*BEEF, error) {
+	beef := &BEEF{}
+	n, err := beef.ReadFrom(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	if int(n) != len(b) {
+		return nil, fmt.Errorf("%w: read %d of %d bytes", ErrBEEFTrailingData, n, len(b))
+	}
+
+	return beef, nil
+}
+
+// ReadFrom reads a binary BEEF from the `io.Reader` into the `bt.BEEF`.
+func (b *BEEF) ReadFrom(r io.Reader) (int64, error) {
+	*b = BEEF{}
+	var bytesRead int64
+
+	version := make([]byte, 4)
+	n, err := io.ReadFull(r, version)
+	bytesRead += int64(n)
+	if err != nil {
+		return bytesRead, errors.Wrapf(err, "version(4): got %d bytes", n)
+	}
+	if !bytes.Equal(version, beefVersion) {
+		return bytesRead, fmt.Errorf("%w: %x", ErrBEEFVersion, version)
+	}
+
+	var nBUMPs VarInt
+	n64, err := nBUMPs.ReadFrom(r)
+	bytesRead += n64
+	if err != nil {
+		return bytesRead, err
+	}
+
+	b.BUMPs = make([]*BUMP, 0)
+	for i := uint64(0); i < uint64(nBUMPs); i++ {
+		bump := &BUMP{}
+		n64, err = bump.ReadFrom(r)
+		bytesRead += n64
+		if err != nil {
+			return bytesRead, errors.Wrapf(err, "bump %d", i)
+		}
+		b.BUMPs = append(b.BUMPs, bump)
+	}
+
+	var nTxs VarInt
+	n64, err = nTxs.ReadFrom(r)
+	bytesRead += n64
+	if err != nil {
+		return bytesRead, err
+	}
+	if nTxs == 0 {
+		return bytesRead, ErrBEEFNoTxs
+	}
+
+	b.Txs = make([]*BEEFTx, 0)
+	for i := uint64(0); i < uint64(nTxs); i++ {
+		btx := &BEEFTx{Tx: &Tx{}}
+		n64, err = btx.Tx.ReadFrom(r)
+		bytesRead += n64
+		if err != nil {
+			return bytesRead, errors.Wrapf(err, "tx %d", i)
+		}
+
+		hasBUMP := make([]byte, 1)
+		n, err = io.ReadFull(r, hasBUMP)
+		bytesRead += int64(n)
+		if err != nil {
+			return bytesRead, errors.Wrapf(err, "hasBUMP(1): got %d bytes", n)
+		}
+
+		switch hasBUMP[0] {
+		case 0x00:
+		case 0x01:
+			var idx VarInt
+			n64, err = idx.ReadFrom(r)
+			bytesRead += n64
+			if err != nil {
+				return bytesRead, err
+			}
+			if uint64(idx) >= uint64(len(b.BUMPs)) {
+				return bytesRead, fmt.Errorf("%w: tx %d has bump index %d of %d", ErrBEEFBUMPIndex, i, idx, len(b.BUMPs))
+			}
+			btx.BUMP = b.BUMPs[idx]
+		default:
+			return bytesRead, fmt.Errorf("%w: tx %d has hasBUMP flag %x", ErrBEEFInvalid, i, hasBUMP[0])
+		}
+
+		b.Txs = append(b.Txs, btx)
+	}
+
+	return bytesRead, nil
+}
+
+// Bytes encodes the BEEF into its binary format.
+func (b *BEEF) Bytes() ([]byte, error) {
+	if len(b.Txs) == 0 {
+		return nil, ErrBEEFNoTxs
+	}
+
+	idxs := make(map[*BUMP]uint64, len(b.BUMPs))
+
+	h := append([]byte{}, beefVersion...)
+	h = append(h, VarInt(uint64(len(b.BUMPs))).Bytes()...)
+	for i, bump := range b.BUMPs {
+		bb, err := bump.Bytes()
+		if err != nil {
+			return nil, errors.Wrapf(err, "bump %d", i)
+		}
+		h = append(h, bb...)
+		idxs[bump] = uint64(i)
+	}
+
+	h = append(h, VarInt(uint64(len(b.Txs))).Bytes()...)
+	for i, btx := range b.Txs {
+		h = append(h, btx.Tx.Bytes()...)
+		if btx.BUMP == nil {
+			h = append(h, 0x00)
+			continue
+		}
+
+		idx, ok := idxs[btx.BUMP]
+		if !ok {
+			return nil, fmt.Errorf("%w: bump of tx %d is not in the BEEF", ErrBEEFBUMPIndex, i)
+		}
+		h = append(h, 0x01)
+		h = append(h, VarInt(idx).Bytes()...)
+	}
+
+	return h, nil
+}
+
+// String encodes the BEEF into its binary format as a hex string.
+func (b *BEEF) String() string {
+	bb, err := b.Bytes()
+	if err != nil {
+		return ""
+	}
+	return hex.EncodeToString(bb)
+}
+
+// Tx returns the tx the BEEF is about, being the last tx, or nil if there are no txs.
+func (b *BEEF) Tx() *Tx {
+	if len(b.Txs) == 0 {
+		return nil
+	}
+	return b.Txs[len(b.Txs)-1].Tx
+}
+
+// FindTx returns the tx within the BEEF with the provided txid, given as a hex string
+// in display order, or nil if the BEEF does not include it.
+func (b *BEEF) FindTx(txID string) *BEEFTx {
+	for _, btx := range b.Txs {
+		if btx.Tx.TxID() == txID {
+			return btx
+		}
+	}
+	return nil
+}
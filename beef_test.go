@@ -0,0 +1,94 @@
+package bt_test
+
+import (
+	"testing"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// spendingTx builds an unsigned tx spending the first output of the parent.
+func spendingTx(t *testing.T, parent *bt.Tx) *bt.Tx {
+	tx := bt.NewTx()
+	out := parent.Outputs[0]
+	assert.NoError(t, tx.From(parent.TxID(), 0, out.LockingScriptHexString(), out.Satoshis))
+	assert.NoError(t, tx.PayToAddress(testP2PKHAddress, 1))
+	return tx
+}
+
+func TestNewBEEF(t *testing.T) {
+	t.Parallel()
+
+	blk := loadTestBlock(t)
+	confirmed := blk.Txs[5]
+	bump, err := blk.Txs.BUMP(1000, 5)
+	assert.NoError(t, err)
+	unused, err := blk.Txs.BUMP(1000, 6)
+	assert.NoError(t, err)
+
+	child := spendingTx(t, confirmed)
+	grandchild := spendingTx(t, child)
+
+	beef, err := bt.NewBEEF(bt.Txs{grandchild, confirmed, child}, unused, bump)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []*bt.BUMP{bump}, beef.BUMPs)
+	assert.Equal(t, []*bt.BEEFTx{
+		{Tx: confirmed, BUMP: bump},
+		{Tx: child},
+		{Tx: grandchild},
+	}, beef.Txs)
+	assert.Equal(t, grandchild, beef.Tx())
+	assert.Equal(t, child, beef.FindTx(child.TxID()).Tx)
+	assert.Nil(t, beef.FindTx(blk.Txs[6].TxID()))
+
+	_, err = bt.NewBEEF(nil)
+	assert.ErrorIs(t, err, bt.ErrBEEFNoTxs)
+
+	t.Run("binary round trip", func(t *testing.T) {
+		b, err := beef.Bytes()
+		assert.NoError(t, err)
+
+		beef2, err := bt.NewBEEFFromString(beef.String())
+		assert.NoError(t, err)
+		assert.Len(t, beef2.Txs, 3)
+		assert.Equal(t, beef2.BUMPs[0], beef2.Txs[0].BUMP)
+		assert.Nil(t, beef2.Txs[1].BUMP)
+		assert.Equal(t, grandchild.TxID(), beef2.Tx().TxID())
+
+		b2, err := beef2.Bytes()
+		assert.NoError(t, err)
+		assert.Equal(t, b, b2)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		b, err := beef.Bytes()
+		assert.NoError(t, err)
+
+		_, err = bt.NewBEEFFromBytes(append([]byte{0x02}, b[1:]...))
+		assert.ErrorIs(t, err, bt.ErrBEEFVersion)
+
+		_, err = bt.NewBEEFFromBytes(append(b, 0x00))
+		assert.ErrorIs(t, err, bt.ErrBEEFTrailingData)
+
+		_, err = bt.NewBEEFFromBytes(b[:len(b)-1])
+		assert.Error(t, err)
+
+		_, err = (&bt.BEEF{Txs: []*bt.BEEFTx{{Tx: confirmed, BUMP: unused}}}).Bytes()
+		assert.ErrorIs(t, err, bt.ErrBEEFBUMPIndex)
+	})
+}
+
+func TestNewBEEFFromBytes_HugeCounts(t *testing.T) {
+	t.Parallel()
+
+	huge := []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}
+	version := []byte{0x01, 0x00, 0xBE, 0xEF}
+
+	_, err := bt.NewBEEFFromBytes(append(append([]byte{}, version...), huge...))
+	assert.Error(t, err)
+
+	noBUMPs := append(append([]byte{}, version...), 0x00)
+	_, err = bt.NewBEEFFromBytes(append(noBUMPs, huge...))
+	assert.Error(t, err)
+}
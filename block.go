@@ -0,0 +1,307 @@
+package bt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/mvc-labs/mvc-lib-go/keys/crypto"
+	"github.com/pkg/errors"
+)
+
+/*
+General format of a block header
+--------------------------------------------------------
+Field            Description                                                               Size
+
+Version          block version number                                                      4 bytes
+
+hashPrevBlock    hash of the previous block header                                         32 bytes
+
+hashMerkleRoot   merkle root of all the transactions in the block                         32 bytes
+
+Time             current block timestamp as seconds since 1970-01-01T00:00 UTC             4 bytes
+
+Bits             current target in compact format                                          4 bytes
+
+Nonce            32-bit number (starts at 0)                                               4 bytes
+--------------------------------------------------------
+*/
+
+// BlockHeaderLen is the length in bytes of a serialised block header.
+const BlockHeaderLen = 80
+
+// BlockHeader wraps a block header.
+//
+// The PrevHash and MerkleRoot are stored in display (big endian) order, the
+// same way a TxID is, and are reversed when serialised.
+type BlockHeader struct {
+	PrevHash   []byte
+	MerkleRoot []byte
+	Version    uint32
+	Time       uint32
+	Bits       uint32
+	Nonce      uint32
+}
+
+// NewBlockHeaderFromString takes a hex string representation of a block header
+// and returns a BlockHeader object.
+func NewBlockHeaderFromString(str string) (*BlockHeader, error) {
+	bb, err := hex.DecodeString(str)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewBlockHeaderFromBytes(bb)
+}
+
+// NewBlockHeaderFromBytes takes an array of bytes, constructs a BlockHeader and
+// returns it. This function assumes that the byte slice contains exactly 1 header.
+func NewBlockHeaderFromBytes(b []byte) (*BlockHeader, error) {
+	if len(b) != BlockHeaderLen {
+		return nil, fmt.Errorf("%w: got %d bytes", ErrBlockHeaderLength, len(b))
+	}
+
+	bh := &BlockHeader{}
+	if _, err := bh.ReadFrom(bytes.NewReader(b)); err != nil {
+		return nil, err
+	}
+
+	return bh, nil
+}
+
+// ReadFrom reads from the `io.Reader` into the `bt.BlockHeader`.
+func (bh *BlockHeader) ReadFrom(r io.Reader) (int64, error) {
+	*bh = BlockHeader{}
+
+	b := make([]byte, BlockHeaderLen)
+	n, err := io.ReadFull(r, b)
+	if err != nil {
+		return int64(n), errors.Wrapf(err, "blockHeader(%d): got %d bytes", BlockHeaderLen, n)
+	}
+
+	bh.Version = binary.LittleEndian.Uint32(b[0:4])
+	bh.PrevHash = ReverseBytes(b[4:36])
+	bh.MerkleRoot = ReverseBytes(b[36:68])
+	bh.Time = binary.LittleEndian.Uint32(b[68:72])
+	bh.Bits = binary.LittleEndian.Uint32(b[72:76])
+	bh.Nonce = binary.LittleEndian.Uint32(b[76:80])
+
+	return int64(n), nil
+}
+
+// Bytes encodes the block header into a byte array.
+func (bh *BlockHeader) Bytes() []byte {
+	h := make([]byte, 0, BlockHeaderLen)
+
+	h = append(h, LittleEndianBytes(bh.Version, 4)...)
+	h = append(h, hashBytes(bh.PrevHash)...)
+	h = append(h, hashBytes(bh.MerkleRoot)...)
+	h = append(h, LittleEndianBytes(bh.Time, 4)...)
+	h = append(h, LittleEndianBytes(bh.Bits, 4)...)
+
+	return append(h, LittleEndianBytes(bh.Nonce, 4)...)
+}
+
+// String encodes the block header into a hex string.
+func (bh *BlockHeader) String() string {
+	return hex.EncodeToString(bh.Bytes())
+}
+
+// Hash returns the hash of the block header as bytes, in display order.
+func (bh *BlockHeader) Hash() []byte {
+	return ReverseBytes(crypto.Sha256d(bh.Bytes()))
+}
+
+// HashStr returns the hash of the block header as a hex string.
+func (bh *BlockHeader) HashStr() string {
+	return hex.EncodeToString(bh.Hash())
+}
+
+// PrevHashStr returns the hash of the previous block header as a hex string.
+func (bh *BlockHeader) PrevHashStr() string {
+	return hex.EncodeToString(bh.PrevHash)
+}
+
+// MerkleRootStr returns the merkle root of the block as a hex string.
+func (bh *BlockHeader) MerkleRootStr() string {
+	return hex.EncodeToString(bh.MerkleRoot)
+}
+
+// BitsStr returns the compact target of the block as a hex string,
+// as displayed by the node.
+func (bh *BlockHeader) BitsStr() string {
+	return hex.EncodeToString(ReverseBytes(LittleEndianBytes(bh.Bits, 4)))
+}
+
+// Block wraps a block header and the transactions it contains.
+type Block struct {
+	Header *BlockHeader
+	Txs    Txs
+}
+
+// NewBlockFromString takes a hex string representation of a block
+// and returns a Block object.
+func NewBlockFromString(str string) (*Block, error) {
+	bb, err := hex.DecodeString(str)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewBlockFromBytes(bb)
+}
+
+// NewBlockFromBytes takes an array of bytes, constructs a Block and returns it.
+// This function assumes that the byte slice contains exactly 1 block.
+func NewBlockFromBytes(b []byte) (*Block, error) {
+	blk := &Block{}
+	n, err := blk.ReadFrom(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	if int(n) != len(b) {
+		return nil, fmt.Errorf("%w: read %d of %d bytes", ErrBlockTrailingData, n, len(b))
+	}
+
+	return blk, nil
+}
+
+// ReadFrom reads from the `io.Reader` into the `bt.Block`. The header is read
+// first, followed by the tx count and the txs themselves.
+//
+// For large blocks, consider using a `bt.BlockReader` instead, which doesn't
+// require the whole block to be held in memory.
+func (b *Block) ReadFrom(r io.Reader) (int64, error) {
+	*b = Block{Header: &BlockHeader{}}
+
+	bytesRead, err := b.Header.ReadFrom(r)
+	if err != nil {
+		return bytesRead, err
+	}
+
+	n, err := b.Txs.ReadFrom(r)
+	bytesRead += n
+
+	return bytesRead, err
+}
+
+// Bytes encodes the block into a byte array.
+func (b *Block) Bytes() []byte {
+	h := make([]byte, 0)
+
+	h = append(h, b.Header.Bytes()...)
+	h = append(h, VarInt(uint64(len(b.Txs))).Bytes()...)
+	for _, tx := range b.Txs {
+		h = append(h, tx.Bytes()...)
+	}
+
+	return h
+}
+
+// String encodes the block into a hex string.
+func (b *Block) String() string {
+	return hex.EncodeToString(b.Bytes())
+}
+
+// Hash returns the hash of the block as bytes, in display order.
+func (b *Block) Hash() []byte {
+	return b.Header.Hash()
+}
+
+// HashStr returns the hash of the block as a hex string.
+func (b *Block) HashStr() string {
+	return b.Header.HashStr()
+}
+
+// BlockReader reads a block from an `io.Reader` one tx at a time, so at no point
+// does the entire block have to be held in memory.
+//
+// Example usage:
+//
+//	br, err := bt.NewBlockReader(r)
+//	if err != nil {}
+//	for {
+//	    tx, err := br.Next()
+//	    if errors.Is(err, io.EOF) {
+//	        break
+//	    }
+//	    if err != nil {}
+//	    fmt.Println(tx.TxID())
+//	}
+type BlockReader struct {
+	r         io.Reader
+	header    *BlockHeader
+	txCount   uint64
+	txsRead   uint64
+	bytesRead int64
+}
+
+// NewBlockReader reads the block header and tx count from the `io.Reader` and
+// returns a `bt.BlockReader` ready to read the txs that follow.
+func NewBlockReader(r io.Reader) (*BlockReader, error) {
+	br := &BlockReader{
+		r:      r,
+		header: &BlockHeader{},
+	}
+
+	n, err := br.header.ReadFrom(r)
+	br.bytesRead += n
+	if err != nil {
+		return nil, err
+	}
+
+	var txCount VarInt
+	n, err = txCount.ReadFrom(r)
+	br.bytesRead += n
+	if err != nil {
+		return nil, err
+	}
+	br.txCount = uint64(txCount)
+
+	return br, nil
+}
+
+// Header returns the header of the block being read.
+func (br *BlockReader) Header() *BlockHeader {
+	return br.header
+}
+
+// TxCount returns the total number of txs in the block being read.
+func (br *BlockReader) TxCount() uint64 {
+	return br.txCount
+}
+
+// BytesRead returns the number of bytes consumed from the underlying reader.
+func (br *BlockReader) BytesRead() int64 {
+	return br.bytesRead
+}
+
+// Next reads and returns the next tx in the block. Once all txs have been read,
+// io.EOF is returned.
+func (br *BlockReader) Next() (*Tx, error) {
+	if br.txsRead >= br.txCount {
+		return nil, io.EOF
+	}
+
+	tx := new(Tx)
+	n, err := tx.ReadFrom(br.r)
+	br.bytesRead += n
+	if err != nil {
+		return nil, errors.Wrapf(err, "tx %d", br.txsRead)
+	}
+	br.txsRead++
+
+	return tx, nil
+}
+
+// hashBytes returns the wire representation of a display order hash, defaulting
+// to 32 zero bytes if none is set.
+func hashBytes(h []byte) []byte {
+	if len(h) == 0 {
+		return make([]byte, 32)
+	}
+	return ReverseBytes(h)
+}
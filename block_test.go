@@ -0,0 +1,89 @@
+package bt_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/testing/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlockHeader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("genesis header", func(t *testing.T) {
+		bh, err := bt.NewBlockHeaderFromString("0100000000000000000000000000000000000000000000000000000000000000000000003ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a29ab5f49ffff001d1dac2b7c")
+		assert.NoError(t, err)
+
+		assert.Equal(t, uint32(1), bh.Version)
+		assert.Equal(t, "0000000000000000000000000000000000000000000000000000000000000000", bh.PrevHashStr())
+		assert.Equal(t, "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b", bh.MerkleRootStr())
+		assert.Equal(t, uint32(1231006505), bh.Time)
+		assert.Equal(t, "1d00ffff", bh.BitsStr())
+		assert.Equal(t, uint32(2083236893), bh.Nonce)
+		assert.Equal(t, "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f", bh.HashStr())
+		assert.Equal(t, "0100000000000000000000000000000000000000000000000000000000000000000000003ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a29ab5f49ffff001d1dac2b7c", bh.String())
+	})
+
+	t.Run("invalid length", func(t *testing.T) {
+		_, err := bt.NewBlockHeaderFromString("0100000000")
+		assert.ErrorIs(t, err, bt.ErrBlockHeaderLength)
+	})
+}
+
+func TestBlock_ReadFrom(t *testing.T) {
+	t.Parallel()
+
+	b, err := data.TxBinData.Load("block.bin")
+	assert.NoError(t, err)
+
+	blk, err := bt.NewBlockFromBytes(b)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "000000000000000004157b868ef6d0f6eab38e3fd7d66543bebe7b11afafbcec", blk.HashStr())
+	assert.Equal(t, 648, len(blk.Txs))
+	assert.True(t, blk.Txs[0].IsCoinbase())
+	assert.Equal(t, b, blk.Bytes())
+
+	_, err = bt.NewBlockFromBytes(append(b, 0x00))
+	assert.ErrorIs(t, err, bt.ErrBlockTrailingData)
+}
+
+func TestBlockReader_Next(t *testing.T) {
+	t.Parallel()
+
+	b, err := data.TxBinData.Load("block.bin")
+	assert.NoError(t, err)
+
+	blk, err := bt.NewBlockFromBytes(b)
+	assert.NoError(t, err)
+
+	br, err := bt.NewBlockReader(bytes.NewReader(b))
+	assert.NoError(t, err)
+	assert.Equal(t, blk.HashStr(), br.Header().HashStr())
+	assert.Equal(t, uint64(len(blk.Txs)), br.TxCount())
+
+	var i int
+	for {
+		tx, err := br.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, blk.Txs[i].TxID(), tx.TxID())
+		i++
+	}
+	assert.Equal(t, len(blk.Txs), i)
+	assert.Equal(t, int64(len(b)), br.BytesRead())
+
+	t.Run("truncated block", func(t *testing.T) {
+		br, err := bt.NewBlockReader(bytes.NewReader(b[:121]))
+		assert.NoError(t, err)
+
+		_, err = br.Next()
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	})
+}
@@ -2,119 +2,212 @@
 package bscript
 
 import (
+	"bytes"
 	"encoding/hex"
 	"fmt"
 
 	"github.com/mvc-labs/mvc-lib-go/keys/base58"
 	"github.com/mvc-labs/mvc-lib-go/keys/bec"
+	"github.com/mvc-labs/mvc-lib-go/keys/chaincfg"
 	"github.com/mvc-labs/mvc-lib-go/keys/crypto"
 )
 
+// AddressType is the kind of locking script an address pays to.
+type AddressType string
+
+// Supported address types.
 const (
-	hashP2PKH        = 0x00
-	hashTestNetP2PKH = 0x6f
-	hashP2SH         = 0x05 // TODO: remove deprecated p2sh stuff
-	hashTestNetP2SH  = 0xc4
+	AddressTypeP2PKH AddressType = "pubkeyhash"
+	AddressTypeP2SH  AddressType = "scripthash"
 )
 
-// An Address struct contains the address string as well as the hash160 hex string of the public key.
-// The address string will be human-readable and specific to the network type, but the public key hash
+// An Address struct contains the address string as well as the hash160 hex string it pays to,
+// being the hash of a public key for a P2PKH address, or of a redeem script for a P2SH address.
+// The address string will be human-readable and specific to the network type, but the hash
 // is useful because it stays the same regardless of the network type (mainnet, testnet).
 type Address struct {
 	AddressString string
+	// PublicKeyHash is set for a P2PKH address.
 	PublicKeyHash string
+	// ScriptHash is set for a P2SH address.
+	ScriptHash string
+
+	Type AddressType
+	// Net is the network the address is for. As networks can share address prefixes,
+	// as testnet and regtest do, an address decoded with NewAddressFromString is for
+	// the first network registered with its prefix. Use NewAddressFromStringForNet
+	// to pick the network instead.
+	Net *chaincfg.Params
 }
 
-// NewAddressFromString takes a string address (P2PKH) and returns a pointer to an Address
-// which contains the address string as well as the public key hash string.
+// NewAddressFromString takes a string address (P2PKH or P2SH) of any network registered
+// in chaincfg, and returns a pointer to an Address which contains the address string as
+// well as the hash string it pays to.
 func NewAddressFromString(addr string) (*Address, error) {
-	pkh, err := addressToPubKeyHashStr(addr)
-	if err != nil {
-		return nil, err
+	decoded := base58.Decode(addr)
+	if len(decoded) != 25 {
+		return nil, fmt.Errorf("%w for '%s'", ErrInvalidAddressLength, addr)
 	}
-	return &Address{
-		AddressString: addr,
-		PublicKeyHash: pkh,
-	}, nil
-}
 
-func addressToPubKeyHashStr(address string) (string, error) {
-	decoded := base58.Decode(address)
+	version, hash := decoded[0], hex.EncodeToString(decoded[1:21])
+	a := &Address{AddressString: addr}
+	var nets []*chaincfg.Params
+	switch {
+	case chaincfg.IsPubKeyHashAddrID(version):
+		a.Type, a.PublicKeyHash = AddressTypeP2PKH, hash
+		nets, _ = chaincfg.ParamsForPubKeyHashAddrID(version)
+	case chaincfg.IsScriptHashAddrID(version):
+		a.Type, a.ScriptHash = AddressTypeP2SH, hash
+		nets, _ = chaincfg.ParamsForScriptHashAddrID(version)
+	default:
+		return nil, fmt.Errorf("%w %s", ErrUnsupportedAddress, addr)
+	}
 
-	if len(decoded) != 25 {
-		return "", fmt.Errorf("%w for '%s'", ErrInvalidAddressLength, address)
+	ckSum := checksum(decoded[:21])
+	if !bytes.Equal(ckSum[:], decoded[21:]) {
+		return nil, fmt.Errorf("%w for '%s'", ErrEncodingChecksumFailed, addr)
 	}
 
-	switch decoded[0] {
-	case hashP2PKH: // Pubkey hash (P2PKH address)
-		return hex.EncodeToString(decoded[1 : len(decoded)-4]), nil
+	a.Net = nets[0]
+	return a, nil
+}
 
-	case hashTestNetP2PKH: // Testnet pubkey hash (P2PKH address)
-		return hex.EncodeToString(decoded[1 : len(decoded)-4]), nil
+// NewAddressFromStringForNet takes a string address (P2PKH or P2SH) for the provided network,
+// and returns a pointer to an Address for that network, even if it shares its prefix with
+// other networks. ErrWrongNetwork is returned if the address is for a network using another
+// prefix.
+func NewAddressFromStringForNet(addr string, net *chaincfg.Params) (*Address, error) {
+	if net == nil {
+		return nil, ErrNoNetwork
+	}
 
-	case hashP2SH: // Script hash (P2SH address)
-		fallthrough
-	case hashTestNetP2SH: // Testnet script hash (P2SH address)
-		fallthrough
-	default:
-		return "", fmt.Errorf("%w %s", ErrUnsupportedAddress, address)
+	a, err := NewAddressFromString(addr)
+	if err != nil {
+		return nil, err
 	}
+	if !a.IsForNet(net) {
+		return nil, fmt.Errorf("%w %s: %s", ErrWrongNetwork, net.Name, addr)
+	}
+
+	a.Net = net
+	return a, nil
 }
 
-// NewAddressFromPublicKeyString takes a public key string and returns an Address struct pointer.
-// If mainnet parameter is true it will return a mainnet address (starting with a 1).
-// Otherwise, (mainnet is false) it will return a testnet address (starting with an m or n).
-func NewAddressFromPublicKeyString(pubKey string, mainnet bool) (*Address, error) {
+// NewAddressFromPublicKeyString takes a public key string and returns an Address struct pointer
+// for the provided network, for example `&chaincfg.MainNet` for an address starting with a 1, or
+// `&chaincfg.TestNet` for one starting with an m or n.
+func NewAddressFromPublicKeyString(pubKey string, net *chaincfg.Params) (*Address, error) {
 	pubKeyBytes, err := hex.DecodeString(pubKey)
 	if err != nil {
 		return nil, err
 	}
-	return NewAddressFromPublicKeyHash(crypto.Hash160(pubKeyBytes), mainnet)
+	return NewAddressFromPublicKeyHash(crypto.Hash160(pubKeyBytes), net)
 }
 
-// NewAddressFromPublicKeyHash takes a public key hash in bytes and returns an Address struct pointer.
-// If mainnet parameter is true it will return a mainnet address (starting with a 1).
-// Otherwise, (mainnet is false) it will return a testnet address (starting with an m or n).
-func NewAddressFromPublicKeyHash(hash []byte, mainnet bool) (*Address, error) {
-
-	// regtest := 111
-	// mainnet: 0
-
-	bb := make([]byte, 1)
-	if !mainnet {
-		bb[0] = 111
+// NewAddressFromPublicKeyHash takes a public key hash in bytes and returns an Address struct pointer
+// for the provided network.
+func NewAddressFromPublicKeyHash(hash []byte, net *chaincfg.Params) (*Address, error) {
+	if net == nil {
+		return nil, ErrNoNetwork
 	}
-	//nolint: makezero // we need to set up the array with 1
-	bb = append(bb, hash...)
 
 	return &Address{
-		AddressString: Base58EncodeMissingChecksum(bb),
+		AddressString: encodeAddress(net.LegacyPubKeyHashAddrID, hash),
 		PublicKeyHash: hex.EncodeToString(hash),
+		Type:          AddressTypeP2PKH,
+		Net:           net,
 	}, nil
 }
 
-// NewAddressFromPublicKey takes a bec public key and returns an Address struct pointer.
-// If mainnet parameter is true it will return a mainnet address (starting with a 1).
-// Otherwise, (mainnet is false) it will return a testnet address (starting with an m or n).
-func NewAddressFromPublicKey(pubKey *bec.PublicKey, mainnet bool) (*Address, error) {
-	hash := crypto.Hash160(pubKey.SerialiseCompressed())
-
-	// regtest := 111
-	// mainnet: 0
+// NewAddressFromPublicKey takes a bec public key and returns an Address struct pointer
+// for the provided network.
+func NewAddressFromPublicKey(pubKey *bec.PublicKey, net *chaincfg.Params) (*Address, error) {
+	return NewAddressFromPublicKeyHash(crypto.Hash160(pubKey.SerialiseCompressed()), net)
+}
 
-	bb := make([]byte, 1)
-	if !mainnet {
-		bb[0] = 111
+// NewAddressFromScriptHash takes the hash160 of a redeem script in bytes and returns a P2SH
+// Address struct pointer for the provided network.
+func NewAddressFromScriptHash(hash []byte, net *chaincfg.Params) (*Address, error) {
+	if net == nil {
+		return nil, ErrNoNetwork
+	}
+	if len(hash) != 20 {
+		return nil, fmt.Errorf("%w: script hash is %d bytes", ErrInvalidAddressLength, len(hash))
 	}
-	//nolint: makezero // we need to set up the array with 1
-	bb = append(bb, hash...)
 
 	return &Address{
-		AddressString: Base58EncodeMissingChecksum(bb),
-		PublicKeyHash: hex.EncodeToString(hash),
+		AddressString: encodeAddress(net.LegacyScriptHashAddrID, hash),
+		ScriptHash:    hex.EncodeToString(hash),
+		Type:          AddressTypeP2SH,
+		Net:           net,
 	}, nil
 }
 
+// NewAddressFromRedeemScript takes a redeem script and returns a P2SH Address struct pointer,
+// paying to the hash of the script, for the provided network.
+func NewAddressFromRedeemScript(redeemScript *Script, net *chaincfg.Params) (*Address, error) {
+	return NewAddressFromScriptHash(crypto.Hash160(*redeemScript), net)
+}
+
+// NewAddressFromLockingScript takes a P2PKH or P2SH locking script and returns the Address
+// struct pointer it pays to, for the provided network. ErrUnsupportedAddress is returned for
+// any other kind of script.
+func NewAddressFromLockingScript(lockingScript *Script, net *chaincfg.Params) (*Address, error) {
+	switch {
+	case lockingScript.IsP2PKH():
+		return NewAddressFromPublicKeyHash((*lockingScript)[3:23], net)
+	case lockingScript.IsP2SH():
+		return NewAddressFromScriptHash((*lockingScript)[2:22], net)
+	}
+
+	return nil, fmt.Errorf("%w: %s script", ErrUnsupportedAddress, lockingScript.ScriptType())
+}
+
+// LockingScript returns the locking script paying to the address.
+func (a *Address) LockingScript() (*Script, error) {
+	switch a.Type {
+	case AddressTypeP2PKH:
+		return NewP2PKHFromPubKeyHashStr(a.PublicKeyHash)
+	case AddressTypeP2SH:
+		hash, err := hex.DecodeString(a.ScriptHash)
+		if err != nil {
+			return nil, err
+		}
+		return NewP2SHFromScriptHash(hash)
+	}
+
+	return nil, fmt.Errorf("%w %s", ErrUnsupportedAddress, a.AddressString)
+}
+
+// IsForNet returns whether the address is valid on the provided network, being
+// true for each network sharing the prefix of the address.
+func (a *Address) IsForNet(net *chaincfg.Params) bool {
+	if a.Net == nil || net == nil {
+		return false
+	}
+
+	switch a.Type {
+	case AddressTypeP2PKH:
+		return a.Net.LegacyPubKeyHashAddrID == net.LegacyPubKeyHashAddrID
+	case AddressTypeP2SH:
+		return a.Net.LegacyScriptHashAddrID == net.LegacyScriptHashAddrID
+	}
+
+	return false
+}
+
+// String returns the address string.
+func (a *Address) String() string {
+	return a.AddressString
+}
+
+func encodeAddress(version byte, hash []byte) string {
+	bb := make([]byte, 0, 1+len(hash))
+	bb = append(bb, version)
+	bb = append(bb, hash...)
+	return Base58EncodeMissingChecksum(bb)
+}
+
 // Base58EncodeMissingChecksum appends a checksum to a byte sequence
 // then encodes into base58 encoding.
 func Base58EncodeMissingChecksum(input []byte) string {
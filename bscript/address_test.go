@@ -6,10 +6,16 @@ import (
 
 	"github.com/mvc-labs/mvc-lib-go/keys/bec"
 	"github.com/mvc-labs/mvc-lib-go/bscript"
+	"github.com/mvc-labs/mvc-lib-go/keys/chaincfg"
+	"github.com/mvc-labs/mvc-lib-go/keys/crypto"
 	"github.com/stretchr/testify/assert"
 )
 
-const testPublicKeyHash = "00ac6144c4db7b5790f343cf0477a65fb8a02eb7"
+const (
+	testPublicKeyHash = "00ac6144c4db7b5790f343cf0477a65fb8a02eb7"
+	testScriptHash    = "f815b036d9bbbce5e9f2a00abd1bf3dc91e95510"
+	testP2SHAddress   = "3QJmV3qfvL9SuYo34YihAf3sRCW3qSinyC"
+)
 
 func TestNewAddressFromString(t *testing.T) {
 	t.Parallel()
@@ -23,6 +29,8 @@ func TestNewAddressFromString(t *testing.T) {
 
 		assert.Equal(t, "8fe80c75c9560e8b56ed64ea3c26e18d2c52211b", addr.PublicKeyHash, addressMain)
 		assert.Equal(t, addressMain, addr.AddressString)
+		assert.Equal(t, bscript.AddressTypeP2PKH, addr.Type)
+		assert.Equal(t, &chaincfg.MainNet, addr.Net)
 	})
 
 	t.Run("testnet", func(t *testing.T) {
@@ -36,6 +44,34 @@ func TestNewAddressFromString(t *testing.T) {
 		assert.Equal(t, addressTestnet, addr.AddressString)
 	})
 
+	t.Run("mainnet P2SH", func(t *testing.T) {
+		addr, err := bscript.NewAddressFromString(testP2SHAddress)
+		assert.NoError(t, err)
+
+		assert.Equal(t, bscript.AddressTypeP2SH, addr.Type)
+		assert.Equal(t, &chaincfg.MainNet, addr.Net)
+		assert.Equal(t, testScriptHash, addr.ScriptHash)
+		assert.Empty(t, addr.PublicKeyHash)
+		assert.Equal(t, testP2SHAddress, addr.String())
+	})
+
+	t.Run("testnet P2SH", func(t *testing.T) {
+		addr, err := bscript.NewAddressFromString("2NFryYnmhXneo7LRajgLZnc38dYiDePvf3G")
+		assert.NoError(t, err)
+
+		assert.Equal(t, bscript.AddressTypeP2SH, addr.Type)
+		assert.Equal(t, &chaincfg.TestNet, addr.Net)
+		assert.True(t, addr.IsForNet(&chaincfg.RegTest))
+		assert.False(t, addr.IsForNet(&chaincfg.MainNet))
+		assert.Equal(t, testScriptHash, addr.ScriptHash)
+	})
+
+	t.Run("bad checksum", func(t *testing.T) {
+		addr, err := bscript.NewAddressFromString("3QJmV3qfvL9SuYo34YihAf3sRCW3qSinyD")
+		assert.ErrorIs(t, err, bscript.ErrEncodingChecksumFailed)
+		assert.Nil(t, addr)
+	})
+
 	t.Run("short address", func(t *testing.T) {
 		shortAddress := "ADD8E55"
 		addr, err := bscript.NewAddressFromString(shortAddress)
@@ -54,13 +90,69 @@ func TestNewAddressFromString(t *testing.T) {
 
 }
 
+func TestNewAddressFromStringForNet(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		addr   string
+		net    *chaincfg.Params
+		expErr error
+	}{
+		"mainnet": {
+			addr: "1E7ucTTWRTahCyViPhxSMor2pj4VGQdFMr",
+			net:  &chaincfg.MainNet,
+		},
+		"testnet": {
+			addr: "mtdruWYVEV1wz5yL7GvpBj4MgifCB7yhPd",
+			net:  &chaincfg.TestNet,
+		},
+		"regtest shares the testnet prefix": {
+			addr: "mtdruWYVEV1wz5yL7GvpBj4MgifCB7yhPd",
+			net:  &chaincfg.RegTest,
+		},
+		"regtest P2SH": {
+			addr: "2NFryYnmhXneo7LRajgLZnc38dYiDePvf3G",
+			net:  &chaincfg.RegTest,
+		},
+		"wrong network": {
+			addr:   "1E7ucTTWRTahCyViPhxSMor2pj4VGQdFMr",
+			net:    &chaincfg.RegTest,
+			expErr: bscript.ErrWrongNetwork,
+		},
+		"no network": {
+			addr:   "1E7ucTTWRTahCyViPhxSMor2pj4VGQdFMr",
+			expErr: bscript.ErrNoNetwork,
+		},
+		"bad checksum": {
+			addr:   "3QJmV3qfvL9SuYo34YihAf3sRCW3qSinyD",
+			net:    &chaincfg.MainNet,
+			expErr: bscript.ErrEncodingChecksumFailed,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			addr, err := bscript.NewAddressFromStringForNet(test.addr, test.net)
+			if test.expErr != nil {
+				assert.ErrorIs(t, err, test.expErr)
+				assert.Nil(t, addr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.net, addr.Net)
+			assert.Equal(t, test.addr, addr.String())
+		})
+	}
+}
+
 func TestNewAddressFromPublicKeyString(t *testing.T) {
 	t.Parallel()
 
 	t.Run("mainnet", func(t *testing.T) {
 		addr, err := bscript.NewAddressFromPublicKeyString(
 			"026cf33373a9f3f6c676b75b543180703df225f7f8edbffedc417718a8ad4e89ce",
-			true,
+			&chaincfg.MainNet,
 		)
 		assert.NoError(t, err)
 		assert.NotNil(t, addr)
@@ -72,7 +164,7 @@ func TestNewAddressFromPublicKeyString(t *testing.T) {
 	t.Run("testnet", func(t *testing.T) {
 		addr, err := bscript.NewAddressFromPublicKeyString(
 			"026cf33373a9f3f6c676b75b543180703df225f7f8edbffedc417718a8ad4e89ce",
-			false,
+			&chaincfg.TestNet,
 		)
 		assert.NoError(t, err)
 		assert.NotNil(t, addr)
@@ -80,6 +172,26 @@ func TestNewAddressFromPublicKeyString(t *testing.T) {
 		assert.Equal(t, testPublicKeyHash, addr.PublicKeyHash)
 		assert.Equal(t, "mfaWoDuTsFfiunLTqZx4fKpVsUctiDV9jk", addr.AddressString)
 	})
+
+	t.Run("regtest", func(t *testing.T) {
+		addr, err := bscript.NewAddressFromPublicKeyString(
+			"026cf33373a9f3f6c676b75b543180703df225f7f8edbffedc417718a8ad4e89ce",
+			&chaincfg.RegTest,
+		)
+		assert.NoError(t, err)
+		assert.NotNil(t, addr)
+
+		assert.Equal(t, "mfaWoDuTsFfiunLTqZx4fKpVsUctiDV9jk", addr.AddressString)
+	})
+
+	t.Run("no network", func(t *testing.T) {
+		addr, err := bscript.NewAddressFromPublicKeyString(
+			"026cf33373a9f3f6c676b75b543180703df225f7f8edbffedc417718a8ad4e89ce",
+			nil,
+		)
+		assert.ErrorIs(t, err, bscript.ErrNoNetwork)
+		assert.Nil(t, addr)
+	})
 }
 
 func TestNewAddressFromPublicKey(t *testing.T) {
@@ -94,7 +206,7 @@ func TestNewAddressFromPublicKey(t *testing.T) {
 	assert.NotNil(t, pubKey)
 
 	var addr *bscript.Address
-	addr, err = bscript.NewAddressFromPublicKey(pubKey, true)
+	addr, err = bscript.NewAddressFromPublicKey(pubKey, &chaincfg.MainNet)
 	assert.NoError(t, err)
 	assert.NotNil(t, addr)
 
@@ -102,6 +214,80 @@ func TestNewAddressFromPublicKey(t *testing.T) {
 	assert.Equal(t, "114ZWApV4EEU8frr7zygqQcB1V2BodGZuS", addr.AddressString)
 }
 
+func TestNewAddressFromScriptHash(t *testing.T) {
+	t.Parallel()
+
+	hash, err := hex.DecodeString(testScriptHash)
+	assert.NoError(t, err)
+
+	addr, err := bscript.NewAddressFromScriptHash(hash, &chaincfg.MainNet)
+	assert.NoError(t, err)
+	assert.Equal(t, testP2SHAddress, addr.AddressString)
+	assert.Equal(t, bscript.AddressTypeP2SH, addr.Type)
+
+	_, err = bscript.NewAddressFromScriptHash(hash[1:], &chaincfg.MainNet)
+	assert.ErrorIs(t, err, bscript.ErrInvalidAddressLength)
+
+	_, err = bscript.NewAddressFromScriptHash(hash, nil)
+	assert.ErrorIs(t, err, bscript.ErrNoNetwork)
+}
+
+func TestNewAddressFromRedeemScript(t *testing.T) {
+	t.Parallel()
+
+	redeemScript, err := bscript.NewFromASM("OP_2 OP_ADD OP_5 OP_EQUAL")
+	assert.NoError(t, err)
+
+	addr, err := bscript.NewAddressFromRedeemScript(redeemScript, &chaincfg.TestNet)
+	assert.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(crypto.Hash160(*redeemScript)), addr.ScriptHash)
+	assert.Equal(t, byte('2'), addr.AddressString[0])
+}
+
+func TestAddress_LockingScript(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		address string
+		expASM  string
+	}{
+		"P2PKH": {
+			address: "114ZWApV4EEU8frr7zygqQcB1V2BodGZuS",
+			expASM:  "OP_DUP OP_HASH160 " + testPublicKeyHash + " OP_EQUALVERIFY OP_CHECKSIG",
+		},
+		"P2SH": {
+			address: testP2SHAddress,
+			expASM:  "OP_HASH160 " + testScriptHash + " OP_EQUAL",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			addr, err := bscript.NewAddressFromString(test.address)
+			assert.NoError(t, err)
+
+			s, err := addr.LockingScript()
+			assert.NoError(t, err)
+			asm, err := s.ToASM()
+			assert.NoError(t, err)
+			assert.Equal(t, test.expASM, asm)
+
+			// and back again
+			addr2, err := bscript.NewAddressFromLockingScript(s, &chaincfg.MainNet)
+			assert.NoError(t, err)
+			assert.Equal(t, addr, addr2)
+		})
+	}
+
+	t.Run("unsupported script", func(t *testing.T) {
+		s, err := bscript.NewFromASM("OP_FALSE OP_RETURN 0102")
+		assert.NoError(t, err)
+
+		_, err = bscript.NewAddressFromLockingScript(s, &chaincfg.MainNet)
+		assert.ErrorIs(t, err, bscript.ErrUnsupportedAddress)
+	})
+}
+
 func TestBase58EncodeMissingChecksum(t *testing.T) {
 	t.Parallel()
 
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/mvc-labs/mvc-lib-go/keys/chaincfg"
 	"github.com/mvc-labs/mvc-lib-go/keys/crypto"
 )
 
@@ -48,8 +49,8 @@ func (a *a25) set58(s []byte) error {
 	return nil
 }
 
-// ValidateAddress checks if an address string is a valid BitCoin address (ex. P2PKH, BIP276).
-// Checks both mainnet and testnet.
+// ValidateAddress checks if an address string is a valid BitCoin address (ex. P2PKH, P2SH, BIP276).
+// Addresses of any network registered with chaincfg are accepted.
 func ValidateAddress(address string) (bool, error) {
 	if strings.HasPrefix(address, "bitcoin-script:") {
 		if _, err := DecodeBIP276(address); err != nil {
@@ -66,7 +67,7 @@ func validA58(a58 []byte) (bool, error) {
 	if err := a.set58(a58); err != nil {
 		return false, err
 	}
-	if a[0] != 0 && a[0] != 0x6f {
+	if !chaincfg.IsPubKeyHashAddrID(a[0]) && !chaincfg.IsScriptHashAddrID(a[0]) {
 		return false, ErrEncodingInvalidVersion
 	}
 
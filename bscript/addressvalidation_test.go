@@ -22,6 +22,18 @@ func TestValidateAddress(t *testing.T) {
 		assert.Equal(t, true, ok)
 	})
 
+	t.Run("mainnet P2SH", func(t *testing.T) {
+		ok, err := bscript.ValidateAddress("3QJmV3qfvL9SuYo34YihAf3sRCW3qSinyC")
+		assert.NoError(t, err)
+		assert.Equal(t, true, ok)
+	})
+
+	t.Run("testnet P2SH", func(t *testing.T) {
+		ok, err := bscript.ValidateAddress("2NFryYnmhXneo7LRajgLZnc38dYiDePvf3G")
+		assert.NoError(t, err)
+		assert.Equal(t, true, ok)
+	})
+
 	t.Run("BIP276", func(t *testing.T) {
 		ok, err := bscript.ValidateAddress("bitcoin-script:0101522102e5b3f2970648b5592b7303367ab7d7d49e6e27dd80c7b5da18a22dac67a51a322103da6bf6a0c1a06ae7c4091542e0eaa29f2678e7957b78ba09cbe5a36241a4ad0452aeb245ccc7")
 		assert.NoError(t, err)
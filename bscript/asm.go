@@ -0,0 +1,293 @@
+package bscript
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/mvc-labs/mvc-lib-go/sighash"
+)
+
+// ASMMode selects how Script.FormatASM renders a script.
+type ASMMode uint8
+
+// Modes supported by Script.FormatASM.
+const (
+	// ASMModeNode renders the script as the node's decodescript does: OP_0, OP_1NEGATE
+	// and OP_1 to OP_16 as numbers, pushes of up to 4 bytes as decimal script numbers,
+	// longer pushes as hex, and "[error]" in place of a truncated push.
+	ASMModeNode ASMMode = iota
+
+	// ASMModeNodeSigHash renders as ASMModeNode, but also decodes the sighash type of
+	// pushes which are DER signatures, `<hex>[ALL|FORKID]`, as the node does when
+	// rendering the unlocking scripts of a tx. Data scripts are never decoded.
+	ASMModeNodeSigHash
+
+	// ASMModeRoundTrip renders the script so that ParseASM gives back exactly the same
+	// bytes: small ints as numbers, pushes as hex and opcodes by name, falling back to
+	// raw `0x` hex for anything that would otherwise be re-encoded differently, such
+	// as a non-minimal push or a truncated script.
+	ASMModeRoundTrip
+)
+
+// sigHashTypes are the sighash types which are rendered by name after a signature.
+var sigHashTypes = []sighash.Flag{
+	sighash.All, sighash.None, sighash.Single,
+	sighash.All | sighash.AnyOneCanPay, sighash.None | sighash.AnyOneCanPay, sighash.Single | sighash.AnyOneCanPay,
+	sighash.AllForkID, sighash.NoneForkID, sighash.SingleForkID,
+	sighash.AllForkID | sighash.AnyOneCanPay, sighash.NoneForkID | sighash.AnyOneCanPay,
+	sighash.SingleForkID | sighash.AnyOneCanPay,
+}
+
+// ParseASM creates a new script from ASM, being more lenient than NewFromASM and
+// accepting both the node's decodescript output and the short form used by its
+// script_tests.json. The tokens, separated by any whitespace, can be:
+//
+//	OP_DUP, DUP      an opcode, with or without the OP_ prefix
+//	-1, 0, 16, 1000  a decimal number of any size, pushed as a minimal script number
+//	'text'           a quoted string, which may contain spaces, pushed as is
+//	76a914           hex data, pushed with the smallest push opcode
+//	3044...[ALL]     a signature with its sighash type decoded, as from ASMModeNodeSigHash
+//	0x4c01ff         raw hex bytes, appended to the script as they are
+//
+// A # starts a comment which runs to the end of the line. Note that a token made up
+// only of decimal digits is always read as a number, so hex data such as "1234" must
+// be written as raw bytes, "0x021234".
+func ParseASM(str string) (*Script, error) {
+	tokens, err := splitASM(str)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Script{}
+	for _, tok := range tokens {
+		if err := s.appendASMToken(tok); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// FormatASM returns the script as ASM, rendered according to the mode.
+func (s *Script) FormatASM(mode ASMMode) string {
+	if s == nil || len(*s) == 0 {
+		return ""
+	}
+
+	unspendable := s.IsData()
+	tokens := make([]string, 0, len(*s))
+	for b := []byte(*s); len(b) > 0; {
+		op, data, rest, err := readOp(b)
+		if err != nil {
+			if mode == ASMModeRoundTrip {
+				tokens = append(tokens, "0x"+hex.EncodeToString(b))
+			} else {
+				tokens = append(tokens, "[error]")
+			}
+			break
+		}
+
+		var tok string
+		switch mode {
+		case ASMModeRoundTrip:
+			tok = roundTripASMToken(op, data, b[:len(b)-len(rest)])
+		default:
+			tok = nodeASMToken(op, data, mode == ASMModeNodeSigHash && !unspendable)
+		}
+		tokens = append(tokens, tok)
+		b = rest
+	}
+
+	return strings.Join(tokens, " ")
+}
+
+func nodeASMToken(op byte, data []byte, decodeSigHash bool) string {
+	switch {
+	case op == Op1NEGATE:
+		return "-1"
+	case op >= OpONE && op <= Op16:
+		return fmt.Sprintf("%d", smallInt(op))
+	case op > OpPUSHDATA4:
+		return opCodeValues[op]
+	case len(data) <= 4:
+		n, _ := parseScriptNum(op, data)
+		return fmt.Sprintf("%d", n)
+	}
+
+	if decodeSigHash {
+		if flag, ok := sigHashType(data); ok {
+			return hex.EncodeToString(data[:len(data)-1]) + "[" + flag.String() + "]"
+		}
+	}
+
+	return hex.EncodeToString(data)
+}
+
+func roundTripASMToken(op byte, data []byte, raw []byte) string {
+	var tok string
+	switch {
+	case op == OpZERO:
+		tok = "0"
+	case op == Op1NEGATE:
+		tok = "-1"
+	case op >= OpONE && op <= Op16:
+		tok = fmt.Sprintf("%d", smallInt(op))
+	case op > OpPUSHDATA4:
+		tok = opCodeValues[op]
+	default:
+		tok = hex.EncodeToString(data)
+	}
+
+	// Check the token reads back as the same bytes, which it will not for a
+	// non-minimal push, or hex data which looks like a number.
+	check := &Script{}
+	if tok == "" || check.appendASMToken(tok) != nil || string(*check) != string(raw) {
+		return "0x" + hex.EncodeToString(raw)
+	}
+
+	return tok
+}
+
+// sigHashType returns the sighash type of the push if it is a strictly DER encoded
+// signature followed by a defined sighash type.
+func sigHashType(sig []byte) (sighash.Flag, bool) {
+	if len(sig) == 0 || !isStrictDER(sig[:len(sig)-1]) {
+		return 0, false
+	}
+	flag := sighash.Flag(sig[len(sig)-1])
+	for _, f := range sigHashTypes {
+		if f == flag {
+			return flag, true
+		}
+	}
+
+	return 0, false
+}
+
+// isStrictDER returns true if the signature, without its sighash type, is encoded
+// as `0x30 <len> 0x02 <len R> <R> 0x02 <len S> <S>` with R and S minimally encoded
+// positive integers, as required by BIP66.
+func isStrictDER(sig []byte) bool {
+	if len(sig) < 8 || len(sig) > 72 || sig[0] != 0x30 || int(sig[1]) != len(sig)-2 {
+		return false
+	}
+
+	rLen := int(sig[3])
+	if 5+rLen >= len(sig) {
+		return false
+	}
+	sLen := int(sig[5+rLen])
+	if rLen+sLen+6 != len(sig) {
+		return false
+	}
+
+	for _, i := range []struct{ typ, l int }{{2, rLen}, {4 + rLen, sLen}} {
+		n := sig[i.typ+2 : i.typ+2+i.l]
+		if sig[i.typ] != 0x02 || i.l == 0 || n[0]&0x80 != 0 || (i.l > 1 && n[0] == 0x00 && n[1]&0x80 == 0) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// splitASM splits ASM into its tokens on whitespace, keeping quoted strings whole
+// and dropping comments.
+func splitASM(str string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(str); {
+		switch c := str[i]; {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			i++
+		case c == '#':
+			for i < len(str) && str[i] != '\n' {
+				i++
+			}
+		case c == '\'':
+			end := strings.IndexByte(str[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unterminated string %s", ErrInvalidASMToken, str[i:])
+			}
+			end += i + 2
+			tokens = append(tokens, str[i:end])
+			i = end
+		default:
+			end := strings.IndexAny(str[i:], " \t\r\n#")
+			if end < 0 {
+				end = len(str) - i
+			}
+			tokens = append(tokens, str[i:i+end])
+			i += end
+		}
+	}
+
+	return tokens, nil
+}
+
+func (s *Script) appendASMToken(tok string) error {
+	if isDecimal(tok) {
+		n, _ := new(big.Int).SetString(tok, 10)
+		return s.appendScriptBigNum(n)
+	}
+
+	if strings.HasPrefix(tok, "0x") || strings.HasPrefix(tok, "0X") {
+		b, err := hex.DecodeString(tok[2:])
+		if err != nil || len(b) == 0 {
+			return fmt.Errorf("%w: %q", ErrInvalidASMToken, tok)
+		}
+		*s = append(*s, b...)
+		return nil
+	}
+
+	if tok[0] == '\'' {
+		return s.AppendPushData([]byte(tok[1 : len(tok)-1]))
+	}
+
+	if op, ok := opCodeStrings[tok]; ok {
+		*s = append(*s, op)
+		return nil
+	}
+	if op, ok := opCodeStrings["OP_"+tok]; ok {
+		*s = append(*s, op)
+		return nil
+	}
+
+	var suffix []byte
+	if i := strings.IndexByte(tok, '['); i > 0 && tok[len(tok)-1] == ']' {
+		name := tok[i+1 : len(tok)-1]
+		for _, f := range sigHashTypes {
+			if f.String() == name {
+				suffix = []byte{byte(f)}
+			}
+		}
+		if suffix == nil {
+			return fmt.Errorf("%w: unknown sighash type in %q", ErrInvalidASMToken, tok)
+		}
+		tok = tok[:i]
+	}
+
+	b, err := hex.DecodeString(tok)
+	if err != nil {
+		return fmt.Errorf("%w: %q", ErrInvalidASMToken, tok)
+	}
+
+	return s.AppendPushData(append(b, suffix...))
+}
+
+func isDecimal(tok string) bool {
+	if tok[0] == '-' {
+		tok = tok[1:]
+	}
+	if tok == "" {
+		return false
+	}
+	for _, c := range tok {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,168 @@
+package bscript_test
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+	"github.com/mvc-labs/mvc-lib-go/keys/bec"
+	"github.com/mvc-labs/mvc-lib-go/keys/crypto"
+	"github.com/mvc-labs/mvc-lib-go/sighash"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseASM(t *testing.T) {
+	t.Parallel()
+
+	pkh := "e2a623699e81b291c0327f408fea765d534baa2a"
+
+	tests := map[string]struct {
+		asm    string
+		expHex string
+		expErr error
+	}{
+		"p2pkh": {
+			asm:    "OP_DUP OP_HASH160 " + pkh + " OP_EQUALVERIFY OP_CHECKSIG",
+			expHex: "76a914" + pkh + "88ac",
+		},
+		"opcodes without prefix and extra whitespace": {
+			asm:    "  DUP\tHASH160\n" + pkh + "   EQUALVERIFY CHECKSIG \n",
+			expHex: "76a914" + pkh + "88ac",
+		},
+		"small numbers": {
+			asm:    "0 -1 1 16 TRUE FALSE",
+			expHex: "004f51605100",
+		},
+		"script numbers": {
+			asm:    "17 -17 127 128 -128 32767 800000",
+			expHex: "0111019101" + "7f" + "028000" + "028080" + "02ff7f" + "0300350c",
+		},
+		"big number": {
+			asm:    "-18446744073709551616",
+			expHex: "09000000000000000081",
+		},
+		"quoted strings": {
+			asm:    "OP_FALSE OP_RETURN 'hello world' ''",
+			expHex: "006a0b68656c6c6f20776f726c6400",
+		},
+		"raw hex": {
+			asm:    "0x4c 0x01 0x07 ADD",
+			expHex: "4c010793",
+		},
+		"comments": {
+			asm:    "# a comment\nOP_1 OP_2 # another\nOP_ADD",
+			expHex: "515293",
+		},
+		"sighash decoded signature": {
+			asm:    "3006020101020101[ALL|FORKID]",
+			expHex: "09300602010102010141",
+		},
+		"unknown opcode": {
+			asm:    "OP_DUP OP_NOTANOP",
+			expErr: bscript.ErrInvalidASMToken,
+		},
+		"odd hex": {
+			asm:    "abc",
+			expErr: bscript.ErrInvalidASMToken,
+		},
+		"unterminated string": {
+			asm:    "'hello",
+			expErr: bscript.ErrInvalidASMToken,
+		},
+		"unknown sighash": {
+			asm:    "3006020101020101[EVERYTHING]",
+			expErr: bscript.ErrInvalidASMToken,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, err := bscript.ParseASM(test.asm)
+			if test.expErr != nil {
+				assert.ErrorIs(t, err, test.expErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expHex, s.String())
+		})
+	}
+}
+
+func TestScript_FormatASM(t *testing.T) {
+	t.Parallel()
+
+	pk, err := bec.NewPrivateKey(bec.S256())
+	assert.NoError(t, err)
+	sig, err := pk.Sign(crypto.Sha256d([]byte("message")))
+	assert.NoError(t, err)
+	sigHex := hex.EncodeToString(sig.Serialise())
+	sigPush := hex.EncodeToString([]byte{byte(len(sig.Serialise()) + 1)})
+	pubKeyHex := hex.EncodeToString(pk.PubKey().SerialiseCompressed())
+
+	tests := map[string]struct {
+		hex          string
+		expNode      string
+		expSigHash   string
+		expRoundTrip string
+	}{
+		"p2pkh": {
+			hex:          "76a914e2a623699e81b291c0327f408fea765d534baa2a88ac",
+			expNode:      "OP_DUP OP_HASH160 e2a623699e81b291c0327f408fea765d534baa2a OP_EQUALVERIFY OP_CHECKSIG",
+			expSigHash:   "OP_DUP OP_HASH160 e2a623699e81b291c0327f408fea765d534baa2a OP_EQUALVERIFY OP_CHECKSIG",
+			expRoundTrip: "OP_DUP OP_HASH160 e2a623699e81b291c0327f408fea765d534baa2a OP_EQUALVERIFY OP_CHECKSIG",
+		},
+		"small ints and numbers": {
+			hex:          "004f5160020001038000800181",
+			expNode:      "0 -1 1 16 256 -128 -1",
+			expSigHash:   "0 -1 1 16 256 -128 -1",
+			expRoundTrip: "0 -1 1 16 0x020001 0x03800080 0x0181",
+		},
+		"unlocking script": {
+			hex:          sigPush + sigHex + "41" + "21" + pubKeyHex,
+			expNode:      sigHex + "41 " + pubKeyHex,
+			expSigHash:   sigHex + "[ALL|FORKID] " + pubKeyHex,
+			expRoundTrip: sigHex + "41 " + pubKeyHex,
+		},
+		"data script is not sighash decoded": {
+			hex:          "006a" + sigPush + sigHex + "41",
+			expNode:      "0 OP_RETURN " + sigHex + "41",
+			expSigHash:   "0 OP_RETURN " + sigHex + "41",
+			expRoundTrip: "0 OP_RETURN " + sigHex + "41",
+		},
+		"non-minimal push": {
+			hex:          "4c0401020304",
+			expNode:      "67305985",
+			expSigHash:   "67305985",
+			expRoundTrip: "0x4c0401020304",
+		},
+		"truncated": {
+			hex:          "76a914e2a6",
+			expNode:      "OP_DUP OP_HASH160 [error]",
+			expSigHash:   "OP_DUP OP_HASH160 [error]",
+			expRoundTrip: "OP_DUP OP_HASH160 0x14e2a6",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, err := bscript.NewFromHexString(test.hex)
+			assert.NoError(t, err)
+
+			assert.Equal(t, test.expNode, s.FormatASM(bscript.ASMModeNode))
+			assert.Equal(t, test.expSigHash, s.FormatASM(bscript.ASMModeNodeSigHash))
+			assert.Equal(t, test.expRoundTrip, s.FormatASM(bscript.ASMModeRoundTrip))
+
+			rt, err := bscript.ParseASM(test.expRoundTrip)
+			assert.NoError(t, err)
+			assert.Equal(t, test.hex, rt.String())
+		})
+	}
+
+	t.Run("sighash decoded round trip", func(t *testing.T) {
+		s, err := bscript.ParseASM(sigHex + "[" + sighash.AllForkID.String() + "] " + pubKeyHex)
+		assert.NoError(t, err)
+		assert.Equal(t, sigPush+sigHex+"41"+"21"+pubKeyHex, s.String())
+		assert.True(t, strings.HasSuffix(s.FormatASM(bscript.ASMModeNodeSigHash), "[ALL|FORKID] "+pubKeyHex))
+	})
+}
@@ -13,13 +13,15 @@ var (
 var (
 	ErrInvalidAddressLength = errors.New("invalid address length")
 	ErrUnsupportedAddress   = errors.New("address not supported")
+	ErrNoNetwork            = errors.New("no network")
+	ErrWrongNetwork         = errors.New("address is not for network")
 )
 
 // Sentinel errors raised through encoding.
 var (
 	ErrEncodingBadChar         = errors.New("bad char")
 	ErrEncodingTooLong         = errors.New("too long")
-	ErrEncodingInvalidVersion  = errors.New("not version 0 of 6f")
+	ErrEncodingInvalidVersion  = errors.New("not a registered address version")
 	ErrEncodingInvalidChecksum = errors.New("invalid checksum")
 	ErrEncodingChecksumFailed  = errors.New("checksum failed")
 	ErrTextNoBIP76             = errors.New("text did not match the bip276 format")
@@ -31,5 +33,24 @@ var (
 	ErrInvalidOpCode     = errors.New("invalid opcode data")
 	ErrEmptyScript       = errors.New("script is empty")
 	ErrNotP2PKH          = errors.New("not a P2PKH")
+	ErrNotP2SH           = errors.New("not a P2SH")
 	ErrInvalidOpcodeType = errors.New("use AppendPushData for push data funcs")
+	ErrInvalidASMToken   = errors.New("invalid ASM token")
+)
+
+// Sentinel errors raised by script templates.
+var (
+	ErrInvalidMultiSig = errors.New("invalid multisig")
+	ErrInvalidRPuzzle  = errors.New("invalid R-puzzle")
+	ErrNotP2PK         = errors.New("not a P2PK")
+	ErrNotMultiSig     = errors.New("not a multisig")
+	ErrNotCLTVP2PKH    = errors.New("not a CLTV P2PKH")
+	ErrNotCSVP2PKH     = errors.New("not a CSV P2PKH")
+	ErrNotRPuzzle      = errors.New("not an R-puzzle")
+)
+
+// Sentinel errors raised by script patterns.
+var (
+	ErrInvalidPattern      = errors.New("invalid script pattern")
+	ErrDuplicateScriptType = errors.New("script type already registered")
 )
@@ -2,16 +2,6 @@ package interpreter
 
 import "math"
 
-type config interface {
-	AfterGenesis() bool
-	MaxOps() int
-	MaxStackSize() int
-	MaxScriptSize() int
-	MaxScriptElementSize() int
-	MaxScriptNumberLength() int
-	MaxPubKeysPerMultiSig() int
-}
-
 // Limits applied to transactions before genesis
 const (
 	MaxOpsBeforeGenesis                = 500
@@ -22,61 +12,92 @@ const (
 	MaxPubKeysPerMultiSigBeforeGenesis = 20
 )
 
-type beforeGenesisConfig struct{}
-type afterGenesisConfig struct{}
-
-func (a *afterGenesisConfig) AfterGenesis() bool {
-	return true
-}
-
-func (b *beforeGenesisConfig) AfterGenesis() bool {
-	return false
-}
-
-func (a *afterGenesisConfig) MaxStackSize() int {
-	return math.MaxInt32
-}
-
-func (b *beforeGenesisConfig) MaxStackSize() int {
-	return MaxStackSizeBeforeGenesis
-}
-
-func (a *afterGenesisConfig) MaxScriptSize() int {
-	return math.MaxInt32
-}
-
-func (b *beforeGenesisConfig) MaxScriptSize() int {
-	return MaxScriptSizeBeforeGenesis
-}
-
-func (a *afterGenesisConfig) MaxScriptElementSize() int {
-	return math.MaxInt32
-}
-
-func (b *beforeGenesisConfig) MaxScriptElementSize() int {
-	return MaxScriptElementSizeBeforeGenesis
-}
+// Limits applied to transactions after genesis, by consensus and by the default
+// standard policy of nodes. Any limit not listed is unbounded.
+const (
+	MaxScriptNumberLengthAfterGenesis       = 750 * 1000 // 750 * 1Kb
+	MaxScriptNumberLengthPolicyAfterGenesis = 250 * 1000 // 250 * 1Kb
+	MaxScriptSizePolicyAfterGenesis         = 500 * 1000 // 500 * 1Kb
+)
 
-func (a *afterGenesisConfig) MaxScriptNumberLength() int {
-	return 750 * 1000 // 750 * 1Kb
+// Limits are the limits applied to the execution of scripts, failing those
+// which exceed them.
+//
+// Before genesis the limits are fixed by consensus. After genesis most are
+// unbounded by consensus, but nodes apply tighter limits as policy when
+// accepting txs to their mempool, so txs breaching them will not be relayed.
+//
+// A limit left as zero takes its value from NewConsensusLimits(), so only the
+// limits to be tightened need to be set.
+type Limits struct {
+	// MaxOps is the max number of non-push opcodes executed per script.
+	MaxOps int
+	// MaxStackSize is the max number of items on the main and alt stacks combined.
+	MaxStackSize int
+	// MaxScriptSize is the max size, in bytes, of the locking and unlocking scripts.
+	MaxScriptSize int
+	// MaxScriptElementSize is the max size, in bytes, of any item pushed to the stack.
+	MaxScriptElementSize int
+	// MaxScriptNumberLength is the max size, in bytes, of numeric operands.
+	MaxScriptNumberLength int
+	// MaxPubKeysPerMultiSig is the max number of public keys in an OP_CHECKMULTISIG.
+	MaxPubKeysPerMultiSig int
 }
 
-func (b *beforeGenesisConfig) MaxScriptNumberLength() int {
-	return MaxScriptNumberLengthBeforeGenesis
+// NewBeforeGenesisLimits returns the limits applied to outputs mined before
+// genesis.
+func NewBeforeGenesisLimits() *Limits {
+	return &Limits{
+		MaxOps:                MaxOpsBeforeGenesis,
+		MaxStackSize:          MaxStackSizeBeforeGenesis,
+		MaxScriptSize:         MaxScriptSizeBeforeGenesis,
+		MaxScriptElementSize:  MaxScriptElementSizeBeforeGenesis,
+		MaxScriptNumberLength: MaxScriptNumberLengthBeforeGenesis,
+		MaxPubKeysPerMultiSig: MaxPubKeysPerMultiSigBeforeGenesis,
+	}
 }
 
-func (a *afterGenesisConfig) MaxOps() int {
-	return math.MaxInt32
+// NewConsensusLimits returns the limits applied by consensus to outputs mined
+// after genesis on mainnet. These are used by default.
+func NewConsensusLimits() *Limits {
+	return &Limits{
+		MaxOps:                math.MaxInt32,
+		MaxStackSize:          math.MaxInt32,
+		MaxScriptSize:         math.MaxInt32,
+		MaxScriptElementSize:  math.MaxInt32,
+		MaxScriptNumberLength: MaxScriptNumberLengthAfterGenesis,
+		MaxPubKeysPerMultiSig: math.MaxInt32,
+	}
 }
 
-func (b *beforeGenesisConfig) MaxOps() int {
-	return MaxOpsBeforeGenesis
-}
+// NewPolicyLimits returns the limits applied by the default standard policy of
+// mainnet nodes to outputs mined after genesis, to check that a tx will be
+// accepted and relayed.
+func NewPolicyLimits() *Limits {
+	l := NewConsensusLimits()
+	l.MaxScriptSize = MaxScriptSizePolicyAfterGenesis
+	l.MaxScriptNumberLength = MaxScriptNumberLengthPolicyAfterGenesis
 
-func (a *afterGenesisConfig) MaxPubKeysPerMultiSig() int {
-	return math.MaxInt32
+	return l
 }
 
-func (b *beforeGenesisConfig) MaxPubKeysPerMultiSig() int {
-	return MaxPubKeysPerMultiSigBeforeGenesis
+// withDefaults returns a copy of the limits, with any left as zero taken from d.
+func (l Limits) withDefaults(d *Limits) *Limits {
+	for _, f := range []struct {
+		v *int
+		d int
+	}{
+		{&l.MaxOps, d.MaxOps},
+		{&l.MaxStackSize, d.MaxStackSize},
+		{&l.MaxScriptSize, d.MaxScriptSize},
+		{&l.MaxScriptElementSize, d.MaxScriptElementSize},
+		{&l.MaxScriptNumberLength, d.MaxScriptNumberLength},
+		{&l.MaxPubKeysPerMultiSig, d.MaxPubKeysPerMultiSig},
+	} {
+		if *f.v == 0 {
+			*f.v = f.d
+		}
+	}
+
+	return &l
 }
@@ -0,0 +1,9 @@
+package debug
+
+import "github.com/pkg/errors"
+
+// Sentinel errors reported by the debug package.
+var (
+	ErrReplayMismatch = errors.New("replay does not match trace")
+	ErrInvalidTrace   = errors.New("trace state does not point into its scripts")
+)
@@ -0,0 +1,464 @@
+package debug
+
+import (
+	"bufio"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+	"github.com/mvc-labs/mvc-lib-go/bscript/interpreter"
+)
+
+// Session is an interactive debugging session over the execution of a script.
+//
+// The execution is recorded in full, as a Trace, when the session is created, so
+// the session can then be moved forwards and backwards through it freely,
+// stopping at breakpoints, without re-executing anything.
+type Session struct {
+	trace *Trace
+	err   error
+
+	pos         int
+	breakpoints []breakpoint
+}
+
+// breakpoint stops a session at an opcode, either by its position or, if it has
+// a name, wherever the opcode is.
+type breakpoint struct {
+	scriptIdx int
+	opcodeIdx int
+	name      string
+	op        byte
+}
+
+func (b breakpoint) String() string {
+	if b.name != "" {
+		return b.name
+	}
+	return fmt.Sprintf("%d:%d", b.scriptIdx, b.opcodeIdx)
+}
+
+func (b breakpoint) matches(s *interpreter.State) bool {
+	if b.name != "" {
+		return s.Opcode().Value() == b.op
+	}
+	return s.ScriptIdx == b.scriptIdx && s.OpcodeIdx == b.opcodeIdx
+}
+
+// NewSession executes the scripts configured by the provided options, such as
+// interpreter.WithTx(...) or interpreter.WithScripts(...), recording each step
+// for debugging. An error is returned only if nothing could be executed; the
+// outcome of the execution itself is reported by Session.Err().
+//
+// Example usage:
+//
+//	s, err := debug.NewSession(
+//	    interpreter.WithTx(tx, 0, prevOutput),
+//	    interpreter.WithForkID(),
+//	    interpreter.WithAfterGenesis(),
+//	)
+//	if err != nil {
+//	    return err
+//	}
+//	return s.Run(os.Stdin, os.Stdout)
+func NewSession(oo ...interpreter.ExecutionOptionFunc) (*Session, error) {
+	trace, err := Record(oo...)
+	if len(trace.Steps) == 0 {
+		if err == nil {
+			err = fmt.Errorf("no opcodes to execute")
+		}
+		return nil, err
+	}
+
+	return &Session{trace: trace, err: err}, nil
+}
+
+// NewSessionFromTrace returns a session over an execution recorded elsewhere,
+// such as a Trace decoded from JSON. An error wrapping ErrInvalidTrace is returned
+// if a step does not point at an opcode of its scripts, or the final state does
+// not point at one of its scripts.
+func NewSessionFromTrace(t *Trace) (*Session, error) {
+	if len(t.Steps) == 0 || t.Final == nil {
+		return nil, fmt.Errorf("trace has no steps")
+	}
+	if err := t.validate(); err != nil {
+		return nil, err
+	}
+
+	s := &Session{trace: t}
+	if t.Err != "" {
+		s.err = errors.New(t.Err)
+	}
+
+	return s, nil
+}
+
+// Err returns the outcome of the execution, nil if it succeeded.
+func (s *Session) Err() error {
+	return s.err
+}
+
+// Trace returns the trace of the execution.
+func (s *Session) Trace() *Trace {
+	return s.trace
+}
+
+// Steps returns the state before each step of the execution.
+func (s *Session) Steps() []*interpreter.State {
+	return s.trace.Steps
+}
+
+// Position returns the index of the next step to be executed, or len(Steps())
+// once the execution has finished.
+func (s *Session) Position() int {
+	return s.pos
+}
+
+// State returns the state of the execution at the current position.
+func (s *Session) State() *interpreter.State {
+	if s.finished() {
+		return s.trace.Final
+	}
+	return s.trace.Steps[s.pos]
+}
+
+// Step moves the session forward n steps, stopping at the end of the execution.
+func (s *Session) Step(n int) {
+	s.Seek(s.pos + n)
+}
+
+// Back moves the session back n steps, stopping at the start of the execution.
+func (s *Session) Back(n int) {
+	s.Seek(s.pos - n)
+}
+
+// Seek moves the session to the step at the index.
+func (s *Session) Seek(idx int) {
+	switch {
+	case idx < 0:
+		s.pos = 0
+	case idx > len(s.trace.Steps):
+		s.pos = len(s.trace.Steps)
+	default:
+		s.pos = idx
+	}
+}
+
+// Next moves the session forward a step or, if the next opcode is an OP_IF or
+// OP_NOTIF, past the whole conditional block to just after its OP_ENDIF.
+func (s *Session) Next() {
+	if s.finished() {
+		return
+	}
+
+	state := s.trace.Steps[s.pos]
+	if op := state.Opcode().Value(); op != bscript.OpIF && op != bscript.OpNOTIF {
+		s.Step(1)
+		return
+	}
+
+	depth := len(state.CondStack)
+	for i := s.pos + 1; i < len(s.trace.Steps); i++ {
+		next := s.trace.Steps[i]
+		if next.ScriptIdx != state.ScriptIdx || len(next.CondStack) <= depth {
+			s.pos = i
+			return
+		}
+	}
+	s.pos = len(s.trace.Steps)
+}
+
+// Continue moves the session forward until it reaches a breakpoint, or the end
+// of the execution.
+func (s *Session) Continue() {
+	for s.Step(1); !s.finished(); s.Step(1) {
+		for _, b := range s.breakpoints {
+			if b.matches(s.trace.Steps[s.pos]) {
+				return
+			}
+		}
+	}
+}
+
+// Break adds a breakpoint, given as either the name of an opcode, such as
+// OP_CHECKSIG, or its position, as "scriptIdx:opcodeIdx" or just "opcodeIdx" in
+// the current script.
+func (s *Session) Break(at string) error {
+	b := breakpoint{scriptIdx: s.State().ScriptIdx}
+	if scriptIdx, opcodeIdx, ok := cut(at, ":"); ok {
+		var err error
+		if b.scriptIdx, err = strconv.Atoi(scriptIdx); err != nil {
+			return fmt.Errorf("invalid breakpoint %q", at)
+		}
+		at = opcodeIdx
+	}
+
+	var err error
+	if b.opcodeIdx, err = strconv.Atoi(at); err != nil {
+		name := strings.ToUpper(at)
+		if !strings.HasPrefix(name, "OP_") {
+			name = "OP_" + name
+		}
+		op, err := bscript.ParseASM(name)
+		if err != nil || len(*op) != 1 {
+			return fmt.Errorf("invalid breakpoint %q", at)
+		}
+		b = breakpoint{name: name, op: (*op)[0]}
+	}
+
+	s.breakpoints = append(s.breakpoints, b)
+	return nil
+}
+
+// finished returns true if every step has been executed.
+func (s *Session) finished() bool {
+	return s.pos >= len(s.trace.Steps)
+}
+
+// Run runs the session as a REPL, reading commands from r and writing their
+// output to w until the input ends or "quit" is read. Run "help" for the
+// commands available.
+func (s *Session) Run(r io.Reader, w io.Writer) error {
+	s.printPosition(w)
+
+	scanner := bufio.NewScanner(r)
+	for {
+		if _, err := fmt.Fprint(w, "(debug) "); err != nil {
+			return err
+		}
+		if !scanner.Scan() {
+			fmt.Fprintln(w)
+			return scanner.Err()
+		}
+
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "quit" || fields[0] == "q" {
+			return nil
+		}
+		if err := s.exec(w, fields[0], fields[1:]); err != nil {
+			fmt.Fprintf(w, "error: %s\n", err)
+		}
+	}
+}
+
+const sessionHelp = `Commands:
+  step, s [n]          execute the next n steps, default 1
+  next, n              execute the next step, stepping over a whole OP_IF block
+  continue, c          execute until a breakpoint or the end
+  back, b [n]          rewind n steps, default 1
+  rewind, r [step]     rewind to a step, default the start
+  break, bp <at>       break at an opcode name or [script:]opcode index
+  breakpoints, bps     list breakpoints
+  delete, d [n]        delete breakpoint n, or all breakpoints
+  stack [view]         show the main stack, as hex, num or str
+  alt [view]           show the alt stack, as hex, num or str
+  cond                 show the condition stack
+  script               show the current script
+  trace                dump each step of the execution
+  quit, q              exit
+`
+
+func (s *Session) exec(w io.Writer, cmd string, args []string) error {
+	n := 1
+	if len(args) > 0 && cmd != "break" && cmd != "bp" && cmd != "stack" && cmd != "alt" {
+		var err error
+		if n, err = strconv.Atoi(args[0]); err != nil {
+			return fmt.Errorf("invalid number %q", args[0])
+		}
+	}
+
+	switch cmd {
+	case "help", "h":
+		fmt.Fprint(w, sessionHelp)
+		return nil
+	case "step", "s":
+		s.Step(n)
+	case "next", "n":
+		s.Next()
+	case "continue", "c":
+		s.Continue()
+	case "back", "b":
+		s.Back(n)
+	case "rewind", "r":
+		if len(args) == 0 {
+			n = 0
+		}
+		s.Seek(n)
+	case "break", "bp":
+		if len(args) != 1 {
+			return fmt.Errorf("break requires an opcode name or index")
+		}
+		if err := s.Break(args[0]); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "breakpoint %d at %s\n", len(s.breakpoints)-1, s.breakpoints[len(s.breakpoints)-1])
+		return nil
+	case "breakpoints", "bps":
+		for i, b := range s.breakpoints {
+			fmt.Fprintf(w, "%d: %s\n", i, b)
+		}
+		return nil
+	case "delete", "d":
+		if len(args) == 0 {
+			s.breakpoints = nil
+			return nil
+		}
+		if n < 0 || n >= len(s.breakpoints) {
+			return fmt.Errorf("no breakpoint %d", n)
+		}
+		s.breakpoints = append(s.breakpoints[:n], s.breakpoints[n+1:]...)
+		return nil
+	case "stack", "alt":
+		view := "hex"
+		if len(args) > 0 {
+			view = args[0]
+		}
+		stack := s.State().DataStack
+		if cmd == "alt" {
+			stack = s.State().AltStack
+		}
+		return printStack(w, stack, view)
+	case "cond":
+		fmt.Fprintln(w, s.State().CondStack)
+		return nil
+	case "script":
+		s.printScript(w)
+		return nil
+	case "trace":
+		s.printTrace(w)
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q, run help for the commands", cmd)
+	}
+
+	s.printPosition(w)
+	return nil
+}
+
+// printPosition prints the step and opcode the session is at, or the outcome of
+// the execution if it has finished.
+func (s *Session) printPosition(w io.Writer) {
+	if s.finished() {
+		if s.err != nil {
+			fmt.Fprintf(w, "finished after %d steps: %s\n", len(s.trace.Steps), s.err)
+		} else {
+			fmt.Fprintf(w, "finished after %d steps: success\n", len(s.trace.Steps))
+		}
+		return
+	}
+
+	state := s.trace.Steps[s.pos]
+	fmt.Fprintf(w, "step %d, script %d op %d: %s\n", s.pos, state.ScriptIdx, state.OpcodeIdx, formatOpcode(state.Opcode()))
+}
+
+func (s *Session) printScript(w io.Writer) {
+	state := s.State()
+	for i, op := range state.Scripts[state.ScriptIdx] {
+		marker := "  "
+		if i == state.OpcodeIdx && !s.finished() {
+			marker = "=>"
+		}
+		fmt.Fprintf(w, "%s %3d %s\n", marker, i, formatOpcode(op))
+	}
+}
+
+func (s *Session) printTrace(w io.Writer) {
+	for i, state := range s.trace.Steps {
+		fmt.Fprintf(w, "%4d %d:%-4d %-32s %s\n",
+			i, state.ScriptIdx, state.OpcodeIdx, formatOpcode(state.Opcode()), formatStack(state.DataStack))
+	}
+	fmt.Fprintf(w, "%4s %-39s %s\n", "end", "", formatStack(s.trace.Final.DataStack))
+}
+
+func printStack(w io.Writer, stack [][]byte, view string) error {
+	format, ok := stackViews[view]
+	if !ok {
+		return fmt.Errorf("unknown view %q, expected hex, num or str", view)
+	}
+	if len(stack) == 0 {
+		fmt.Fprintln(w, "<empty>")
+	}
+	// The top of the stack is printed first.
+	for i := len(stack) - 1; i >= 0; i-- {
+		fmt.Fprintf(w, "%3d: %s\n", len(stack)-1-i, format(stack[i]))
+	}
+	return nil
+}
+
+// stackViews format stack items as hex, script numbers or strings.
+var stackViews = map[string]func([]byte) string{
+	"hex": func(b []byte) string {
+		if len(b) == 0 {
+			return `""`
+		}
+		return hex.EncodeToString(b)
+	},
+	"num": func(b []byte) string {
+		return scriptNumber(b).String()
+	},
+	"str": func(b []byte) string {
+		for _, r := range string(b) {
+			if !unicode.IsPrint(r) {
+				return strconv.Quote(string(b))
+			}
+		}
+		return string(b)
+	},
+}
+
+// scriptNumber decodes the little endian, sign and magnitude number of any size.
+func scriptNumber(b []byte) *big.Int {
+	if len(b) == 0 {
+		return new(big.Int)
+	}
+
+	be := make([]byte, len(b))
+	for i := range b {
+		be[len(b)-1-i] = b[i]
+	}
+	neg := be[0]&0x80 != 0
+	be[0] &= 0x7f
+
+	n := new(big.Int).SetBytes(be)
+	if neg {
+		n.Neg(n)
+	}
+	return n
+}
+
+// formatOpcode returns the name of the opcode, followed by its data, if any.
+func formatOpcode(op interpreter.ParsedOpcode) string {
+	if len(op.Data) == 0 {
+		return op.Name()
+	}
+	return op.Name() + " " + hex.EncodeToString(op.Data)
+}
+
+// formatStack formats the stack, bottom first, as hex, showing empty items as "".
+func formatStack(stack [][]byte) string {
+	ss := make([]string, len(stack))
+	for i, item := range stack {
+		ss[i] = hex.EncodeToString(item)
+		if len(item) == 0 {
+			ss[i] = `""`
+		}
+	}
+	return "[" + strings.Join(ss, " ") + "]"
+}
+
+// cut slices s around the first instance of sep.
+func cut(s, sep string) (before, after string, found bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
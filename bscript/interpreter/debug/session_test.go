@@ -0,0 +1,169 @@
+package debug_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+	"github.com/mvc-labs/mvc-lib-go/bscript/interpreter"
+	"github.com/mvc-labs/mvc-lib-go/bscript/interpreter/debug"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSession(t *testing.T, lockingASM, unlockingASM string) *debug.Session {
+	lockingScript, err := bscript.ParseASM(lockingASM)
+	assert.NoError(t, err)
+	unlockingScript, err := bscript.ParseASM(unlockingASM)
+	assert.NoError(t, err)
+
+	s, err := debug.NewSession(
+		interpreter.WithScripts(lockingScript, unlockingScript),
+		interpreter.WithAfterGenesis(),
+	)
+	assert.NoError(t, err)
+	return s
+}
+
+func TestSession(t *testing.T) {
+	t.Parallel()
+
+	s := newTestSession(t, "OP_IF OP_2 OP_3 OP_ADD OP_ELSE OP_4 OP_ENDIF OP_5 OP_EQUAL", "OP_1")
+	assert.NoError(t, s.Err())
+	assert.Len(t, s.Steps(), 10)
+	assert.Equal(t, "OP_1", s.State().Opcode().Name())
+
+	s.Step(1)
+	assert.Equal(t, "OP_IF", s.State().Opcode().Name())
+	assert.Equal(t, [][]byte{{0x01}}, s.State().DataStack)
+
+	s.Next()
+	assert.Equal(t, "OP_5", s.State().Opcode().Name())
+	assert.Equal(t, [][]byte{{0x05}}, s.State().DataStack)
+
+	s.Back(4)
+	assert.Equal(t, "OP_ADD", s.State().Opcode().Name())
+	assert.Equal(t, [][]byte{{0x02}, {0x03}}, s.State().DataStack)
+	assert.Equal(t, []int{1}, s.State().CondStack)
+
+	s.Seek(0)
+	assert.NoError(t, s.Break("OP_ELSE"))
+	s.Continue()
+	assert.Equal(t, 5, s.Position())
+
+	assert.NoError(t, s.Break("1:8"))
+	s.Continue()
+	assert.Equal(t, "OP_EQUAL", s.State().Opcode().Name())
+
+	s.Continue()
+	assert.Equal(t, len(s.Steps()), s.Position())
+	assert.True(t, s.State().IsFinished)
+	s.Step(1)
+	assert.Equal(t, len(s.Steps()), s.Position())
+
+	assert.Error(t, s.Break("OP_NOPE"))
+	assert.Error(t, s.Break("x:1"))
+}
+
+func TestSession_Run(t *testing.T) {
+	t.Parallel()
+
+	s := newTestSession(t, "OP_DUP 'hi' OP_CAT OP_2 OP_EQUAL", "OP_1")
+
+	var out bytes.Buffer
+	assert.NoError(t, s.Run(strings.NewReader(strings.Join([]string{
+		"bp cat",
+		"c",
+		"stack",
+		"stack str",
+		"s 2",
+		"stack num",
+		"b",
+		"script",
+		"nope",
+		"c",
+		"q",
+		"step",
+	}, "\n")), &out))
+
+	exp := strings.Join([]string{
+		"step 0, script 0 op 0: OP_1",
+		"(debug) breakpoint 0 at OP_CAT",
+		"(debug) step 3, script 1 op 2: OP_CAT",
+		"(debug)   0: 6869",
+		"  1: 01",
+		"  2: 01",
+		"(debug)   0: hi",
+		`  1: "\x01"`,
+		`  2: "\x01"`,
+		"(debug) step 5, script 1 op 4: OP_EQUAL",
+		"(debug)   0: 2",
+		"  1: 6907905",
+		"  2: 1",
+		"(debug) step 4, script 1 op 3: OP_2",
+		"(debug)      0 OP_DUP",
+		"     1 OP_DATA_2 6869",
+		"     2 OP_CAT",
+		"=>   3 OP_2",
+		"     4 OP_EQUAL",
+		`(debug) error: unknown command "nope", run help for the commands`,
+		"(debug) finished after 6 steps: false stack entry at end of script execution",
+		"(debug) ",
+	}, "\n")
+	assert.Equal(t, exp, out.String())
+}
+
+func TestNewSession_Error(t *testing.T) {
+	t.Parallel()
+
+	_, err := debug.NewSession(interpreter.WithScripts(&bscript.Script{}, &bscript.Script{}))
+	assert.Error(t, err)
+}
+
+func TestNewSessionFromTrace_Malformed(t *testing.T) {
+	t.Parallel()
+
+	s := newTestSession(t, "OP_2 OP_EQUAL", "OP_2")
+	b, err := json.Marshal(s.Trace())
+	assert.NoError(t, err)
+
+	tests := map[string]func(tr *debug.Trace){
+		"no scripts": func(tr *debug.Trace) {
+			tr.Steps[0].Scripts = nil
+		},
+		"script out of range": func(tr *debug.Trace) {
+			tr.Steps[1].ScriptIdx = 5
+		},
+		"negative opcode": func(tr *debug.Trace) {
+			tr.Steps[0].OpcodeIdx = -1
+		},
+		"opcode out of range": func(tr *debug.Trace) {
+			tr.Steps[0].OpcodeIdx = 10
+		},
+		"final script out of range": func(tr *debug.Trace) {
+			tr.Final.Scripts = nil
+		},
+	}
+	for name, corrupt := range tests {
+		corrupt := corrupt
+		t.Run(name, func(t *testing.T) {
+			var tr debug.Trace
+			assert.NoError(t, json.Unmarshal(b, &tr))
+			corrupt(&tr)
+
+			_, err := debug.NewSessionFromTrace(&tr)
+			assert.True(t, errors.Is(err, debug.ErrInvalidTrace), "got %v", err)
+		})
+	}
+
+	t.Run("empty scripts in json", func(t *testing.T) {
+		var tr debug.Trace
+		assert.NoError(t, json.Unmarshal(
+			[]byte(`{"steps":[{"scripts":[],"scriptIdx":0,"opcodeIdx":0}],"final":{"scripts":[]}}`), &tr))
+
+		_, err := debug.NewSessionFromTrace(&tr)
+		assert.True(t, errors.Is(err, debug.ErrInvalidTrace), "got %v", err)
+	})
+}
@@ -0,0 +1,321 @@
+package debug
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/bscript/interpreter"
+	"github.com/pkg/errors"
+)
+
+// traceEncodingVersion is the first byte of a binary encoded Trace.
+const traceEncodingVersion = 1
+
+// Trace is a step by step recording of the execution of a script. It can be
+// encoded as JSON or binary, so that a failed execution can be captured in one
+// place and stepped through, or replayed, in another.
+type Trace struct {
+	// Steps holds the state before each step of the execution.
+	Steps []*interpreter.State `json:"steps"`
+	// Final is the state once the execution has finished, nil if it never started.
+	Final *interpreter.State `json:"final"`
+	// Err is the error the execution failed with, empty if it succeeded.
+	Err string `json:"err"`
+}
+
+// Record executes the scripts configured by the provided options, such as
+// interpreter.WithTx(...) or interpreter.WithScripts(...), returning the trace
+// of the execution along with its outcome.
+//
+// Example usage:
+//
+//	trace, err := debug.Record(
+//	    interpreter.WithTx(tx, 0, prevOutput),
+//	    interpreter.WithForkID(),
+//	    interpreter.WithAfterGenesis(),
+//	)
+//	if err != nil {
+//	    b, _ := json.Marshal(trace)
+//	    log.Printf("input 0 failed: %s", b)
+//	}
+func Record(oo ...interpreter.ExecutionOptionFunc) (*Trace, error) {
+	t := &Trace{}
+
+	d := NewDebugger()
+	t.Attach(d)
+
+	err := interpreter.NewEngine().Execute(append(oo, interpreter.WithDebugger(d))...)
+	if err != nil {
+		t.Err = err.Error()
+	}
+
+	return t, err
+}
+
+// Attach attaches the trace to the debugger, so that it records each step of
+// the executions the debugger is used for.
+func (t *Trace) Attach(d DefaultDebugger) {
+	d.AttachBeforeStep(func(state *interpreter.State) {
+		t.Steps = append(t.Steps, state)
+	})
+	d.AttachAfterExecute(func(state *interpreter.State) {
+		t.Final = state
+	})
+	d.AttachAfterError(func(_ *interpreter.State, err error) {
+		t.Err = err.Error()
+	})
+}
+
+// Replay re-executes the trace from the step at the index, by injecting the
+// state recorded for it with interpreter.WithState(...), checking that each
+// step after it, and the outcome, matches the trace. The options must configure
+// the execution as it was when recorded, with the same tx or scripts and flags.
+//
+// An error wrapping ErrReplayMismatch is returned for the first difference.
+func (t *Trace) Replay(from int, oo ...interpreter.ExecutionOptionFunc) error {
+	if from < 0 || from >= len(t.Steps) {
+		return errors.Errorf("trace has no step %d", from)
+	}
+	if err := validateStep(t.Steps[from]); err != nil {
+		return errors.Wrapf(err, "step %d", from)
+	}
+
+	// The state is copied, as the thread takes ownership of what it is given.
+	b, err := t.Steps[from].MarshalBinary()
+	if err != nil {
+		return err
+	}
+	var state interpreter.State
+	if err = state.UnmarshalBinary(b); err != nil {
+		return err
+	}
+
+	replay, _ := Record(append(oo, interpreter.WithState(&state))...)
+
+	for i, step := range t.Steps[from:] {
+		if i >= len(replay.Steps) {
+			return errors.Wrapf(ErrReplayMismatch, "replay finished at step %d, trace has %d steps",
+				from+i, len(t.Steps))
+		}
+		if err = compareStates(replay.Steps[i], step); err != nil {
+			return errors.Wrapf(err, "step %d", from+i)
+		}
+	}
+	if len(replay.Steps) > len(t.Steps)-from {
+		return errors.Wrapf(ErrReplayMismatch, "replay continued past the %d steps of the trace", len(t.Steps))
+	}
+	if t.Final != nil && replay.Final != nil {
+		if err = compareStates(replay.Final, t.Final); err != nil {
+			return errors.Wrap(err, "final state")
+		}
+	}
+	if replay.Err != t.Err {
+		return errors.Wrapf(ErrReplayMismatch, "replay ended with %q, trace ended with %q", replay.Err, t.Err)
+	}
+
+	return nil
+}
+
+// validate checks that each step of the trace points at an opcode of its scripts,
+// and that the final state, if any, points at one of its scripts, so that a trace
+// decoded from elsewhere can be stepped through safely.
+func (t *Trace) validate() error {
+	for i, step := range t.Steps {
+		if err := validateStep(step); err != nil {
+			return errors.Wrapf(err, "step %d", i)
+		}
+	}
+	if t.Final != nil {
+		if t.Final.ScriptIdx < 0 || t.Final.ScriptIdx >= len(t.Final.Scripts) {
+			return errors.Wrapf(ErrInvalidTrace, "final state script %d of %d",
+				t.Final.ScriptIdx, len(t.Final.Scripts))
+		}
+	}
+
+	return nil
+}
+
+// validateStep checks that the state of a step points at the opcode about to be
+// executed.
+func validateStep(state *interpreter.State) error {
+	if state == nil {
+		return errors.Wrap(ErrInvalidTrace, "missing state")
+	}
+	if state.ScriptIdx < 0 || state.ScriptIdx >= len(state.Scripts) {
+		return errors.Wrapf(ErrInvalidTrace, "script %d of %d", state.ScriptIdx, len(state.Scripts))
+	}
+	if state.OpcodeIdx < 0 || state.OpcodeIdx >= len(state.Scripts[state.ScriptIdx]) {
+		return errors.Wrapf(ErrInvalidTrace, "script %d op %d of %d",
+			state.ScriptIdx, state.OpcodeIdx, len(state.Scripts[state.ScriptIdx]))
+	}
+
+	return nil
+}
+
+// compareStates returns an error wrapping ErrReplayMismatch describing the first
+// difference between the position and stacks of a replayed and recorded state.
+func compareStates(replayed, recorded *interpreter.State) error {
+	if replayed.ScriptIdx != recorded.ScriptIdx || replayed.OpcodeIdx != recorded.OpcodeIdx {
+		return errors.Wrapf(ErrReplayMismatch, "replay at %d:%d, trace at %d:%d",
+			replayed.ScriptIdx, replayed.OpcodeIdx, recorded.ScriptIdx, recorded.OpcodeIdx)
+	}
+
+	for _, s := range []struct {
+		name               string
+		replayed, recorded [][]byte
+	}{
+		{"data stack", replayed.DataStack, recorded.DataStack},
+		{"alt stack", replayed.AltStack, recorded.AltStack},
+		{"else stack", replayed.ElseStack, recorded.ElseStack},
+	} {
+		if !stacksEqual(s.replayed, s.recorded) {
+			return errors.Wrapf(ErrReplayMismatch, "replay %s %s, trace %s %s",
+				s.name, formatStack(s.replayed), s.name, formatStack(s.recorded))
+		}
+	}
+
+	if len(replayed.CondStack) != len(recorded.CondStack) {
+		return errors.Wrapf(ErrReplayMismatch, "replay condition stack %v, trace condition stack %v",
+			replayed.CondStack, recorded.CondStack)
+	}
+	for i := range replayed.CondStack {
+		if replayed.CondStack[i] != recorded.CondStack[i] {
+			return errors.Wrapf(ErrReplayMismatch, "replay condition stack %v, trace condition stack %v",
+				replayed.CondStack, recorded.CondStack)
+		}
+	}
+
+	return nil
+}
+
+func stacksEqual(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// MarshalBinary encodes the trace in a compact binary form, which is restored via
+// UnmarshalBinary. Its layout is a version byte, the number of steps as a VarInt,
+// then each step, the final state and the error, prefixed by their lengths as
+// VarInts, with a zero length for no final state.
+func (t *Trace) MarshalBinary() ([]byte, error) {
+	buf := []byte{traceEncodingVersion}
+	buf = append(buf, bt.VarInt(len(t.Steps)).Bytes()...)
+
+	for _, state := range append(t.Steps[:len(t.Steps):len(t.Steps)], t.Final) {
+		if state == nil {
+			buf = append(buf, bt.VarInt(0).Bytes()...)
+			continue
+		}
+		b, err := state.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, bt.VarInt(len(b)).Bytes()...)
+		buf = append(buf, b...)
+	}
+
+	buf = append(buf, bt.VarInt(len(t.Err)).Bytes()...)
+	return append(buf, t.Err...), nil
+}
+
+// UnmarshalBinary decodes a trace encoded by MarshalBinary.
+func (t *Trace) UnmarshalBinary(b []byte) error {
+	r := bytes.NewReader(b)
+	tr, err := readTrace(r)
+	if err != nil {
+		return errors.Wrap(err, "invalid trace encoding")
+	}
+	if r.Len() > 0 {
+		return errors.Errorf("invalid trace encoding: %d bytes of trailing data", r.Len())
+	}
+
+	*t = *tr
+	return nil
+}
+
+func readTrace(r *bytes.Reader) (*Trace, error) {
+	version, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if version != traceEncodingVersion {
+		return nil, errors.Errorf("unknown version %d", version)
+	}
+
+	n, err := readInt(r)
+	if err != nil {
+		return nil, err
+	}
+	if n > r.Len() {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	t := &Trace{Steps: make([]*interpreter.State, n)}
+	for i := range t.Steps {
+		if t.Steps[i], err = readState(r); err != nil {
+			return nil, errors.Wrapf(err, "step %d", i)
+		}
+		if t.Steps[i] == nil {
+			return nil, errors.Errorf("step %d: missing state", i)
+		}
+	}
+	if t.Final, err = readState(r); err != nil {
+		return nil, errors.Wrap(err, "final state")
+	}
+
+	b, err := readBytes(r)
+	if err != nil {
+		return nil, err
+	}
+	t.Err = string(b)
+
+	return t, nil
+}
+
+// readState reads a length prefixed state, returning nil for a zero length.
+func readState(r *bytes.Reader) (*interpreter.State, error) {
+	b, err := readBytes(r)
+	if err != nil || len(b) == 0 {
+		return nil, err
+	}
+
+	var state interpreter.State
+	if err = state.UnmarshalBinary(b); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+func readBytes(r *bytes.Reader) ([]byte, error) {
+	n, err := readInt(r)
+	if err != nil {
+		return nil, err
+	}
+	if n > r.Len() {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	b := make([]byte, n)
+	_, err = io.ReadFull(r, b)
+	return b, err
+}
+
+// readInt reads a VarInt which fits in an int.
+func readInt(r io.Reader) (int, error) {
+	var v bt.VarInt
+	if _, err := v.ReadFrom(r); err != nil {
+		return 0, err
+	}
+	if uint64(v) > uint64(^uint(0)>>1) {
+		return 0, errors.Errorf("value %d out of range", uint64(v))
+	}
+	return int(v), nil
+}
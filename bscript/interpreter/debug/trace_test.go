@@ -0,0 +1,170 @@
+package debug_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+	"github.com/mvc-labs/mvc-lib-go/bscript/interpreter"
+	"github.com/mvc-labs/mvc-lib-go/bscript/interpreter/debug"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func testScriptOptions(t *testing.T, lockingASM, unlockingASM string) []interpreter.ExecutionOptionFunc {
+	lockingScript, err := bscript.ParseASM(lockingASM)
+	assert.NoError(t, err)
+	unlockingScript, err := bscript.ParseASM(unlockingASM)
+	assert.NoError(t, err)
+
+	return []interpreter.ExecutionOptionFunc{
+		interpreter.WithScripts(lockingScript, unlockingScript),
+		interpreter.WithAfterGenesis(),
+	}
+}
+
+func TestRecord(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		lockingASM   string
+		unlockingASM string
+		expSteps     int
+		expErr       string
+	}{
+		"success": {
+			lockingASM:   "OP_IF OP_2 OP_3 OP_ADD OP_ELSE OP_4 OP_ENDIF OP_5 OP_EQUAL",
+			unlockingASM: "OP_1",
+			expSteps:     10,
+		},
+		"failure": {
+			lockingASM:   "OP_TOALTSTACK OP_2 OP_FROMALTSTACK OP_ADD OP_4 OP_EQUAL",
+			unlockingASM: "OP_1",
+			expSteps:     7,
+			expErr:       "false stack entry at end of script execution",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			oo := testScriptOptions(t, test.lockingASM, test.unlockingASM)
+			trace, err := debug.Record(oo...)
+			assert.Len(t, trace.Steps, test.expSteps)
+			assert.NotNil(t, trace.Final)
+			if test.expErr == "" {
+				assert.NoError(t, err)
+				assert.Empty(t, trace.Err)
+			} else {
+				assert.EqualError(t, err, test.expErr)
+				assert.Equal(t, test.expErr, trace.Err)
+			}
+
+			b, err := json.Marshal(trace)
+			assert.NoError(t, err)
+			var fromJSON debug.Trace
+			assert.NoError(t, json.Unmarshal(b, &fromJSON))
+
+			b, err = trace.MarshalBinary()
+			assert.NoError(t, err)
+			var fromBinary debug.Trace
+			assert.NoError(t, fromBinary.UnmarshalBinary(b))
+
+			for _, decoded := range []*debug.Trace{&fromJSON, &fromBinary} {
+				assert.Len(t, decoded.Steps, test.expSteps)
+				assert.Equal(t, trace.Err, decoded.Err)
+				assert.Equal(t, trace.Final.DataStack, decoded.Final.DataStack)
+				for i := range trace.Steps {
+					assert.Equal(t, trace.Steps[i].DataStack, decoded.Steps[i].DataStack)
+					assert.Equal(t, trace.Steps[i].Opcode().Name(), decoded.Steps[i].Opcode().Name())
+				}
+
+				// A decoded trace replays just as the original does.
+				for i := range decoded.Steps {
+					assert.NoError(t, decoded.Replay(i, oo...), "step %d", i)
+				}
+			}
+		})
+	}
+}
+
+func TestTrace_Replay(t *testing.T) {
+	t.Parallel()
+
+	oo := testScriptOptions(t, "OP_IF OP_2 OP_3 OP_ADD OP_ELSE OP_4 OP_ENDIF OP_5 OP_EQUAL", "OP_1")
+
+	t.Run("tampered stack", func(t *testing.T) {
+		trace, err := debug.Record(oo...)
+		assert.NoError(t, err)
+		trace.Steps[4].DataStack[0] = []byte{0x09}
+
+		assert.NoError(t, trace.Replay(5, oo...))
+		err = trace.Replay(3, oo...)
+		assert.True(t, errors.Is(err, debug.ErrReplayMismatch))
+		assert.EqualError(t, err, "step 4: replay data stack [02 03], trace data stack [09 03]: replay does not match trace")
+
+		// Replaying from the tampered step carries it into the next.
+		err = trace.Replay(4, oo...)
+		assert.EqualError(t, err, "step 5: replay data stack [0c], trace data stack [05]: replay does not match trace")
+	})
+
+	t.Run("tampered outcome", func(t *testing.T) {
+		trace, err := debug.Record(oo...)
+		assert.NoError(t, err)
+		trace.Err = "false stack entry at end of script execution"
+
+		err = trace.Replay(0, oo...)
+		assert.True(t, errors.Is(err, debug.ErrReplayMismatch))
+	})
+
+	t.Run("missing steps", func(t *testing.T) {
+		trace, err := debug.Record(oo...)
+		assert.NoError(t, err)
+		trace.Steps = trace.Steps[:8]
+
+		err = trace.Replay(0, oo...)
+		assert.EqualError(t, err, "replay continued past the 8 steps of the trace: replay does not match trace")
+	})
+
+	t.Run("no such step", func(t *testing.T) {
+		trace, err := debug.Record(oo...)
+		assert.NoError(t, err)
+
+		assert.EqualError(t, trace.Replay(10, oo...), "trace has no step 10")
+	})
+}
+
+func TestTrace_UnmarshalBinary(t *testing.T) {
+	t.Parallel()
+
+	trace, err := debug.Record(testScriptOptions(t, "OP_2 OP_EQUAL", "OP_2")...)
+	assert.NoError(t, err)
+	b, err := trace.MarshalBinary()
+	assert.NoError(t, err)
+
+	tests := map[string]struct {
+		b []byte
+	}{
+		"empty": {
+			b: []byte{},
+		},
+		"unknown version": {
+			b: append([]byte{0x02}, b[1:]...),
+		},
+		"truncated": {
+			b: b[:len(b)-2],
+		},
+		"trailing data": {
+			b: append(append([]byte{}, b...), 0x00),
+		},
+		"missing step": {
+			b: []byte{0x01, 0x01, 0x00, 0x00, 0x00},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var trace debug.Trace
+			assert.Error(t, trace.UnmarshalBinary(test.b))
+		})
+	}
+}
@@ -44,6 +44,11 @@ func (e *engine) Execute(oo ...ExecutionOptionFunc) error {
 	for _, o := range oo {
 		o(opts)
 	}
+	if opts.ctx != nil {
+		if err := opts.ctx.Err(); err != nil {
+			return contextError(err)
+		}
+	}
 
 	t, err := createThread(opts)
 	if err != nil {
@@ -4,8 +4,10 @@
 package interpreter
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/mvc-labs/mvc-lib-go"
 	"github.com/mvc-labs/mvc-lib-go/bscript"
@@ -56,7 +58,7 @@ func TestBadPC(t *testing.T) {
 	for _, test := range tests {
 		vm := &thread{
 			scriptParser: &DefaultOpcodeParser{},
-			cfg:          &beforeGenesisConfig{},
+			limits:       NewBeforeGenesisLimits(),
 		}
 		err := vm.apply(&execOpts{
 			previousTxOut: txOut,
@@ -112,7 +114,7 @@ func TestCheckErrorCondition(t *testing.T) {
 
 	vm := &thread{
 		scriptParser: &DefaultOpcodeParser{},
-		cfg:          &beforeGenesisConfig{},
+		limits:       NewBeforeGenesisLimits(),
 	}
 
 	err = vm.apply(&execOpts{
@@ -432,7 +434,7 @@ func TestInvalidFlagCombinations(t *testing.T) {
 	for i, test := range tests {
 		vm := &thread{
 			scriptParser: &DefaultOpcodeParser{},
-			cfg:          &beforeGenesisConfig{},
+			limits:       NewBeforeGenesisLimits(),
 		}
 		err := vm.apply(&execOpts{
 			tx:            tx,
@@ -927,3 +929,235 @@ func TestEngine_WithState(t *testing.T) {
 		})
 	}
 }
+
+func TestWithLimits(t *testing.T) {
+	t.Parallel()
+
+	newLimits := func(f func(l *Limits)) *Limits {
+		l := &Limits{
+			MaxOps:                10,
+			MaxStackSize:          10,
+			MaxScriptSize:         10,
+			MaxScriptElementSize:  10,
+			MaxScriptNumberLength: 4,
+			MaxPubKeysPerMultiSig: 3,
+		}
+		f(l)
+		return l
+	}
+
+	tests := map[string]struct {
+		lockingASM    string
+		unlockingASM  string
+		limits        *Limits
+		beforeGenesis bool
+		expErr        error
+	}{
+		"within limits": {
+			lockingASM:   "OP_NOP OP_NOP OP_NOP",
+			unlockingASM: "OP_1",
+			limits:       newLimits(func(l *Limits) {}),
+		},
+		"too many ops": {
+			lockingASM:   "OP_NOP OP_NOP OP_NOP",
+			unlockingASM: "OP_1",
+			limits:       newLimits(func(l *Limits) { l.MaxOps = 2 }),
+			expErr:       errs.NewError(errs.ErrTooManyOperations, ""),
+		},
+		"stack overflow": {
+			lockingASM:   "OP_DROP OP_DROP",
+			unlockingASM: "OP_1 OP_1 OP_1",
+			limits:       newLimits(func(l *Limits) { l.MaxStackSize = 2 }),
+			expErr:       errs.NewError(errs.ErrStackOverflow, ""),
+		},
+		"script too big": {
+			lockingASM:   "OP_NOP OP_NOP OP_NOP",
+			unlockingASM: "OP_1",
+			limits:       newLimits(func(l *Limits) { l.MaxScriptSize = 2 }),
+			expErr:       errs.NewError(errs.ErrScriptTooBig, ""),
+		},
+		"element too big": {
+			lockingASM:   "OP_DROP OP_1",
+			unlockingASM: "aabbcc",
+			limits:       newLimits(func(l *Limits) { l.MaxScriptElementSize = 2 }),
+			expErr:       errs.NewError(errs.ErrElementTooBig, ""),
+		},
+		"number too big": {
+			lockingASM:   "OP_1ADD",
+			unlockingASM: "aabbcc",
+			limits:       newLimits(func(l *Limits) { l.MaxScriptNumberLength = 2 }),
+			expErr:       errs.NewError(errs.ErrNumberTooBig, ""),
+		},
+		"too many pubkeys": {
+			lockingASM:   "OP_0 OP_0 OP_0 OP_2 OP_CHECKMULTISIG",
+			unlockingASM: "OP_0",
+			limits:       newLimits(func(l *Limits) { l.MaxPubKeysPerMultiSig = 1 }),
+			expErr:       errs.NewError(errs.ErrInvalidPubKeyCount, ""),
+		},
+		"zero limits keep consensus values": {
+			lockingASM:   "OP_DROP OP_1",
+			unlockingASM: "aabbccddeeff",
+			limits:       &Limits{MaxOps: 1000},
+		},
+		"stack size of max int": {
+			lockingASM:   "OP_DROP OP_1",
+			unlockingASM: "OP_1 OP_1",
+			limits:       newLimits(func(l *Limits) { l.MaxStackSize = int(^uint(0) >> 1) }),
+		},
+		"ignored before genesis": {
+			lockingASM:    "OP_NOP OP_NOP OP_NOP",
+			unlockingASM:  "OP_1",
+			limits:        newLimits(func(l *Limits) { l.MaxOps = 2 }),
+			beforeGenesis: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			lockingScript, err := bscript.ParseASM(test.lockingASM)
+			assert.NoError(t, err)
+			unlockingScript, err := bscript.ParseASM(test.unlockingASM)
+			assert.NoError(t, err)
+
+			opts := &execOpts{lockingScript: lockingScript, unlockingScript: unlockingScript}
+			if !test.beforeGenesis {
+				WithAfterGenesis()(opts)
+			}
+			WithLimits(test.limits)(opts)
+
+			// The thread is created directly, as the engine requires a tx for
+			// scripts containing an OP_CHECKMULTISIG.
+			vm := &thread{
+				scriptParser: &DefaultOpcodeParser{},
+				limits:       NewBeforeGenesisLimits(),
+			}
+			err = vm.apply(opts)
+			if err == nil {
+				err = vm.execute()
+			}
+			assert.NoError(t, tstCheckScriptError(err, test.expErr))
+		})
+	}
+}
+
+func TestNewPolicyLimits(t *testing.T) {
+	t.Parallel()
+
+	lockingScript, err := bscript.ParseASM("OP_1ADD OP_DROP OP_1")
+	assert.NoError(t, err)
+	unlockingScript := &bscript.Script{}
+	assert.NoError(t, unlockingScript.AppendPushData(make([]byte, MaxScriptNumberLengthPolicyAfterGenesis+1)))
+
+	assert.NoError(t, NewEngine().Execute(
+		WithScripts(lockingScript, unlockingScript),
+		WithAfterGenesis(),
+	))
+
+	err = NewEngine().Execute(
+		WithScripts(lockingScript, unlockingScript),
+		WithAfterGenesis(),
+		WithLimits(NewPolicyLimits()),
+	)
+	assert.True(t, errs.IsErrorCode(err, errs.ErrNumberTooBig), err)
+}
+
+// cancelDebugger cancels the execution before the opcode at the index.
+type cancelDebugger struct {
+	nopDebugger
+	cancel    context.CancelFunc
+	opcodeIdx int
+}
+
+func (c *cancelDebugger) BeforeExecuteOpcode(s *State) {
+	if s.OpcodeIdx == c.opcodeIdx {
+		c.cancel()
+	}
+}
+
+func TestEngine_Execute_WithContext(t *testing.T) {
+	t.Parallel()
+
+	lockingScript, err := bscript.ParseASM("OP_1 OP_2 OP_ADD OP_3 OP_EQUAL")
+	assert.NoError(t, err)
+	unlockingScript := &bscript.Script{}
+
+	t.Run("completes", func(t *testing.T) {
+		assert.NoError(t, NewEngine().Execute(
+			WithContext(context.Background()),
+			WithScripts(lockingScript, unlockingScript),
+			WithAfterGenesis(),
+		))
+	})
+
+	t.Run("canceled before starting", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := NewEngine().Execute(WithContext(ctx), WithScripts(lockingScript, unlockingScript), WithAfterGenesis())
+		assert.True(t, errs.IsErrorCode(err, errs.ErrCanceled))
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("deadline passed before starting", func(t *testing.T) {
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancel()
+
+		err := NewEngine().Execute(WithContext(ctx), WithScripts(lockingScript, unlockingScript), WithAfterGenesis())
+		assert.True(t, errs.IsErrorCode(err, errs.ErrTimeout))
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("canceled between opcodes", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		d := &cancelDebugger{cancel: cancel, opcodeIdx: 2}
+
+		var executed []int
+		err := NewEngine().Execute(
+			WithContext(ctx),
+			WithScripts(lockingScript, unlockingScript),
+			WithAfterGenesis(),
+			WithDebugger(&stepRecorder{Debugger: d, steps: &executed}),
+		)
+		assert.True(t, errs.IsErrorCode(err, errs.ErrCanceled))
+		assert.Equal(t, []int{0, 1, 2}, executed)
+	})
+
+	t.Run("canceled within OP_CHECKMULTISIG", func(t *testing.T) {
+		lockingScript, err := bscript.ParseASM(
+			"OP_1 02fc4c9ce1a8ab6e9f0ce0e2a4e1b6c30be4b2fe6b0a1c7a8fd56d0f8ce8c6e0d8 OP_1 OP_CHECKMULTISIG")
+		assert.NoError(t, err)
+		unlockingScript, err := bscript.ParseASM("OP_0 3006020101020101")
+		assert.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		opts := &execOpts{lockingScript: lockingScript, unlockingScript: unlockingScript, ctx: ctx}
+		WithAfterGenesis()(opts)
+		WithDebugger(&cancelDebugger{cancel: cancel, opcodeIdx: 3})(opts)
+
+		// The thread is created directly, as the engine requires a tx for
+		// scripts containing an OP_CHECKMULTISIG.
+		vm := &thread{
+			scriptParser: &DefaultOpcodeParser{},
+			limits:       NewBeforeGenesisLimits(),
+		}
+		assert.NoError(t, vm.apply(opts))
+
+		var done bool
+		for !done && err == nil {
+			done, err = vm.Step()
+		}
+		assert.True(t, errs.IsErrorCode(err, errs.ErrCanceled), err)
+		assert.Equal(t, 3, vm.scriptOff)
+	})
+}
+
+// stepRecorder records the index of each opcode executed.
+type stepRecorder struct {
+	Debugger
+	steps *[]int
+}
+
+func (s *stepRecorder) BeforeExecuteOpcode(state *State) {
+	*s.steps = append(*s.steps, state.OpcodeIdx)
+	s.Debugger.BeforeExecuteOpcode(state)
+}
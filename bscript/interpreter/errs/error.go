@@ -323,6 +323,26 @@ const (
 	// set, but the ScriptEnableSighashForkID flag is not set.
 	ErrIllegalForkID
 
+	// -----------------------------
+	// Failures related to metering.
+	// -----------------------------
+
+	// ErrBudgetExceeded is returned when an execution takes the Profiler it
+	// is metered by over its Budget.
+	ErrBudgetExceeded
+
+	// ---------------------------------
+	// Failures related to cancellation.
+	// ---------------------------------
+
+	// ErrTimeout is returned when the deadline of the context.Context an
+	// execution is run with passes before it finishes.
+	ErrTimeout
+
+	// ErrCanceled is returned when the context.Context an execution is run
+	// with is canceled before it finishes.
+	ErrCanceled
+
 	// numErrorCodes is the maximum error code number used in tests.  This
 	// entry MUST be the last entry in the enum.
 	numErrorCodes
@@ -392,6 +412,9 @@ var errorCodeStrings = map[ErrorCode]string{
 	ErrNegativeLockTime:         "ErrNegativeLockTime",
 	ErrUnsatisfiedLockTime:      "ErrUnsatisfiedLockTime",
 	ErrIllegalForkID:            "ErrIllegalForkID",
+	ErrBudgetExceeded:           "ErrBudgetExceeded",
+	ErrTimeout:                  "ErrTimeout",
+	ErrCanceled:                 "ErrCanceled",
 }
 
 // String returns the ErrorCode as a human-readable name.
@@ -416,6 +439,9 @@ func (e ErrorCode) String() string {
 type Error struct {
 	ErrorCode   ErrorCode
 	Description string
+	// Err is the error which caused this one, if any, such as the error of the
+	// context.Context for an ErrTimeout.
+	Err error
 }
 
 // Error satisfies the error interface and prints human-readable errors.
@@ -423,6 +449,11 @@ func (e Error) Error() string {
 	return e.Description
 }
 
+// Unwrap returns the error which caused this one, if any.
+func (e Error) Unwrap() error {
+	return e.Err
+}
+
 // NewError creates an Error given a set of arguments.
 func NewError(c ErrorCode, desc string, fmtArgs ...interface{}) Error {
 	return Error{ErrorCode: c, Description: fmt.Sprintf(desc, fmtArgs...)}
@@ -77,6 +77,9 @@ func TestErrorCodeStringer(t *testing.T) {
 		{ErrNegativeLockTime, "ErrNegativeLockTime"},
 		{ErrUnsatisfiedLockTime, "ErrUnsatisfiedLockTime"},
 		{ErrIllegalForkID, "ErrIllegalForkID"},
+		{ErrBudgetExceeded, "ErrBudgetExceeded"},
+		{ErrTimeout, "ErrTimeout"},
+		{ErrCanceled, "ErrCanceled"},
 		{0xffff, "Unknown ErrorCode (65535)"},
 	}
 
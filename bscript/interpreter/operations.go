@@ -958,9 +958,9 @@ func opcodeCat(op *ParsedOpcode, t *thread) error {
 	}
 
 	c := bytes.Join([][]byte{a, b}, nil)
-	if len(c) > t.cfg.MaxScriptElementSize() {
+	if len(c) > t.limits.MaxScriptElementSize {
 		return errs.NewError(errs.ErrElementTooBig,
-			"concatenated size %d exceeds max allowed size %d", len(c), t.cfg.MaxScriptElementSize())
+			"concatenated size %d exceeds max allowed size %d", len(c), t.limits.MaxScriptElementSize)
 	}
 
 	t.dstack.PushByteArray(c)
@@ -1013,8 +1013,8 @@ func opcodeNum2bin(op *ParsedOpcode, t *thread) error {
 		return err
 	}
 
-	if n.GreaterThanInt(int64(t.cfg.MaxScriptElementSize())) {
-		return errs.NewError(errs.ErrNumberTooBig, "n is larger than the max of %d", t.cfg.MaxScriptElementSize())
+	if n.GreaterThanInt(int64(t.limits.MaxScriptElementSize)) {
+		return errs.NewError(errs.ErrNumberTooBig, "n is larger than the max of %d", t.limits.MaxScriptElementSize)
 	}
 
 	// encode a as a script num so that we we take the bytes it
@@ -1061,8 +1061,8 @@ func opcodeBin2num(op *ParsedOpcode, t *thread) error {
 	}
 
 	b := minimallyEncode(a)
-	if len(b) > t.cfg.MaxScriptNumberLength() {
-		return errs.NewError(errs.ErrNumberTooBig, "script numbers are limited to %d bytes", t.cfg.MaxScriptNumberLength())
+	if len(b) > t.limits.MaxScriptNumberLength {
+		return errs.NewError(errs.ErrNumberTooBig, "script numbers are limited to %d bytes", t.limits.MaxScriptNumberLength)
 	}
 
 	t.dstack.PushByteArray(b)
@@ -1821,6 +1821,9 @@ func opcodeRipemd160(op *ParsedOpcode, t *thread) error {
 	if err != nil {
 		return err
 	}
+	if err = t.meter.bytesHashed(len(buf)); err != nil {
+		return err
+	}
 
 	t.dstack.PushByteArray(calcHash(buf, ripemd160.New()))
 	return nil
@@ -1835,6 +1838,9 @@ func opcodeSha1(op *ParsedOpcode, t *thread) error {
 	if err != nil {
 		return err
 	}
+	if err = t.meter.bytesHashed(len(buf)); err != nil {
+		return err
+	}
 
 	hash := sha1.Sum(buf) //nolint:gosec // operation is for sha1
 	t.dstack.PushByteArray(hash[:])
@@ -1850,6 +1856,9 @@ func opcodeSha256(op *ParsedOpcode, t *thread) error {
 	if err != nil {
 		return err
 	}
+	if err = t.meter.bytesHashed(len(buf)); err != nil {
+		return err
+	}
 
 	hash := sha256.Sum256(buf)
 	t.dstack.PushByteArray(hash[:])
@@ -1865,6 +1874,9 @@ func opcodeHash160(op *ParsedOpcode, t *thread) error {
 	if err != nil {
 		return err
 	}
+	if err = t.meter.bytesHashed(len(buf)); err != nil {
+		return err
+	}
 
 	hash := sha256.Sum256(buf)
 	t.dstack.PushByteArray(calcHash(hash[:], ripemd160.New()))
@@ -1880,6 +1892,9 @@ func opcodeHash256(op *ParsedOpcode, t *thread) error {
 	if err != nil {
 		return err
 	}
+	if err = t.meter.bytesHashed(len(buf)); err != nil {
+		return err
+	}
 
 	t.dstack.PushByteArray(crypto.Sha256d(buf))
 	return nil
@@ -1969,10 +1984,7 @@ func opcodeCheckSig(op *ParsedOpcode, t *thread) error {
 		return err
 	}
 
-	txCopy := t.tx.Clone()
-	txCopy.Inputs[t.inputIdx].PreviousTxScript = up
-
-	hash, err = txCopy.CalcInputSignatureHash(uint32(t.inputIdx), shf)
+	hash, err = t.signatureHash(up, shf)
 	if err != nil {
 		t.dstack.PushBool(false)
 		return err
@@ -1995,7 +2007,10 @@ func opcodeCheckSig(op *ParsedOpcode, t *thread) error {
 		return nil //nolint:nilerr // only need a false push in this case
 	}
 
-	ok := signature.Verify(hash, pubKey)
+	ok, err := t.verifySignature(hash, signature, sigBytes, pubKey, pkBytes)
+	if err != nil {
+		return err
+	}
 	if !ok && t.hasFlag(scriptflag.VerifyNullFail) && len(sigBytes) > 0 {
 		return errs.NewError(errs.ErrNullFail, "signature not empty on failed checksig")
 	}
@@ -2055,16 +2070,16 @@ func opcodeCheckMultiSig(op *ParsedOpcode, t *thread) error {
 	if numPubKeys < 0 {
 		return errs.NewError(errs.ErrInvalidPubKeyCount, "number of pubkeys %d is negative", numPubKeys)
 	}
-	if numPubKeys > t.cfg.MaxPubKeysPerMultiSig() {
+	if numPubKeys > t.limits.MaxPubKeysPerMultiSig {
 		return errs.NewError(
 			errs.ErrInvalidPubKeyCount,
 			"too many pubkeys: %d > %d",
-			numPubKeys, t.cfg.MaxPubKeysPerMultiSig(),
+			numPubKeys, t.limits.MaxPubKeysPerMultiSig,
 		)
 	}
 	t.numOps += numPubKeys
-	if t.numOps > t.cfg.MaxOps() {
-		return errs.NewError(errs.ErrTooManyOperations, "exceeded max operation limit of %d", t.cfg.MaxOps())
+	if t.numOps > t.limits.MaxOps {
+		return errs.NewError(errs.ErrTooManyOperations, "exceeded max operation limit of %d", t.limits.MaxOps)
 	}
 
 	pubKeys := make([][]byte, 0, numPubKeys)
@@ -2132,6 +2147,12 @@ func opcodeCheckMultiSig(op *ParsedOpcode, t *thread) error {
 	pubKeyIdx := -1
 	signatureIdx := 0
 	for numSignatures > 0 {
+		// Each signature check can be costly, so stop early if the execution
+		// has been canceled.
+		if err := t.checkContext(); err != nil {
+			return err
+		}
+
 		// When there are more signatures than public keys remaining,
 		// there is no way to succeed since too many signatures are
 		// invalid, so exit early.
@@ -2210,16 +2231,17 @@ func opcodeCheckMultiSig(op *ParsedOpcode, t *thread) error {
 		}
 
 		// Generate the signature hash based on the signature hash type.
-		txCopy := t.tx.Clone()
-		txCopy.Inputs[t.inputIdx].PreviousTxScript = up
-
-		signatureHash, err := txCopy.CalcInputSignatureHash(uint32(t.inputIdx), shf)
+		signatureHash, err := t.signatureHash(up, shf)
 		if err != nil {
 			t.dstack.PushBool(false)
 			return nil //nolint:nilerr // only need a false push in this case
 		}
 
-		if ok := parsedSig.Verify(signatureHash, parsedPubKey); ok {
+		ok, err := t.verifySignature(signatureHash, parsedSig, signature, parsedPubKey, pubKey)
+		if err != nil {
+			return err
+		}
+		if ok {
 			// PubKey verified, move on to the next signature.
 			signatureIdx++
 			numSignatures--
@@ -1,6 +1,8 @@
 package interpreter
 
 import (
+	"context"
+
 	"github.com/mvc-labs/mvc-lib-go"
 	"github.com/mvc-labs/mvc-lib-go/bscript"
 	"github.com/mvc-labs/mvc-lib-go/bscript/interpreter/scriptflag"
@@ -77,3 +79,73 @@ func WithState(state *State) ExecutionOptionFunc {
 		p.state = state
 	}
 }
+
+// WithSigCache configure the execution to use the provided *interpreter.SigCache,
+// skipping the verification of any signature already held in it, and adding those
+// found to be valid.
+func WithSigCache(sc *SigCache) ExecutionOptionFunc {
+	return func(p *execOpts) {
+		p.sigCache = sc
+	}
+}
+
+// WithSigHashes configure the execution to use the provided precomputed *bt.SigHashes
+// when calculating signature hashes, rather than calculating them from the tx.
+//
+// The *bt.SigHashes must have been created from the tx being executed, for example
+// via `bt.NewSigHashes(tx)`, and can be shared by the executions of each of its inputs.
+// If not provided, they are calculated on the first signature check of an execution.
+func WithSigHashes(sh *bt.SigHashes) ExecutionOptionFunc {
+	return func(p *execOpts) {
+		p.sigHashes = sh
+	}
+}
+
+// WithLimits configure the execution to apply the provided *interpreter.Limits, for
+// example `interpreter.NewPolicyLimits()`, in place of the consensus limits. Any limit
+// left as zero keeps its consensus value, so `&interpreter.Limits{MaxOps: 1000}` only
+// limits the number of ops.
+//
+// The limits only apply to an after-genesis context, as the limits before genesis
+// are fixed by consensus.
+func WithLimits(l *Limits) ExecutionOptionFunc {
+	return func(p *execOpts) {
+		p.limits = l
+	}
+}
+
+// WithProfiler configure the execution to record the cost of each opcode executed
+// with the provided *interpreter.Profiler, aborting the execution if it takes the
+// profiler over its budget.
+func WithProfiler(p *Profiler) ExecutionOptionFunc {
+	return func(o *execOpts) {
+		o.profiler = p
+	}
+}
+
+// WithContext configure the execution to stop once the provided context is done.
+//
+// The context is checked before starting, between opcodes and within costly opcodes,
+// such as the signature checks of an OP_CHECKMULTISIG. If it is done an error is
+// returned with the errs.ErrTimeout code, if its deadline passed, or errs.ErrCanceled,
+// either wrapping the error of the context.
+//
+// Example usage:
+//
+//	ctx, cancel := context.WithTimeout(ctx, time.Second)
+//	defer cancel()
+//	if err := engine.Execute(
+//	    interpreter.WithTx(tx, inputIdx, previousOutput),
+//	    interpreter.WithAfterGenesis(),
+//	    interpreter.WithForkID(),
+//	    interpreter.WithContext(ctx),
+//	); err != nil {
+//	    if errs.IsErrorCode(err, errs.ErrTimeout) {
+//	        // handle timeout
+//	    }
+//	}
+func WithContext(ctx context.Context) ExecutionOptionFunc {
+	return func(p *execOpts) {
+		p.ctx = ctx
+	}
+}
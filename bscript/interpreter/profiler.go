@@ -0,0 +1,226 @@
+package interpreter
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mvc-labs/mvc-lib-go/bscript/interpreter/errs"
+)
+
+// Budget limits the cost of the executions metered by a Profiler. A zero limit is
+// unlimited.
+//
+// The budget is checked after each opcode is executed, and within the opcodes
+// which hash data, check signatures or decode numbers before doing that work, so
+// an execution will be aborted before the costly part of the opcode which would
+// take it over budget.
+type Budget struct {
+	// MaxOpcodes is the max number of opcodes executed, including data pushes but
+	// not opcodes skipped in non-executing branches.
+	MaxOpcodes int
+	// MaxDuration is the max wall time spent executing opcodes.
+	MaxDuration time.Duration
+	// MaxStackDepth is the max number of items on the main and alt stacks combined.
+	MaxStackDepth int
+	// MaxBytesHashed is the max number of bytes hashed by the hashing opcodes.
+	MaxBytesHashed int
+	// MaxSigChecks is the max number of signatures checked.
+	MaxSigChecks int
+	// MaxNumberLength is the max size, in bytes, of any numeric operand.
+	MaxNumberLength int
+}
+
+// OpcodeProfile is the profile of the executions of an opcode.
+type OpcodeProfile struct {
+	Count    int
+	Duration time.Duration
+}
+
+// Profile is a snapshot of the executions recorded by a Profiler.
+type Profile struct {
+	// Opcodes holds the profile of each opcode executed, by name.
+	Opcodes map[string]OpcodeProfile
+	// Count is the number of opcodes executed. Opcodes skipped in non-executing
+	// branches are not counted, but the conditionals delimiting them are.
+	Count int
+	// Duration is the wall time spent executing the counted opcodes.
+	Duration time.Duration
+	// MaxStackDepth is the most items on the main and alt stacks combined after
+	// any opcode.
+	MaxStackDepth int
+	// BytesHashed is the number of bytes hashed by the hashing opcodes, such as
+	// OP_SHA256.
+	BytesHashed int
+	// SigChecks is the number of signatures checked by OP_CHECKSIG and
+	// OP_CHECKMULTISIG and their verify variants.
+	SigChecks int
+	// MaxNumberLength is the size, in bytes, of the largest numeric operand.
+	MaxNumberLength int
+	// NumberBytes is the total size, in bytes, of the numeric operands.
+	NumberBytes int
+}
+
+// Profiler records the cost of each opcode executed, when passed to executions via
+// interpreter.WithProfiler(...), optionally aborting any which take it over its
+// budget with an errs.ErrBudgetExceeded.
+//
+// A Profiler is safe for concurrent use, and the costs of every execution it is
+// passed to are added together, so one can meter each input of a tx verified
+// with interpreter.VerifyTx(...).
+type Profiler struct {
+	mu      sync.Mutex
+	budget  Budget
+	opcodes [256]OpcodeProfile
+	names   [256]string
+	profile Profile
+}
+
+// NewProfiler returns a profiler enforcing the budget. Pass a zero Budget to
+// record executions without limiting them.
+func NewProfiler(budget Budget) *Profiler {
+	return &Profiler{budget: budget}
+}
+
+// Profile returns a snapshot of the costs recorded so far.
+func (p *Profiler) Profile() *Profile {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	profile := p.profile
+	profile.Opcodes = make(map[string]OpcodeProfile)
+	for i, op := range p.opcodes {
+		if op.Count > 0 {
+			profile.Opcodes[p.names[i]] = op
+		}
+	}
+
+	return &profile
+}
+
+// Reset clears the costs recorded so far, keeping the budget.
+func (p *Profiler) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.opcodes = [256]OpcodeProfile{}
+	p.profile = Profile{}
+}
+
+// recordOpcode records the execution of the opcode, returning an error if the
+// profiler is now over budget.
+func (p *Profiler) recordOpcode(pop ParsedOpcode, d time.Duration, stackDepth int) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	op := &p.opcodes[pop.op.val]
+	if op.Count == 0 {
+		p.names[pop.op.val] = pop.Name()
+	}
+	op.Count++
+	op.Duration += d
+
+	p.profile.Count++
+	p.profile.Duration += d
+	if stackDepth > p.profile.MaxStackDepth {
+		p.profile.MaxStackDepth = stackDepth
+	}
+
+	return p.checkBudget(0)
+}
+
+// addBytesHashed, addSigCheck and addNumber record costs incurred within an
+// opcode which has been running for elapsed, returning an error if the profiler
+// is now over budget. They are called before the work is done, so that it can be
+// skipped.
+func (p *Profiler) addBytesHashed(n int, elapsed time.Duration) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.profile.BytesHashed += n
+	return p.checkBudget(elapsed)
+}
+
+func (p *Profiler) addSigCheck(elapsed time.Duration) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.profile.SigChecks++
+	return p.checkBudget(elapsed)
+}
+
+func (p *Profiler) addNumber(n int, elapsed time.Duration) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.profile.NumberBytes += n
+	if n > p.profile.MaxNumberLength {
+		p.profile.MaxNumberLength = n
+	}
+	return p.checkBudget(elapsed)
+}
+
+// checkBudget returns an error if the profiler is over budget, counting elapsed
+// as time spent by an opcode which is still running.
+func (p *Profiler) checkBudget(elapsed time.Duration) error {
+	if d := p.profile.Duration + elapsed; p.budget.MaxDuration > 0 && d > p.budget.MaxDuration {
+		return errs.NewError(errs.ErrBudgetExceeded, "budget exceeded: duration %s > %s",
+			d, p.budget.MaxDuration)
+	}
+
+	for _, l := range []struct {
+		name      string
+		used, max int
+	}{
+		{"opcodes", p.profile.Count, p.budget.MaxOpcodes},
+		{"stack depth", p.profile.MaxStackDepth, p.budget.MaxStackDepth},
+		{"bytes hashed", p.profile.BytesHashed, p.budget.MaxBytesHashed},
+		{"sig checks", p.profile.SigChecks, p.budget.MaxSigChecks},
+		{"number length", p.profile.MaxNumberLength, p.budget.MaxNumberLength},
+	} {
+		if l.max > 0 && l.used > l.max {
+			return errs.NewError(errs.ErrBudgetExceeded, "budget exceeded: %s %d > %d", l.name, l.used, l.max)
+		}
+	}
+
+	return nil
+}
+
+// meter charges the costs incurred within the opcode being executed by a thread
+// to its profiler. A nil meter, as used when executions are not profiled, does
+// nothing.
+type meter struct {
+	profiler *Profiler
+	start    time.Time // when the opcode being executed started
+}
+
+func newMeter(p *Profiler) *meter {
+	if p == nil {
+		return nil
+	}
+
+	return &meter{profiler: p}
+}
+
+func (m *meter) bytesHashed(n int) error {
+	if m == nil {
+		return nil
+	}
+
+	return m.profiler.addBytesHashed(n, time.Since(m.start))
+}
+
+func (m *meter) sigCheck() error {
+	if m == nil {
+		return nil
+	}
+
+	return m.profiler.addSigCheck(time.Since(m.start))
+}
+
+func (m *meter) number(n int) error {
+	if m == nil {
+		return nil
+	}
+
+	return m.profiler.addNumber(n, time.Since(m.start))
+}
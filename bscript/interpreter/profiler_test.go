@@ -0,0 +1,185 @@
+package interpreter_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+	"github.com/mvc-labs/mvc-lib-go/bscript/interpreter"
+	"github.com/mvc-labs/mvc-lib-go/bscript/interpreter/errs"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfiler(t *testing.T) {
+	t.Parallel()
+
+	lockingScript, err := bscript.ParseASM("OP_SHA256 OP_SIZE OP_NIP OP_ADD OP_DUP OP_TOALTSTACK OP_FROMALTSTACK 33 OP_EQUAL")
+	assert.NoError(t, err)
+	unlockingScript, err := bscript.ParseASM("OP_1 aabbccdd")
+	assert.NoError(t, err)
+
+	p := interpreter.NewProfiler(interpreter.Budget{})
+	assert.NoError(t, interpreter.NewEngine().Execute(
+		interpreter.WithScripts(lockingScript, unlockingScript),
+		interpreter.WithAfterGenesis(),
+		interpreter.WithProfiler(p),
+	))
+
+	profile := p.Profile()
+	assert.Equal(t, 11, profile.Count)
+	assert.Len(t, profile.Opcodes, 11)
+	assert.Equal(t, 1, profile.Opcodes["OP_SHA256"].Count)
+	assert.Equal(t, 1, profile.Opcodes["OP_DATA_4"].Count)
+	assert.Equal(t, 4, profile.BytesHashed)
+	assert.Equal(t, 0, profile.SigChecks)
+	assert.Equal(t, 3, profile.MaxStackDepth)
+	assert.Equal(t, 1, profile.MaxNumberLength)
+	assert.Equal(t, 2, profile.NumberBytes)
+
+	var total int64
+	for _, op := range profile.Opcodes {
+		total += int64(op.Duration)
+	}
+	assert.Equal(t, int64(profile.Duration), total)
+
+	p.Reset()
+	assert.Zero(t, p.Profile().Count)
+	assert.Empty(t, p.Profile().Opcodes)
+}
+
+func TestProfiler_SkippedBranch(t *testing.T) {
+	t.Parallel()
+
+	lockingScript, err := bscript.ParseASM("OP_IF OP_SHA256 OP_ELSE OP_1ADD OP_ENDIF OP_2 OP_NUMEQUAL")
+	assert.NoError(t, err)
+	unlockingScript, err := bscript.ParseASM("OP_1 OP_0")
+	assert.NoError(t, err)
+
+	p := interpreter.NewProfiler(interpreter.Budget{})
+	assert.NoError(t, interpreter.NewEngine().Execute(
+		interpreter.WithScripts(lockingScript, unlockingScript),
+		interpreter.WithAfterGenesis(),
+		interpreter.WithProfiler(p),
+	))
+
+	profile := p.Profile()
+	assert.Equal(t, 8, profile.Count)
+	assert.NotContains(t, profile.Opcodes, "OP_SHA256")
+	assert.Equal(t, 1, profile.Opcodes["OP_ELSE"].Count)
+	assert.Zero(t, profile.BytesHashed)
+}
+
+func TestProfiler_Budget(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		unlockingASM string
+		budget       interpreter.Budget
+		expErr       string
+	}{
+		"within budget": {
+			unlockingASM: "OP_1 OP_1 aabbccdd",
+			budget:       interpreter.Budget{MaxOpcodes: 8, MaxStackDepth: 3, MaxBytesHashed: 4, MaxNumberLength: 1},
+		},
+		"too many opcodes": {
+			unlockingASM: "OP_1 OP_1 aabbccdd",
+			budget:       interpreter.Budget{MaxOpcodes: 7},
+			expErr:       "budget exceeded: opcodes 8 > 7",
+		},
+		"stack too deep": {
+			unlockingASM: "OP_1 OP_1 aabbccdd",
+			budget:       interpreter.Budget{MaxStackDepth: 2},
+			expErr:       "budget exceeded: stack depth 3 > 2",
+		},
+		"too many bytes hashed": {
+			unlockingASM: "OP_1 OP_1 aabbccdd",
+			budget:       interpreter.Budget{MaxBytesHashed: 3},
+			expErr:       "budget exceeded: bytes hashed 4 > 3",
+		},
+		"number too long": {
+			unlockingASM: "OP_1 aabbcc00 aabbccdd",
+			budget:       interpreter.Budget{MaxNumberLength: 1},
+			expErr:       "budget exceeded: number length 4 > 1",
+		},
+		"number too long to decode": {
+			unlockingASM: "OP_1 aabbccddeeff00112233 aabbccdd",
+			budget:       interpreter.Budget{MaxNumberLength: 4},
+			expErr:       "budget exceeded: number length 10 > 4",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			lockingScript, err := bscript.ParseASM("OP_SHA256 OP_DROP OP_1ADD OP_2 OP_NUMEQUAL")
+			assert.NoError(t, err)
+			unlockingScript, err := bscript.ParseASM(test.unlockingASM)
+			assert.NoError(t, err)
+
+			err = interpreter.NewEngine().Execute(
+				interpreter.WithScripts(lockingScript, unlockingScript),
+				interpreter.WithAfterGenesis(),
+				interpreter.WithProfiler(interpreter.NewProfiler(test.budget)),
+			)
+			if test.expErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, errs.IsErrorCode(err, errs.ErrBudgetExceeded), err)
+			assert.EqualError(t, err, test.expErr)
+		})
+	}
+}
+
+func TestProfiler_BudgetCheckedBeforeWork(t *testing.T) {
+	t.Parallel()
+
+	// OP_SHA256 exceeds the budget before it hashes the 1MiB element.
+	lockingScript, err := bscript.ParseASM("OP_SHA256 OP_DROP OP_1")
+	assert.NoError(t, err)
+	unlockingScript := &bscript.Script{}
+	assert.NoError(t, unlockingScript.AppendPushData(make([]byte, 1<<20)))
+
+	p := interpreter.NewProfiler(interpreter.Budget{MaxBytesHashed: 1 << 10})
+	err = interpreter.NewEngine().Execute(
+		interpreter.WithScripts(lockingScript, unlockingScript),
+		interpreter.WithAfterGenesis(),
+		interpreter.WithProfiler(p),
+	)
+	assert.True(t, errs.IsErrorCode(err, errs.ErrBudgetExceeded), err)
+	assert.EqualError(t, err, "budget exceeded: bytes hashed 1048576 > 1024")
+}
+
+func TestProfiler_VerifyTx(t *testing.T) {
+	t.Parallel()
+
+	tx := signedTestTx(t, 4)
+
+	p := interpreter.NewProfiler(interpreter.Budget{})
+	report, err := interpreter.VerifyTx(context.Background(), tx, interpreter.WithExecutionOptions(
+		interpreter.WithForkID(),
+		interpreter.WithAfterGenesis(),
+		interpreter.WithProfiler(p),
+	))
+	assert.NoError(t, err)
+	assert.True(t, report.Valid())
+
+	profile := p.Profile()
+	assert.Equal(t, 4, profile.SigChecks)
+	assert.Equal(t, 4, profile.Opcodes["OP_CHECKSIG"].Count)
+	assert.Equal(t, 4*33, profile.BytesHashed)
+
+	// The budget is shared by every input.
+	p = interpreter.NewProfiler(interpreter.Budget{MaxSigChecks: 2})
+	report, err = interpreter.VerifyTx(context.Background(), tx,
+		interpreter.WithConcurrency(1),
+		interpreter.WithExecutionOptions(
+			interpreter.WithForkID(),
+			interpreter.WithAfterGenesis(),
+			interpreter.WithProfiler(p),
+		))
+	assert.NoError(t, err)
+	assert.False(t, report.Valid())
+	assert.NoError(t, report.Inputs[0])
+	assert.NoError(t, report.Inputs[1])
+	assert.True(t, errs.IsErrorCode(report.Inputs[2], errs.ErrBudgetExceeded), report.Inputs[2])
+}
@@ -4,6 +4,7 @@
 package interpreter
 
 import (
+	"bytes"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
@@ -466,6 +467,63 @@ func TestScripts(t *testing.T) {
 	}
 }
 
+// TestScriptsASM ensures bscript.ParseASM reads every script in script_tests.json
+// as the short form parser does, and that bscript.ASMModeRoundTrip renders each of
+// them as ASM which parses back to the same script.
+func TestScriptsASM(t *testing.T) {
+	file, err := ioutil.ReadFile("data/script_tests.json")
+	if err != nil {
+		t.Fatalf("TestScriptsASM: %v\n", err)
+	}
+
+	var tests [][]interface{}
+	if err = json.Unmarshal(file, &tests); err != nil {
+		t.Fatalf("TestScriptsASM couldn't Unmarshal: %v", err)
+	}
+
+	for i, test := range tests {
+		if len(test) == 1 {
+			continue
+		}
+		if _, ok := test[0].([]interface{}); ok {
+			test = test[1:]
+		}
+
+		for _, field := range test[:2] {
+			str, ok := field.(string)
+			if !ok {
+				t.Errorf("test #%d: script is not a string", i)
+				continue
+			}
+			exp, err := parseShortForm(str)
+			if err != nil {
+				t.Errorf("test #%d: can't parse script %q: %v", i, str, err)
+				continue
+			}
+
+			s, err := bscript.ParseASM(str)
+			if err != nil {
+				t.Errorf("test #%d: ParseASM(%q): %v", i, str, err)
+				continue
+			}
+			if !bytes.Equal(*exp, *s) {
+				t.Errorf("test #%d: ParseASM(%q) = %x, want %x", i, str, *s, *exp)
+				continue
+			}
+
+			asm := s.FormatASM(bscript.ASMModeRoundTrip)
+			rt, err := bscript.ParseASM(asm)
+			if err != nil {
+				t.Errorf("test #%d: ParseASM(%q): %v", i, asm, err)
+				continue
+			}
+			if !bytes.Equal(*s, *rt) {
+				t.Errorf("test #%d: %q round trips as %x, want %x", i, asm, *rt, *s)
+			}
+		}
+	}
+}
+
 // testVecF64ToUint32 properly handles conversion of float64s read from the JSON
 // test data to unsigned 32-bit integers.  This is necessary because some of the
 // test data uses -1 as a shortcut to mean max uint32 and direct conversion of a
@@ -0,0 +1,100 @@
+package interpreter
+
+import (
+	"crypto/sha256"
+	"sync"
+
+	"github.com/mvc-labs/mvc-lib-go"
+)
+
+// sigCacheKey identifies a verified signature, being the sha256 of the
+// signature hash, signature and public key it was verified against, each
+// prefixed with its length so that no two combinations share a key.
+type sigCacheKey [sha256.Size]byte
+
+// SigCache is a thread-safe cache of valid signatures, allowing the ECDSA
+// verification of OP_CHECKSIG and OP_CHECKMULTISIG to be skipped for any
+// signature, public key and signature hash combination which has already
+// been verified.
+//
+// This is useful when the same txs are verified more than once, such as
+// when first accepted into a mempool and again when seen in a block. A single
+// SigCache can be shared across many executions by passing it to each of them
+// with `interpreter.WithSigCache`.
+//
+// Only valid signatures are added to the cache. Once the cache is full, a
+// random entry is evicted to make room for each new one.
+type SigCache struct {
+	mu         sync.RWMutex
+	entries    map[sigCacheKey]struct{}
+	maxEntries uint
+}
+
+// NewSigCache creates and returns a new *interpreter.SigCache which will hold
+// at most maxEntries signatures. A maxEntries of 0 disables caching.
+func NewSigCache(maxEntries uint) *SigCache {
+	return &SigCache{
+		entries:    make(map[sigCacheKey]struct{}, maxEntries),
+		maxEntries: maxEntries,
+	}
+}
+
+// Exists returns true if the signature has been verified against the public key
+// and signature hash, and added to the cache.
+func (s *SigCache) Exists(sigHash, sig, pubKey []byte) bool {
+	key := newSigCacheKey(sigHash, sig, pubKey)
+
+	s.mu.RLock()
+	_, ok := s.entries[key]
+	s.mu.RUnlock()
+
+	return ok
+}
+
+// Add a signature which has been verified against the public key and signature
+// hash to the cache.
+func (s *SigCache) Add(sigHash, sig, pubKey []byte) {
+	if s.maxEntries == 0 {
+		return
+	}
+
+	key := newSigCacheKey(sigHash, sig, pubKey)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[key]; ok {
+		return
+	}
+
+	// Map iteration order is random, so removing the first key found evicts
+	// a random entry.
+	if uint(len(s.entries)) >= s.maxEntries {
+		for k := range s.entries {
+			delete(s.entries, k)
+			break
+		}
+	}
+
+	s.entries[key] = struct{}{}
+}
+
+// Len returns the number of signatures held in the cache.
+func (s *SigCache) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.entries)
+}
+
+func newSigCacheKey(sigHash, sig, pubKey []byte) sigCacheKey {
+	h := sha256.New()
+	for _, b := range [][]byte{sigHash, sig, pubKey} {
+		h.Write(bt.VarInt(len(b)).Bytes())
+		h.Write(b)
+	}
+
+	var key sigCacheKey
+	copy(key[:], h.Sum(nil))
+	return key
+}
@@ -0,0 +1,167 @@
+package interpreter_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/bscript/interpreter"
+	"github.com/mvc-labs/mvc-lib-go/bscript/interpreter/errs"
+	"github.com/mvc-labs/mvc-lib-go/sighash"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSigCache(t *testing.T) {
+	t.Parallel()
+
+	t.Run("add and exists", func(t *testing.T) {
+		sc := interpreter.NewSigCache(10)
+		assert.False(t, sc.Exists([]byte{1}, []byte{2}, []byte{3}))
+
+		sc.Add([]byte{1}, []byte{2}, []byte{3})
+		assert.True(t, sc.Exists([]byte{1}, []byte{2}, []byte{3}))
+		assert.False(t, sc.Exists([]byte{1}, []byte{2}, []byte{4}))
+		assert.False(t, sc.Exists([]byte{3}, []byte{2}, []byte{1}))
+
+		sc.Add([]byte{1}, []byte{2}, []byte{3})
+		assert.Equal(t, 1, sc.Len())
+
+		// The same bytes split differently are a different entry.
+		sc.Add([]byte{1}, []byte{2, 3}, []byte{4})
+		assert.False(t, sc.Exists([]byte{1}, []byte{2}, []byte{3, 4}))
+		assert.False(t, sc.Exists([]byte{1, 2}, []byte{3}, []byte{4}))
+	})
+
+	t.Run("evicts once full", func(t *testing.T) {
+		sc := interpreter.NewSigCache(3)
+		for i := byte(0); i < 10; i++ {
+			sc.Add([]byte{i}, []byte{i}, []byte{i})
+		}
+		assert.Equal(t, 3, sc.Len())
+		assert.True(t, sc.Exists([]byte{9}, []byte{9}, []byte{9}))
+	})
+
+	t.Run("zero max entries disables caching", func(t *testing.T) {
+		sc := interpreter.NewSigCache(0)
+		sc.Add([]byte{1}, []byte{2}, []byte{3})
+		assert.False(t, sc.Exists([]byte{1}, []byte{2}, []byte{3}))
+		assert.Equal(t, 0, sc.Len())
+	})
+
+	t.Run("concurrent use", func(t *testing.T) {
+		sc := interpreter.NewSigCache(50)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i byte) {
+				defer wg.Done()
+				for j := byte(0); j < 100; j++ {
+					sc.Add([]byte{i}, []byte{j}, nil)
+					sc.Exists([]byte{i}, []byte{j}, nil)
+				}
+			}(byte(i))
+		}
+		wg.Wait()
+
+		assert.Equal(t, 50, sc.Len())
+	})
+}
+
+func TestEngine_Execute_WithSigCache(t *testing.T) {
+	t.Parallel()
+
+	tx := signedTestTx(t, 3)
+	sc := interpreter.NewSigCache(100)
+
+	report, err := interpreter.VerifyTx(context.Background(), tx,
+		interpreter.WithExecutionOptions(
+			interpreter.WithForkID(), interpreter.WithAfterGenesis(), interpreter.WithSigCache(sc),
+		),
+	)
+	assert.NoError(t, err)
+	assert.True(t, report.Valid())
+	assert.Equal(t, 3, sc.Len())
+
+	// Re-verifying hits the cache rather than adding to it.
+	report, err = interpreter.VerifyTx(context.Background(), tx,
+		interpreter.WithExecutionOptions(
+			interpreter.WithForkID(), interpreter.WithAfterGenesis(), interpreter.WithSigCache(sc),
+		),
+	)
+	assert.NoError(t, err)
+	assert.True(t, report.Valid())
+	assert.Equal(t, 3, sc.Len())
+
+	// A tampered tx produces a different signature hash, so misses the cache.
+	tx.Outputs[0].Satoshis--
+	err = interpreter.NewEngine().Execute(
+		interpreter.WithTx(tx, 0, &bt.Output{
+			Satoshis:      tx.Inputs[0].PreviousTxSatoshis,
+			LockingScript: tx.Inputs[0].PreviousTxScript,
+		}),
+		interpreter.WithForkID(),
+		interpreter.WithAfterGenesis(),
+		interpreter.WithSigCache(sc),
+	)
+	assert.True(t, errs.IsErrorCode(err, errs.ErrEvalFalse))
+	assert.Equal(t, 3, sc.Len())
+}
+
+func TestEngine_Execute_WithSigHashes(t *testing.T) {
+	t.Parallel()
+
+	tx := signedTestTx(t, 3)
+
+	t.Run("matches calculated signature hashes", func(t *testing.T) {
+		sh := bt.NewSigHashes(tx)
+		for i := range tx.Inputs {
+			for _, shf := range []sighash.Flag{
+				sighash.AllForkID,
+				sighash.SingleForkID,
+				sighash.NoneForkID,
+				sighash.AllForkID | sighash.AnyOneCanPay,
+				sighash.All,
+			} {
+				exp, err := tx.CalcInputSignatureHash(uint32(i), shf)
+				assert.NoError(t, err)
+
+				hash, err := tx.CalcInputSignatureHashWithSigHashes(uint32(i), shf, sh)
+				assert.NoError(t, err)
+				assert.Equal(t, exp, hash)
+			}
+		}
+	})
+
+	t.Run("shared across inputs", func(t *testing.T) {
+		sh := bt.NewSigHashes(tx)
+		for i, in := range tx.Inputs {
+			assert.NoError(t, interpreter.NewEngine().Execute(
+				interpreter.WithTx(tx, i, &bt.Output{
+					Satoshis:      in.PreviousTxSatoshis,
+					LockingScript: in.PreviousTxScript,
+				}),
+				interpreter.WithForkID(),
+				interpreter.WithAfterGenesis(),
+				interpreter.WithSigHashes(sh),
+			))
+		}
+	})
+
+	t.Run("mismatched sig hashes fail", func(t *testing.T) {
+		other := signedTestTx(t, 3)
+		other.Outputs[0].Satoshis--
+
+		err := interpreter.NewEngine().Execute(
+			interpreter.WithTx(tx, 0, &bt.Output{
+				Satoshis:      tx.Inputs[0].PreviousTxSatoshis,
+				LockingScript: tx.Inputs[0].PreviousTxScript,
+			}),
+			interpreter.WithForkID(),
+			interpreter.WithAfterGenesis(),
+			interpreter.WithSigHashes(bt.NewSigHashes(other)),
+		)
+		assert.True(t, errs.IsErrorCode(err, errs.ErrEvalFalse))
+	})
+}
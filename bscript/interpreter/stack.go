@@ -42,12 +42,13 @@ type stack struct {
 	verifyMinimalData bool
 	debug             Debugger
 	sh                StateHandler
+	meter             *meter
 }
 
-func newStack(cfg config, verifyMinimalData bool) stack {
+func newStack(limits *Limits, afterGenesis, verifyMinimalData bool) stack {
 	return stack{
-		maxNumLength:      cfg.MaxScriptNumberLength(),
-		afterGenesis:      cfg.AfterGenesis(),
+		maxNumLength:      limits.MaxScriptNumberLength,
+		afterGenesis:      afterGenesis,
 		verifyMinimalData: verifyMinimalData,
 		debug:             &nopDebugger{},
 		sh:                &nopStateHandler{},
@@ -108,6 +109,9 @@ func (s *stack) PopInt() (*scriptNumber, error) {
 		return nil, err
 	}
 
+	if err = s.meter.number(len(so)); err != nil {
+		return nil, err
+	}
 	return makeScriptNumber(so, s.maxNumLength, s.verifyMinimalData, s.afterGenesis)
 }
 
@@ -143,6 +147,9 @@ func (s *stack) PeekInt(idx int32) (*scriptNumber, error) {
 		return nil, err
 	}
 
+	if err = s.meter.number(len(so)); err != nil {
+		return nil, err
+	}
 	return makeScriptNumber(so, s.maxNumLength, s.verifyMinimalData, s.afterGenesis)
 }
 
@@ -901,7 +901,7 @@ func TestStack(t *testing.T) {
 
 	for _, test := range tests {
 		// Setup the initial stack state and perform the test operation.
-		s := newStack(&beforeGenesisConfig{}, false)
+		s := newStack(NewBeforeGenesisLimits(), false, false)
 		for i := range test.before {
 			s.PushByteArray(test.before[i])
 		}
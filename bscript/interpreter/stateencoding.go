@@ -0,0 +1,335 @@
+package interpreter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+	"github.com/mvc-labs/mvc-lib-go/bscript/interpreter/errs"
+	"github.com/mvc-labs/mvc-lib-go/bscript/interpreter/scriptflag"
+)
+
+// stateEncodingVersion is the first byte of a binary encoded State, incremented
+// whenever the encoding changes.
+const stateEncodingVersion = 1
+
+// Bits of the flags byte of a binary encoded State.
+const (
+	stateBitFinished = 1 << iota
+	stateBitAfterGenesis
+	stateBitEarlyReturn
+)
+
+// stateJSON is the JSON encoding of a State, with stack items and scripts as hex.
+type stateJSON struct {
+	DataStack            []string         `json:"dataStack"`
+	AltStack             []string         `json:"altStack"`
+	ElseStack            []string         `json:"elseStack"`
+	CondStack            []int            `json:"condStack"`
+	SavedFirstStack      []string         `json:"savedFirstStack"`
+	Scripts              []string         `json:"scripts"`
+	ScriptIdx            int              `json:"scriptIdx"`
+	OpcodeIdx            int              `json:"opcodeIdx"`
+	LastCodeSeparatorIdx int              `json:"lastCodeSeparatorIdx"`
+	NumOps               int              `json:"numOps"`
+	Flags                scriptflag.Flag  `json:"flags"`
+	IsFinished           bool             `json:"isFinished"`
+	Genesis              stateGenesisJSON `json:"genesis"`
+}
+
+type stateGenesisJSON struct {
+	AfterGenesis bool `json:"afterGenesis"`
+	EarlyReturn  bool `json:"earlyReturn"`
+}
+
+// MarshalJSON encodes the state as JSON, with stack items and scripts as hex, so
+// that it can be stored or sent elsewhere and restored via UnmarshalJSON.
+func (s *State) MarshalJSON() ([]byte, error) {
+	scripts, err := unparseScripts(s.Scripts)
+	if err != nil {
+		return nil, err
+	}
+
+	ss := make([]string, len(scripts))
+	for i, script := range scripts {
+		ss[i] = hex.EncodeToString(script)
+	}
+
+	return json.Marshal(&stateJSON{
+		DataStack:            hexStack(s.DataStack),
+		AltStack:             hexStack(s.AltStack),
+		ElseStack:            hexStack(s.ElseStack),
+		CondStack:            nonNilInts(s.CondStack),
+		SavedFirstStack:      hexStack(s.SavedFirstStack),
+		Scripts:              ss,
+		ScriptIdx:            s.ScriptIdx,
+		OpcodeIdx:            s.OpcodeIdx,
+		LastCodeSeparatorIdx: s.LastCodeSeparatorIdx,
+		NumOps:               s.NumOps,
+		Flags:                s.Flags,
+		IsFinished:           s.IsFinished,
+		Genesis: stateGenesisJSON{
+			AfterGenesis: s.Genesis.AfterGenesis,
+			EarlyReturn:  s.Genesis.EarlyReturn,
+		},
+	})
+}
+
+// UnmarshalJSON decodes a state encoded by MarshalJSON.
+func (s *State) UnmarshalJSON(b []byte) error {
+	var j stateJSON
+	if err := json.Unmarshal(b, &j); err != nil {
+		return err
+	}
+
+	st := State{
+		CondStack:            nonNilInts(j.CondStack),
+		Scripts:              make([]ParsedScript, len(j.Scripts)),
+		ScriptIdx:            j.ScriptIdx,
+		OpcodeIdx:            j.OpcodeIdx,
+		LastCodeSeparatorIdx: j.LastCodeSeparatorIdx,
+		NumOps:               j.NumOps,
+		Flags:                j.Flags,
+		IsFinished:           j.IsFinished,
+	}
+	st.Genesis.AfterGenesis = j.Genesis.AfterGenesis
+	st.Genesis.EarlyReturn = j.Genesis.EarlyReturn
+
+	var err error
+	for _, f := range []struct {
+		stack *[][]byte
+		hex   []string
+	}{
+		{&st.DataStack, j.DataStack},
+		{&st.AltStack, j.AltStack},
+		{&st.ElseStack, j.ElseStack},
+		{&st.SavedFirstStack, j.SavedFirstStack},
+	} {
+		if *f.stack, err = unhexStack(f.hex); err != nil {
+			return err
+		}
+	}
+	for i, script := range j.Scripts {
+		b, err := hex.DecodeString(script)
+		if err != nil {
+			return errs.NewError(errs.ErrInvalidParams, "invalid script %d: %s", i, err)
+		}
+		if st.Scripts[i], err = parseScript(b); err != nil {
+			return err
+		}
+	}
+
+	*s = st
+	return nil
+}
+
+// MarshalBinary encodes the state in a compact binary form, which is restored via
+// UnmarshalBinary. Its layout is a version byte, the flags, as 4 little endian
+// bytes, a byte of the boolean fields, then the indexes, counts and lengths of
+// the remaining fields as VarInts.
+func (s *State) MarshalBinary() ([]byte, error) {
+	scripts, err := unparseScripts(s.Scripts)
+	if err != nil {
+		return nil, err
+	}
+
+	var bits byte
+	if s.IsFinished {
+		bits |= stateBitFinished
+	}
+	if s.Genesis.AfterGenesis {
+		bits |= stateBitAfterGenesis
+	}
+	if s.Genesis.EarlyReturn {
+		bits |= stateBitEarlyReturn
+	}
+
+	buf := []byte{stateEncodingVersion}
+	buf = append(buf, bt.LittleEndianBytes(uint32(s.Flags), 4)...)
+	buf = append(buf, bits)
+
+	for _, n := range []int{s.ScriptIdx, s.OpcodeIdx, s.LastCodeSeparatorIdx, s.NumOps} {
+		if n < 0 {
+			return nil, errs.NewError(errs.ErrInvalidParams, "negative state index %d", n)
+		}
+		buf = append(buf, bt.VarInt(n).Bytes()...)
+	}
+
+	buf = append(buf, bt.VarInt(len(s.CondStack)).Bytes()...)
+	for _, c := range s.CondStack {
+		if c < 0 {
+			return nil, errs.NewError(errs.ErrInvalidParams, "negative condition %d", c)
+		}
+		buf = append(buf, bt.VarInt(c).Bytes()...)
+	}
+
+	for _, stack := range [][][]byte{s.DataStack, s.AltStack, s.ElseStack, s.SavedFirstStack, scripts} {
+		buf = append(buf, bt.VarInt(len(stack)).Bytes()...)
+		for _, item := range stack {
+			buf = append(buf, bt.VarInt(len(item)).Bytes()...)
+			buf = append(buf, item...)
+		}
+	}
+
+	return buf, nil
+}
+
+// UnmarshalBinary decodes a state encoded by MarshalBinary.
+func (s *State) UnmarshalBinary(b []byte) error {
+	r := bytes.NewReader(b)
+	st, err := readState(r)
+	if err != nil {
+		return errs.NewError(errs.ErrInvalidParams, "invalid state encoding: %s", err)
+	}
+	if r.Len() > 0 {
+		return errs.NewError(errs.ErrInvalidParams, "invalid state encoding: %d bytes of trailing data", r.Len())
+	}
+
+	*s = *st
+	return nil
+}
+
+func readState(r *bytes.Reader) (*State, error) {
+	header := make([]byte, 6)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if header[0] != stateEncodingVersion {
+		return nil, errs.NewError(errs.ErrInvalidParams, "unknown version %d", header[0])
+	}
+
+	st := &State{
+		Flags:      scriptflag.Flag(binary.LittleEndian.Uint32(header[1:5])),
+		IsFinished: header[5]&stateBitFinished != 0,
+	}
+	st.Genesis.AfterGenesis = header[5]&stateBitAfterGenesis != 0
+	st.Genesis.EarlyReturn = header[5]&stateBitEarlyReturn != 0
+
+	for _, n := range []*int{&st.ScriptIdx, &st.OpcodeIdx, &st.LastCodeSeparatorIdx, &st.NumOps} {
+		v, err := readInt(r)
+		if err != nil {
+			return nil, err
+		}
+		*n = v
+	}
+
+	n, err := readInt(r)
+	if err != nil {
+		return nil, err
+	}
+	if n > r.Len() {
+		return nil, io.ErrUnexpectedEOF
+	}
+	st.CondStack = make([]int, n)
+	for i := range st.CondStack {
+		if st.CondStack[i], err = readInt(r); err != nil {
+			return nil, err
+		}
+	}
+
+	var scripts [][]byte
+	for _, stack := range []*[][]byte{&st.DataStack, &st.AltStack, &st.ElseStack, &st.SavedFirstStack, &scripts} {
+		if *stack, err = readStack(r); err != nil {
+			return nil, err
+		}
+	}
+
+	st.Scripts = make([]ParsedScript, len(scripts))
+	for i, script := range scripts {
+		if st.Scripts[i], err = parseScript(script); err != nil {
+			return nil, err
+		}
+	}
+
+	return st, nil
+}
+
+func readStack(r *bytes.Reader) ([][]byte, error) {
+	n, err := readInt(r)
+	if err != nil {
+		return nil, err
+	}
+	if n > r.Len() {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	stack := make([][]byte, n)
+	for i := range stack {
+		l, err := readInt(r)
+		if err != nil {
+			return nil, err
+		}
+		if l > r.Len() {
+			return nil, io.ErrUnexpectedEOF
+		}
+		stack[i] = make([]byte, l)
+		if _, err = io.ReadFull(r, stack[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return stack, nil
+}
+
+// readInt reads a VarInt which fits in an int.
+func readInt(r io.Reader) (int, error) {
+	var v bt.VarInt
+	if _, err := v.ReadFrom(r); err != nil {
+		return 0, err
+	}
+	if uint64(v) > uint64(^uint(0)>>1) {
+		return 0, errs.NewError(errs.ErrInvalidParams, "value %d out of range", uint64(v))
+	}
+
+	return int(v), nil
+}
+
+func unparseScripts(pp []ParsedScript) ([][]byte, error) {
+	scripts := make([][]byte, len(pp))
+	for i, ps := range pp {
+		s, err := (&DefaultOpcodeParser{}).Unparse(ps)
+		if err != nil {
+			return nil, err
+		}
+		scripts[i] = *s
+	}
+
+	return scripts, nil
+}
+
+func parseScript(b []byte) (ParsedScript, error) {
+	return (&DefaultOpcodeParser{}).Parse(bscript.NewFromBytes(b))
+}
+
+func hexStack(stack [][]byte) []string {
+	ss := make([]string, len(stack))
+	for i, item := range stack {
+		ss[i] = hex.EncodeToString(item)
+	}
+
+	return ss
+}
+
+func unhexStack(ss []string) ([][]byte, error) {
+	stack := make([][]byte, len(ss))
+	for i, s := range ss {
+		b, err := hex.DecodeString(s)
+		if err != nil {
+			return nil, errs.NewError(errs.ErrInvalidParams, "invalid stack item %q: %s", s, err)
+		}
+		stack[i] = b
+	}
+
+	return stack, nil
+}
+
+func nonNilInts(ii []int) []int {
+	if ii == nil {
+		return []int{}
+	}
+	return ii
+}
@@ -0,0 +1,175 @@
+package interpreter
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+	"github.com/mvc-labs/mvc-lib-go/bscript/interpreter/errs"
+	"github.com/mvc-labs/mvc-lib-go/bscript/interpreter/scriptflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestState(t *testing.T) *State {
+	var scripts []ParsedScript
+	for _, asm := range []string{"OP_1 OP_2", "OP_IF OP_3 OP_ELSE 68656c6c6f OP_ENDIF OP_ADD"} {
+		s, err := bscript.NewFromASM(asm)
+		assert.NoError(t, err)
+		ps, err := (&DefaultOpcodeParser{}).Parse(s)
+		assert.NoError(t, err)
+		scripts = append(scripts, ps)
+	}
+
+	state := &State{
+		DataStack:            [][]byte{{0x01}, {}, {0x02, 0x03}},
+		AltStack:             [][]byte{{0xff}},
+		ElseStack:            [][]byte{{}},
+		CondStack:            []int{1},
+		SavedFirstStack:      [][]byte{},
+		Scripts:              scripts,
+		ScriptIdx:            1,
+		OpcodeIdx:            2,
+		LastCodeSeparatorIdx: 0,
+		NumOps:               3,
+		Flags:                scriptflag.UTXOAfterGenesis | scriptflag.EnableSighashForkID,
+	}
+	state.Genesis.AfterGenesis = true
+
+	return state
+}
+
+// assertStatesEqual compares states field by field, as parsed opcodes hold funcs
+// which cannot be compared.
+func assertStatesEqual(t *testing.T, exp, act *State) {
+	assert.Equal(t, exp.DataStack, act.DataStack)
+	assert.Equal(t, exp.AltStack, act.AltStack)
+	assert.Equal(t, exp.ElseStack, act.ElseStack)
+	assert.Equal(t, exp.CondStack, act.CondStack)
+	assert.Equal(t, exp.SavedFirstStack, act.SavedFirstStack)
+	assert.Equal(t, len(exp.Scripts), len(act.Scripts))
+	for i := range exp.Scripts {
+		assert.Equal(t, len(exp.Scripts[i]), len(act.Scripts[i]))
+		for j := range exp.Scripts[i] {
+			assert.Equal(t, exp.Scripts[i][j].Value(), act.Scripts[i][j].Value())
+			assert.Equal(t, exp.Scripts[i][j].Data, act.Scripts[i][j].Data)
+		}
+	}
+	assert.Equal(t, exp.ScriptIdx, act.ScriptIdx)
+	assert.Equal(t, exp.OpcodeIdx, act.OpcodeIdx)
+	assert.Equal(t, exp.LastCodeSeparatorIdx, act.LastCodeSeparatorIdx)
+	assert.Equal(t, exp.NumOps, act.NumOps)
+	assert.Equal(t, exp.Flags, act.Flags)
+	assert.Equal(t, exp.IsFinished, act.IsFinished)
+	assert.Equal(t, exp.Genesis, act.Genesis)
+}
+
+func TestState_MarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	state := newTestState(t)
+	b, err := json.Marshal(state)
+	assert.NoError(t, err)
+	assert.Contains(t, string(b), `"dataStack":["01","","0203"]`)
+	assert.Contains(t, string(b), `"scripts":["5152","63536705`)
+
+	var decoded State
+	assert.NoError(t, json.Unmarshal(b, &decoded))
+	assertStatesEqual(t, state, &decoded)
+	assert.Equal(t, "OP_ELSE", decoded.Opcode().Name())
+
+	reencoded, err := json.Marshal(&decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, string(b), string(reencoded))
+}
+
+func TestState_UnmarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		json string
+	}{
+		"invalid stack item": {
+			json: `{"dataStack":["zz"]}`,
+		},
+		"invalid script hex": {
+			json: `{"scripts":["zz"]}`,
+		},
+		"truncated script": {
+			json: `{"scripts":["4c05"]}`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var state State
+			assert.Error(t, json.Unmarshal([]byte(test.json), &state))
+		})
+	}
+}
+
+func TestState_MarshalBinary(t *testing.T) {
+	t.Parallel()
+
+	state := newTestState(t)
+	b, err := state.MarshalBinary()
+	assert.NoError(t, err)
+
+	var decoded State
+	assert.NoError(t, decoded.UnmarshalBinary(b))
+	assertStatesEqual(t, state, &decoded)
+
+	reencoded, err := decoded.MarshalBinary()
+	assert.NoError(t, err)
+	assert.Equal(t, b, reencoded)
+
+	t.Run("empty state", func(t *testing.T) {
+		b, err := (&State{}).MarshalBinary()
+		assert.NoError(t, err)
+		assert.Len(t, b, 16)
+
+		var decoded State
+		assert.NoError(t, decoded.UnmarshalBinary(b))
+		assert.Empty(t, decoded.DataStack)
+		assert.Empty(t, decoded.Scripts)
+	})
+
+	t.Run("negative index", func(t *testing.T) {
+		_, err := (&State{OpcodeIdx: -1}).MarshalBinary()
+		assert.True(t, errs.IsErrorCode(err, errs.ErrInvalidParams))
+	})
+}
+
+func TestState_UnmarshalBinary(t *testing.T) {
+	t.Parallel()
+
+	b, err := newTestState(t).MarshalBinary()
+	assert.NoError(t, err)
+
+	tests := map[string]struct {
+		b []byte
+	}{
+		"empty": {
+			b: []byte{},
+		},
+		"unknown version": {
+			b: append([]byte{0x02}, b[1:]...),
+		},
+		"truncated": {
+			b: b[:len(b)-1],
+		},
+		"trailing data": {
+			b: append(append([]byte{}, b...), 0x00),
+		},
+		"stack count past the end": {
+			b: append(append([]byte{}, b[:6]...), 0x00, 0x00, 0x00, 0x00, 0x00, 0xfd, 0xff, 0xff),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var state State
+			err := state.UnmarshalBinary(test.b)
+			assert.True(t, errs.IsErrorCode(err, errs.ErrInvalidParams), err)
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package interpreter
 
 import (
+	"context"
+	"errors"
 	"math/big"
+	"time"
 
 	"github.com/mvc-labs/mvc-lib-go/keys/bec"
 	"github.com/mvc-labs/mvc-lib-go"
@@ -20,7 +23,12 @@ type thread struct {
 
 	elseStack boolStack
 
-	cfg config
+	limits *Limits
+
+	meter *meter
+
+	ctx  context.Context
+	done <-chan struct{}
 
 	debug Debugger
 	state StateHandler
@@ -38,6 +46,9 @@ type thread struct {
 	inputIdx   int
 	prevOutput *bt.Output
 
+	sigCache  *SigCache
+	sigHashes *bt.SigHashes
+
 	numOps int
 
 	flags scriptflag.Flag
@@ -52,7 +63,7 @@ func createThread(opts *execOpts) (*thread, error) {
 		scriptParser: &DefaultOpcodeParser{
 			ErrorOnCheckSig: opts.tx == nil || opts.previousTxOut == nil,
 		},
-		cfg: &beforeGenesisConfig{},
+		limits: NewBeforeGenesisLimits(),
 	}
 
 	if err := th.apply(opts); err != nil {
@@ -78,6 +89,11 @@ type execOpts struct {
 	flags           scriptflag.Flag
 	debugger        Debugger
 	state           *State
+	sigCache        *SigCache
+	sigHashes       *bt.SigHashes
+	limits          *Limits
+	profiler        *Profiler
+	ctx             context.Context
 }
 
 func (o execOpts) validate() error {
@@ -146,13 +162,40 @@ func (t *thread) isBranchExecuting() bool {
 	return len(t.condStack) == 0 || t.condStack[len(t.condStack)-1] == opCondTrue
 }
 
-// executeOpcode performs execution on the passed opcode. It takes into account
+// executeOpcode performs execution on the passed opcode, recording its cost if
+// a profiler is configured.
+func (t *thread) executeOpcode(pop ParsedOpcode) error {
+	if t.meter == nil {
+		return t.execOpcode(pop)
+	}
+
+	// Opcodes in non-executing branches are skipped, so aren't recorded, but
+	// conditionals are always evaluated to track nesting.
+	executed := pop.IsConditional() || (t.isBranchExecuting() && t.shouldExec(pop))
+
+	t.meter.start = time.Now()
+	err := t.execOpcode(pop)
+	if !executed {
+		return err
+	}
+
+	stackDepth := int(t.dstack.Depth() + t.astack.Depth())
+	if perr := t.meter.profiler.recordOpcode(pop, time.Since(t.meter.start), stackDepth); perr != nil {
+		if err == nil || errs.IsErrorCode(err, errs.ErrOK) {
+			return perr
+		}
+	}
+
+	return err
+}
+
+// execOpcode performs execution on the passed opcode. It takes into account
 // whether it is hidden by conditionals, but some rules still must be
 // tested in this case.
-func (t *thread) executeOpcode(pop ParsedOpcode) error {
-	if len(pop.Data) > t.cfg.MaxScriptElementSize() {
+func (t *thread) execOpcode(pop ParsedOpcode) error {
+	if len(pop.Data) > t.limits.MaxScriptElementSize {
 		return errs.NewError(errs.ErrElementTooBig,
-			"element size %d exceeds max allowed size %d", len(pop.Data), t.cfg.MaxScriptElementSize())
+			"element size %d exceeds max allowed size %d", len(pop.Data), t.limits.MaxScriptElementSize)
 	}
 
 	exec := t.shouldExec(pop)
@@ -170,15 +213,15 @@ func (t *thread) executeOpcode(pop ParsedOpcode) error {
 	// Note that this includes OP_RESERVED which counts as a push operation.
 	if pop.op.val > bscript.Op16 {
 		t.numOps++
-		if t.numOps > t.cfg.MaxOps() {
-			return errs.NewError(errs.ErrTooManyOperations, "exceeded max operation limit of %d", t.cfg.MaxOps())
+		if t.numOps > t.limits.MaxOps {
+			return errs.NewError(errs.ErrTooManyOperations, "exceeded max operation limit of %d", t.limits.MaxOps)
 		}
 
 	}
 
-	if len(pop.Data) > t.cfg.MaxScriptElementSize() {
+	if len(pop.Data) > t.limits.MaxScriptElementSize {
 		return errs.NewError(errs.ErrElementTooBig,
-			"element size %d exceeds max allowed size %d", len(pop.Data), t.cfg.MaxScriptElementSize())
+			"element size %d exceeds max allowed size %d", len(pop.Data), t.limits.MaxScriptElementSize)
 	}
 
 	// Nothing left to do when this is not a conditional opcode, and it is
@@ -204,6 +247,25 @@ func (t *thread) executeOpcode(pop ParsedOpcode) error {
 	return pop.op.exec(&pop, t)
 }
 
+// checkContext returns an ErrTimeout or ErrCanceled if the context of the
+// execution is done, nil otherwise.
+func (t *thread) checkContext() error {
+	select {
+	case <-t.done:
+		return contextError(t.ctx.Err())
+	default:
+		return nil
+	}
+}
+
+// contextError returns the error for an execution stopped by the context error.
+func contextError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return errs.Error{ErrorCode: errs.ErrTimeout, Description: "execution timed out", Err: err}
+	}
+	return errs.Error{ErrorCode: errs.ErrCanceled, Description: "execution canceled", Err: err}
+}
+
 // validPC returns an error if the current script position is valid for
 // execution, nil otherwise.
 func (t *thread) validPC() error {
@@ -261,6 +323,8 @@ func (t *thread) apply(opts *execOpts) error {
 	t.flags = opts.flags
 	t.inputIdx = opts.inputIdx
 	t.prevOutput = opts.previousTxOut
+	t.sigCache = opts.sigCache
+	t.sigHashes = opts.sigHashes
 
 	// The clean stack flag (ScriptVerifyCleanStack) is not allowed without
 	// the pay-to-script-hash (P2SH) evaluation (ScriptBip16).
@@ -278,7 +342,10 @@ func (t *thread) apply(opts *execOpts) error {
 	if t.hasFlag(scriptflag.UTXOAfterGenesis) {
 		t.elseStack = &stack{debug: &nopDebugger{}, sh: &nopStateHandler{}}
 		t.afterGenesis = true
-		t.cfg = &afterGenesisConfig{}
+		t.limits = NewConsensusLimits()
+		if opts.limits != nil {
+			t.limits = opts.limits.withDefaults(t.limits)
+		}
 	}
 
 	uscript := opts.unlockingScript
@@ -296,20 +363,20 @@ func (t *thread) apply(opts *execOpts) error {
 		return errs.NewError(errs.ErrInvalidFlags, "invalid scriptflag combination")
 	}
 
-	if len(*uscript) > t.cfg.MaxScriptSize() {
+	if len(*uscript) > t.limits.MaxScriptSize {
 		return errs.NewError(
 			errs.ErrScriptTooBig,
 			"unlocking script size %d is larger than the max allowed size %d",
 			len(*uscript),
-			t.cfg.MaxScriptSize(),
+			t.limits.MaxScriptSize,
 		)
 	}
-	if len(*lscript) > t.cfg.MaxScriptSize() {
+	if len(*lscript) > t.limits.MaxScriptSize {
 		return errs.NewError(
 			errs.ErrScriptTooBig,
 			"locking script size %d is larger than the max allowed size %d",
 			len(*uscript),
-			t.cfg.MaxScriptSize(),
+			t.limits.MaxScriptSize,
 		)
 	}
 
@@ -348,8 +415,8 @@ func (t *thread) apply(opts *execOpts) error {
 		t.bip16 = true
 	}
 
-	t.dstack = newStack(t.cfg, t.hasFlag(scriptflag.VerifyMinimalData))
-	t.astack = newStack(t.cfg, t.hasFlag(scriptflag.VerifyMinimalData))
+	t.dstack = newStack(t.limits, t.afterGenesis, t.hasFlag(scriptflag.VerifyMinimalData))
+	t.astack = newStack(t.limits, t.afterGenesis, t.hasFlag(scriptflag.VerifyMinimalData))
 
 	if t.tx != nil {
 		t.tx.InputIdx(t.inputIdx).PreviousTxScript = t.prevOutput.LockingScript
@@ -364,8 +431,16 @@ func (t *thread) apply(opts *execOpts) error {
 	t.debug = opts.debugger
 	t.dstack.debug = t.debug
 	t.dstack.sh = t.state
+	t.meter = newMeter(opts.profiler)
+	t.dstack.meter = t.meter
 	t.astack.debug = t.debug
 	t.astack.sh = t.state
+	t.astack.meter = t.meter
+
+	t.ctx = opts.ctx
+	if t.ctx != nil {
+		t.done = t.ctx.Done()
+	}
 
 	if opts.state != nil {
 		t.SetState(opts.state)
@@ -410,6 +485,12 @@ func (t *thread) Step() (bool, error) {
 		return true, err
 	}
 
+	// Stop if the execution has been canceled, checking between each opcode
+	// so that scripts looping over costly operations cannot run on.
+	if err := t.checkContext(); err != nil {
+		return true, err
+	}
+
 	opcode := t.scripts[t.scriptIdx][t.scriptOff]
 
 	t.beforeExecuteOpcode()
@@ -431,9 +512,9 @@ func (t *thread) Step() (bool, error) {
 	// The number of elements in the combination of the data and alt stacks
 	// must not exceed the maximum number of stack elements allowed.
 	combinedStackSize := t.dstack.Depth() + t.astack.Depth()
-	if combinedStackSize > int32(t.cfg.MaxStackSize()) {
+	if int(combinedStackSize) > t.limits.MaxStackSize {
 		return false, errs.NewError(errs.ErrStackOverflow,
-			"combined stack size %d > max allowed %d", combinedStackSize, t.cfg.MaxStackSize())
+			"combined stack size %d > max allowed %d", combinedStackSize, t.limits.MaxStackSize)
 	}
 
 	if t.scriptOff < len(t.scripts[t.scriptIdx]) {
@@ -750,6 +831,51 @@ func getStack(stack *stack) [][]byte {
 	return array
 }
 
+// signatureHash calculates the signature hash of the input being executed, with
+// the provided script as its script code.
+//
+// Rather than cloning the whole tx, only the slice of inputs and the input being
+// executed are copied, leaving the tx itself untouched.
+func (t *thread) signatureHash(scriptCode *bscript.Script, shf sighash.Flag) ([]byte, error) {
+	if t.sigHashes == nil && shf.Has(sighash.ForkID) {
+		t.sigHashes = bt.NewSigHashes(t.tx)
+	}
+
+	in := *t.tx.Inputs[t.inputIdx]
+	in.PreviousTxScript = scriptCode
+
+	txCopy := *t.tx
+	txCopy.Inputs = make([]*bt.Input, len(t.tx.Inputs))
+	copy(txCopy.Inputs, t.tx.Inputs)
+	txCopy.Inputs[t.inputIdx] = &in
+
+	return txCopy.CalcInputSignatureHashWithSigHashes(uint32(t.inputIdx), shf, t.sigHashes)
+}
+
+// verifySignature verifies the signature against the public key and signature hash,
+// consulting the signature cache first if one is configured. Valid signatures are
+// added to the cache. An error is returned if checking the signature would take
+// the profiler over budget.
+func (t *thread) verifySignature(sigHash []byte, signature *bec.Signature, sigBytes []byte,
+	pubKey *bec.PublicKey, pkBytes []byte) (bool, error) {
+	if err := t.meter.sigCheck(); err != nil {
+		return false, err
+	}
+
+	if t.sigCache != nil && t.sigCache.Exists(sigHash, sigBytes, pkBytes) {
+		return true, nil
+	}
+
+	if !signature.Verify(sigHash, pubKey) {
+		return false, nil
+	}
+
+	if t.sigCache != nil {
+		t.sigCache.Add(sigHash, sigBytes, pkBytes)
+	}
+	return true, nil
+}
+
 // setStack sets the stack to the contents of the array where the last item in
 // the array is the top item in the stack.
 func setStack(stack *stack, data [][]byte) {
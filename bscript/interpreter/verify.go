@@ -0,0 +1,185 @@
+package interpreter
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/bscript/interpreter/errs"
+)
+
+// VerifyOptionFunc for setting tx verification options.
+type VerifyOptionFunc func(o *verifyOpts)
+
+type verifyOpts struct {
+	concurrency int
+	execOpts    []ExecutionOptionFunc
+}
+
+// WithConcurrency configure the maximum number of inputs to be verified in parallel.
+// Defaults to the number of CPUs available.
+func WithConcurrency(n int) VerifyOptionFunc {
+	return func(o *verifyOpts) {
+		o.concurrency = n
+	}
+}
+
+// WithExecutionOptions configure the execution options to be applied when executing
+// the scripts of each input, for example `interpreter.WithForkID()`.
+//
+// If not provided, each input is executed with `interpreter.WithForkID()` and
+// `interpreter.WithAfterGenesis()`. A `interpreter.WithSigCache(...)` can be
+// provided here to share a signature cache across the verification of many txs.
+func WithExecutionOptions(oo ...ExecutionOptionFunc) VerifyOptionFunc {
+	return func(o *verifyOpts) {
+		o.execOpts = append(o.execOpts, oo...)
+	}
+}
+
+// VerifyReport details the outcome of verifying a tx.
+type VerifyReport struct {
+	// Inputs holds the outcome of executing the scripts of each input, by input
+	// index. A nil entry means the input is valid.
+	Inputs []error
+
+	// TotalInputSatoshis the sum of the satoshis of the outputs being spent.
+	TotalInputSatoshis uint64
+
+	// TotalOutputSatoshis the sum of the satoshis of the tx outputs.
+	TotalOutputSatoshis uint64
+
+	// ValueErr is set when the tx spends more satoshis than its inputs provide.
+	ValueErr error
+}
+
+// Valid returns true if every input is valid and the value of the tx is conserved.
+func (r *VerifyReport) Valid() bool {
+	return r.Err() == nil
+}
+
+// Err returns the first error found while verifying the tx, or nil if it is valid.
+// Input errors are wrapped with the index of the input they were raised for.
+func (r *VerifyReport) Err() error {
+	for i, err := range r.Inputs {
+		if err != nil {
+			return fmt.Errorf("input %d: %w", i, err)
+		}
+	}
+
+	return r.ValueErr
+}
+
+// Fee returns the fee paid by the tx, or 0 if the outputs exceed the inputs.
+func (r *VerifyReport) Fee() uint64 {
+	if r.TotalInputSatoshis < r.TotalOutputSatoshis {
+		return 0
+	}
+	return r.TotalInputSatoshis - r.TotalOutputSatoshis
+}
+
+// VerifyTx verifies a tx by executing the scripts of every input, in parallel, and
+// checking that the tx does not spend more than its inputs provide.
+//
+// Each input must carry the locking script and satoshis of the output it spends,
+// in `PreviousTxScript` and `PreviousTxSatoshis`. This is the case for txs parsed
+// from the extended format, or built via `tx.From(...)` or `tx.FromUTXOs(...)`.
+//
+// The returned report holds the outcome of every input. An error is only returned
+// if the tx cannot be verified at all, such as it being nil or a coinbase. Should
+// ctx be done before every input is verified, those remaining fail with an
+// errs.ErrTimeout or errs.ErrCanceled, as their execution is stopped.
+//
+// Example usage:
+//
+//	report, err := interpreter.VerifyTx(ctx, tx)
+//	if err != nil {
+//	    return err
+//	}
+//	if !report.Valid() {
+//	    for i, err := range report.Inputs {
+//	        // handle err for input i
+//	    }
+//	}
+func VerifyTx(ctx context.Context, tx *bt.Tx, oo ...VerifyOptionFunc) (*VerifyReport, error) {
+	opts := &verifyOpts{concurrency: runtime.NumCPU()}
+	for _, o := range oo {
+		o(opts)
+	}
+	if len(opts.execOpts) == 0 {
+		opts.execOpts = []ExecutionOptionFunc{WithForkID(), WithAfterGenesis()}
+	}
+	if opts.concurrency < 1 {
+		opts.concurrency = 1
+	}
+
+	if tx == nil {
+		return nil, errs.NewError(errs.ErrInvalidParams, "no tx provided")
+	}
+	if tx.InputCount() == 0 {
+		return nil, errs.NewError(errs.ErrInvalidParams, "tx has no inputs")
+	}
+	if tx.IsCoinbase() {
+		return nil, errs.NewError(errs.ErrInvalidParams, "coinbase txs have no scripts to verify")
+	}
+
+	report := &VerifyReport{
+		Inputs:              make([]error, tx.InputCount()),
+		TotalInputSatoshis:  tx.TotalInputSatoshis(),
+		TotalOutputSatoshis: tx.TotalOutputSatoshis(),
+	}
+	if report.TotalInputSatoshis < report.TotalOutputSatoshis {
+		report.ValueErr = fmt.Errorf(
+			"%w: %d < %d", bt.ErrInsufficientInputs, report.TotalInputSatoshis, report.TotalOutputSatoshis,
+		)
+	}
+
+	// The signature hash midstate is common to every input, so is calculated once
+	// up front rather than by the execution of each input.
+	execOpts := append([]ExecutionOptionFunc{WithSigHashes(bt.NewSigHashes(tx))}, opts.execOpts...)
+
+	workers := opts.concurrency
+	if workers > tx.InputCount() {
+		workers = tx.InputCount()
+	}
+
+	idxs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// Execution writes the previous output onto the input being verified and
+			// reads every input when calculating signature hashes, so each worker
+			// operates on its own copy of the tx.
+			txCopy := tx.Clone()
+			for idx := range idxs {
+				report.Inputs[idx] = verifyInput(ctx, txCopy, idx, execOpts)
+			}
+		}()
+	}
+
+	for i := range tx.Inputs {
+		idxs <- i
+	}
+	close(idxs)
+	wg.Wait()
+
+	return report, nil
+}
+
+func verifyInput(ctx context.Context, tx *bt.Tx, idx int, oo []ExecutionOptionFunc) error {
+	in := tx.Inputs[idx]
+	if in.PreviousTxScript == nil {
+		return errs.NewError(errs.ErrInvalidParams, "input %d has no previous locking script", idx)
+	}
+
+	prevOutput := &bt.Output{
+		Satoshis:      in.PreviousTxSatoshis,
+		LockingScript: in.PreviousTxScript,
+	}
+
+	return NewEngine().Execute(append([]ExecutionOptionFunc{WithTx(tx, idx, prevOutput), WithContext(ctx)}, oo...)...)
+}
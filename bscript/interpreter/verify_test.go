@@ -0,0 +1,134 @@
+package interpreter_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+	"github.com/mvc-labs/mvc-lib-go/bscript/interpreter"
+	"github.com/mvc-labs/mvc-lib-go/bscript/interpreter/errs"
+	"github.com/mvc-labs/mvc-lib-go/keys/wif"
+	"github.com/mvc-labs/mvc-lib-go/unlocker"
+	"github.com/stretchr/testify/assert"
+)
+
+func signedTestTx(t *testing.T, inputs int) *bt.Tx {
+	w, err := wif.DecodeWIF("cNGwGSc7KRrTmdLUZ54fiSXWbhLNDc2Eg5zNucgQxyQCzuQ5YRDq")
+	assert.NoError(t, err)
+
+	lockingScript, err := bscript.NewP2PKHFromPubKeyEC(w.PrivKey.PubKey())
+	assert.NoError(t, err)
+
+	tx := bt.NewTx()
+	for i := 0; i < inputs; i++ {
+		assert.NoError(t, tx.From(
+			"45be95d2f2c64e99518ffbbce03fb15a7758f20ee5eecf0df07938d977add71d",
+			uint32(i),
+			lockingScript.String(),
+			10000,
+		))
+	}
+	assert.NoError(t, tx.PayTo(lockingScript, uint64(inputs)*10000-500))
+	assert.NoError(t, tx.FillAllInputs(context.Background(), &unlocker.Getter{PrivateKey: w.PrivKey}))
+
+	return tx
+}
+
+func TestVerifyTx(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid tx", func(t *testing.T) {
+		tx := signedTestTx(t, 5)
+
+		report, err := interpreter.VerifyTx(context.Background(), tx, interpreter.WithConcurrency(2))
+		assert.NoError(t, err)
+		assert.True(t, report.Valid())
+		assert.NoError(t, report.Err())
+		assert.Len(t, report.Inputs, 5)
+		assert.Equal(t, uint64(500), report.Fee())
+	})
+
+	t.Run("valid extended format tx", func(t *testing.T) {
+		tx, err := bt.NewTxFromBytes(signedTestTx(t, 3).ExtendedBytes())
+		assert.NoError(t, err)
+
+		report, err := interpreter.VerifyTx(context.Background(), tx)
+		assert.NoError(t, err)
+		assert.True(t, report.Valid())
+	})
+
+	t.Run("tampered output invalidates every input", func(t *testing.T) {
+		tx := signedTestTx(t, 3)
+		tx.Outputs[0].Satoshis--
+
+		report, err := interpreter.VerifyTx(context.Background(), tx)
+		assert.NoError(t, err)
+		assert.False(t, report.Valid())
+		for _, err := range report.Inputs {
+			assert.True(t, errs.IsErrorCode(err, errs.ErrEvalFalse))
+		}
+		assert.NoError(t, report.ValueErr)
+	})
+
+	t.Run("tampered unlocking script invalidates a single input", func(t *testing.T) {
+		tx := signedTestTx(t, 3)
+		tx.Inputs[1].UnlockingScript = tx.Inputs[0].UnlockingScript
+		tx.Inputs[1].PreviousTxOutIndex = 7
+
+		report, err := interpreter.VerifyTx(context.Background(), tx)
+		assert.NoError(t, err)
+		assert.Error(t, report.Err())
+		assert.Error(t, report.Inputs[1])
+	})
+
+	t.Run("outputs exceed inputs", func(t *testing.T) {
+		tx := signedTestTx(t, 2)
+		tx.Inputs[0].PreviousTxSatoshis = 1
+
+		report, err := interpreter.VerifyTx(context.Background(), tx)
+		assert.NoError(t, err)
+		assert.ErrorIs(t, report.ValueErr, bt.ErrInsufficientInputs)
+		assert.Equal(t, uint64(0), report.Fee())
+	})
+
+	t.Run("missing previous output", func(t *testing.T) {
+		tx := signedTestTx(t, 2)
+		tx.Inputs[1].PreviousTxScript = nil
+
+		report, err := interpreter.VerifyTx(context.Background(), tx)
+		assert.NoError(t, err)
+		assert.NoError(t, report.Inputs[0])
+		assert.True(t, errs.IsErrorCode(report.Inputs[1], errs.ErrInvalidParams))
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		report, err := interpreter.VerifyTx(ctx, signedTestTx(t, 2))
+		assert.NoError(t, err)
+		assert.ErrorIs(t, report.Err(), context.Canceled)
+		for _, err := range report.Inputs {
+			assert.True(t, errs.IsErrorCode(err, errs.ErrCanceled))
+		}
+	})
+
+	t.Run("expired deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+		defer cancel()
+
+		report, err := interpreter.VerifyTx(ctx, signedTestTx(t, 2))
+		assert.NoError(t, err)
+		assert.ErrorIs(t, report.Err(), context.DeadlineExceeded)
+		for _, err := range report.Inputs {
+			assert.True(t, errs.IsErrorCode(err, errs.ErrTimeout))
+		}
+	})
+
+	t.Run("nil tx", func(t *testing.T) {
+		_, err := interpreter.VerifyTx(context.Background(), nil)
+		assert.True(t, errs.IsErrorCode(err, errs.ErrInvalidParams))
+	})
+}
@@ -0,0 +1,377 @@
+package bscript
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Element is a single op of a script, being its opcode along with the data it
+// pushes, if any.
+type Element struct {
+	Op   byte
+	Data []byte
+}
+
+// IsPush returns true if the element is a data push, including the empty push OP_0.
+func (e Element) IsPush() bool {
+	return e.Op <= OpPUSHDATA4
+}
+
+// Capture holds the elements of a script matched by a placeholder of a Pattern.
+type Capture struct {
+	// Name is the name given to the placeholder, `<name:type>`, or empty if the
+	// placeholder is unnamed.
+	Name     string
+	Elements []Element
+}
+
+// Data returns the data pushed by the first captured element, or nil if nothing
+// was captured.
+func (c Capture) Data() []byte {
+	if len(c.Elements) == 0 {
+		return nil
+	}
+	return c.Elements[0].Data
+}
+
+// Int returns the first captured element as a script number.
+func (c Capture) Int() (int64, error) {
+	if len(c.Elements) == 0 {
+		return 0, fmt.Errorf("%w: nothing captured by %q", ErrInvalidOpCode, c.Name)
+	}
+	return parseScriptNum(c.Elements[0].Op, c.Elements[0].Data)
+}
+
+// Captures holds the captures of a match, one for each placeholder in the pattern
+// in the order they appear.
+type Captures []Capture
+
+// Get returns the first capture with the given name.
+func (cc Captures) Get(name string) (Capture, bool) {
+	for _, c := range cc {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Capture{}, false
+}
+
+// placeholderTypes are the types which can be used in a pattern placeholder,
+// along with `<N bytes>`.
+var placeholderTypes = map[string]func(Element) bool{
+	"any": func(Element) bool { return true },
+	"op":  func(e Element) bool { return !e.IsPush() },
+	"data": func(e Element) bool {
+		return e.IsPush()
+	},
+	"pubkey": func(e Element) bool {
+		return e.IsPush() && isPubKeyLen(e.Data)
+	},
+	"smallint": func(e Element) bool {
+		return smallInt(e.Op) >= 0
+	},
+	"num": func(e Element) bool {
+		_, err := parseScriptNum(e.Op, e.Data)
+		return err == nil
+	},
+}
+
+type patternToken struct {
+	placeholder bool
+	name        string
+	min, max    int
+	match       func(Element) bool
+}
+
+// Pattern is a compiled script template, which a script can be matched against.
+//
+// A pattern is written like ASM, as space separated opcodes (OP_DUP) and hex data
+// pushes, with placeholders in angle brackets which match an element by type:
+//
+//	<data>      any data push, including OP_0
+//	<N bytes>   a data push of exactly N bytes, such as <20 bytes>
+//	<pubkey>    a data push of 33 or 65 bytes
+//	<smallint>  OP_0 or OP_1 to OP_16
+//	<num>       a script number, being a push of up to 5 bytes, OP_1NEGATE or a small int
+//	<op>        any opcode which is not a data push
+//	<any>       any element
+//
+// The elements matched by a placeholder are captured, and it can be named so that
+// they can be looked up after, such as <pkh:20 bytes>. The type can be followed by
+// ?, * or + to match zero or one, zero or more, or one or more elements, such as
+// <keys:pubkey+>.
+//
+// For example, a P2PKH locking script is matched by:
+//
+//	OP_DUP OP_HASH160 <pkh:20 bytes> OP_EQUALVERIFY OP_CHECKSIG
+type Pattern struct {
+	src    string
+	tokens []patternToken
+}
+
+// NewPattern compiles the pattern, returning ErrInvalidPattern if it is malformed.
+func NewPattern(pattern string) (*Pattern, error) {
+	parts, err := splitPattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("%w: pattern is empty", ErrInvalidPattern)
+	}
+
+	p := &Pattern{src: strings.Join(parts, " "), tokens: make([]patternToken, 0, len(parts))}
+	for _, part := range parts {
+		tok, err := parsePatternToken(part)
+		if err != nil {
+			return nil, err
+		}
+		p.tokens = append(p.tokens, tok)
+	}
+
+	return p, nil
+}
+
+// MustNewPattern compiles the pattern, panicking if it is malformed. It is intended
+// for patterns declared as package variables.
+func MustNewPattern(pattern string) *Pattern {
+	p, err := NewPattern(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// String returns the source of the pattern.
+func (p *Pattern) String() string {
+	return p.src
+}
+
+// Match returns true, along with the elements captured by each placeholder, if the
+// whole script matches the pattern.
+//
+// Quantified placeholders are matched greedily, backtracking as needed. Positions
+// already known not to lead to a match are remembered, so matching takes time linear
+// in the number of pattern tokens multiplied by the number of script elements.
+func (p *Pattern) Match(s *Script) (Captures, bool) {
+	if s == nil {
+		return nil, false
+	}
+	ee, err := s.elements()
+	if err != nil {
+		return nil, false
+	}
+
+	m := &matcher{
+		tokens: p.tokens,
+		ee:     ee,
+		failed: make([]bool, (len(p.tokens)+1)*(len(ee)+1)*2),
+	}
+
+	return m.match(0, 0, 0, Captures{})
+}
+
+// matcher matches elements against pattern tokens, recording the states which have
+// failed so that they are never explored twice.
+type matcher struct {
+	tokens []patternToken
+	ee     []Element
+	failed []bool
+}
+
+// match matches the elements from e onwards against the tokens from t onwards, where
+// the token t began matching at element start. A state is identified by t, e and
+// whether token t has matched any elements yet, as quantifiers only allow a min of
+// 0 or 1 and a max of 1 or unbounded.
+func (m *matcher) match(t, e, start int, cc Captures) (Captures, bool) {
+	if t == len(m.tokens) {
+		return cc, e == len(m.ee)
+	}
+
+	n := e - start
+	state := (t*(len(m.ee)+1) + e) * 2
+	if n > 0 {
+		state++
+	}
+	if m.failed[state] {
+		return nil, false
+	}
+
+	tok := m.tokens[t]
+	if n < tok.max && e < len(m.ee) && tok.match(m.ee[e]) {
+		if res, ok := m.match(t, e+1, start, cc); ok {
+			return res, true
+		}
+	}
+	if n >= tok.min {
+		next := cc
+		if tok.placeholder {
+			// Copy so that a failed branch cannot clobber the captures of another.
+			next = append(cc[:len(cc):len(cc)], Capture{Name: tok.name, Elements: m.ee[start:e]})
+		}
+		if res, ok := m.match(t+1, e, e, next); ok {
+			return res, true
+		}
+	}
+
+	m.failed[state] = true
+	return nil, false
+}
+
+// splitPattern splits the pattern on whitespace, keeping each placeholder whole.
+func splitPattern(pattern string) ([]string, error) {
+	var parts []string
+	for s := strings.TrimSpace(pattern); s != ""; s = strings.TrimSpace(s) {
+		var end int
+		if s[0] == '<' {
+			if end = strings.IndexByte(s, '>'); end < 0 {
+				return nil, fmt.Errorf("%w: unclosed placeholder %q", ErrInvalidPattern, s)
+			}
+			end++
+		} else if end = strings.IndexAny(s, " \t\r\n"); end < 0 {
+			end = len(s)
+		}
+		parts = append(parts, s[:end])
+		s = s[end:]
+	}
+
+	return parts, nil
+}
+
+func parsePatternToken(part string) (patternToken, error) {
+	if part[0] != '<' {
+		return parseLiteralToken(part)
+	}
+
+	tok := patternToken{placeholder: true, min: 1, max: 1}
+	typ := strings.TrimSpace(part[1 : len(part)-1])
+	if i := strings.IndexByte(typ, ':'); i >= 0 {
+		tok.name, typ = strings.TrimSpace(typ[:i]), strings.TrimSpace(typ[i+1:])
+		if tok.name == "" {
+			return tok, fmt.Errorf("%w: empty placeholder name in %s", ErrInvalidPattern, part)
+		}
+	}
+	if typ != "" {
+		switch typ[len(typ)-1] {
+		case '?':
+			tok.min = 0
+		case '*':
+			tok.min, tok.max = 0, int(^uint(0)>>1)
+		case '+':
+			tok.max = int(^uint(0) >> 1)
+		}
+		if tok.min != 1 || tok.max != 1 {
+			typ = strings.TrimSpace(typ[:len(typ)-1])
+		}
+	}
+
+	if match, ok := placeholderTypes[typ]; ok {
+		tok.match = match
+		return tok, nil
+	}
+
+	// <N bytes>
+	if ff := strings.Fields(typ); len(ff) == 2 && (ff[1] == "bytes" || ff[1] == "byte") {
+		l, err := strconv.Atoi(ff[0])
+		if err != nil || l < 0 {
+			return tok, fmt.Errorf("%w: invalid length in %s", ErrInvalidPattern, part)
+		}
+		tok.match = func(e Element) bool {
+			return e.IsPush() && len(e.Data) == l
+		}
+		return tok, nil
+	}
+
+	return tok, fmt.Errorf("%w: unknown placeholder type in %s", ErrInvalidPattern, part)
+}
+
+func parseLiteralToken(part string) (patternToken, error) {
+	tok := patternToken{min: 1, max: 1}
+	if op, ok := opCodeStrings[part]; ok {
+		tok.match = func(e Element) bool {
+			return e.Op == op
+		}
+		return tok, nil
+	}
+
+	data, err := hex.DecodeString(part)
+	if err != nil || len(data) == 0 {
+		return tok, fmt.Errorf("%w: %q is neither an opcode nor hex data", ErrInvalidPattern, part)
+	}
+	tok.match = func(e Element) bool {
+		return e.IsPush() && bytes.Equal(e.Data, data)
+	}
+
+	return tok, nil
+}
+
+// elements decodes the script into its elements.
+func (s *Script) elements() ([]Element, error) {
+	var ee []Element
+	for b := []byte(*s); len(b) > 0; {
+		op, data, rest, err := readOp(b)
+		if err != nil {
+			return nil, err
+		}
+		ee = append(ee, Element{Op: op, Data: data})
+		b = rest
+	}
+
+	return ee, nil
+}
+
+type scriptTypePattern struct {
+	scriptType string
+	pattern    *Pattern
+}
+
+var (
+	scriptTypePatternsMu sync.RWMutex
+	scriptTypePatterns   []scriptTypePattern
+)
+
+// RegisterScriptType registers a script type, reported by Script.ScriptType for
+// scripts which match the pattern, allowing applications to recognise their own
+// contract templates. Registered types are checked, in the order registered, after
+// the standard spendable types but before null data, so that data carrier protocols
+// can be told apart. ErrDuplicateScriptType is returned if the type is already in use.
+func RegisterScriptType(scriptType string, p *Pattern) error {
+	if scriptType == "" || p == nil {
+		return fmt.Errorf("%w: script type and pattern are required", ErrInvalidPattern)
+	}
+
+	switch scriptType {
+	case ScriptTypePubKey, ScriptTypePubKeyHash, ScriptTypeNonStandard, ScriptTypeEmpty,
+		ScriptTypeSecureHash, ScriptTypeMultiSig, ScriptTypeNullData, ScriptTypeScriptHash,
+		ScriptTypeCLTVPubKeyHash, ScriptTypeCSVPubKeyHash, ScriptTypeRPuzzle:
+		return fmt.Errorf("%w: %s", ErrDuplicateScriptType, scriptType)
+	}
+
+	scriptTypePatternsMu.Lock()
+	defer scriptTypePatternsMu.Unlock()
+	for _, stp := range scriptTypePatterns {
+		if stp.scriptType == scriptType {
+			return fmt.Errorf("%w: %s", ErrDuplicateScriptType, scriptType)
+		}
+	}
+	scriptTypePatterns = append(scriptTypePatterns, scriptTypePattern{scriptType: scriptType, pattern: p})
+
+	return nil
+}
+
+// registeredScriptType returns the first registered script type whose pattern the
+// script matches, or an empty string if there is none.
+func (s *Script) registeredScriptType() string {
+	scriptTypePatternsMu.RLock()
+	defer scriptTypePatternsMu.RUnlock()
+	for _, stp := range scriptTypePatterns {
+		if _, ok := stp.pattern.Match(s); ok {
+			return stp.scriptType
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,202 @@
+package bscript_test
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+	"github.com/mvc-labs/mvc-lib-go/keys/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPattern(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		pattern string
+		expErr  error
+	}{
+		"opcodes and placeholders": {
+			pattern: "OP_DUP OP_HASH160 <pkh:20 bytes> OP_EQUALVERIFY OP_CHECKSIG",
+		},
+		"quantified placeholders": {
+			pattern: "<m:smallint> <keys:pubkey+> <n:smallint> OP_CHECKMULTISIG <data*> <op?>",
+		},
+		"hex literal": {
+			pattern: "OP_FALSE OP_RETURN 6d65746169640a <data*>",
+		},
+		"empty": {
+			pattern: "  ",
+			expErr:  bscript.ErrInvalidPattern,
+		},
+		"unknown opcode": {
+			pattern: "OP_DUP OP_NOTANOP",
+			expErr:  bscript.ErrInvalidPattern,
+		},
+		"unclosed placeholder": {
+			pattern: "OP_DUP <20 bytes",
+			expErr:  bscript.ErrInvalidPattern,
+		},
+		"unknown placeholder type": {
+			pattern: "<sig>",
+			expErr:  bscript.ErrInvalidPattern,
+		},
+		"invalid length": {
+			pattern: "<x bytes>",
+			expErr:  bscript.ErrInvalidPattern,
+		},
+		"empty name": {
+			pattern: "<:data>",
+			expErr:  bscript.ErrInvalidPattern,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, err := bscript.NewPattern(test.pattern)
+			if test.expErr != nil {
+				assert.ErrorIs(t, err, test.expErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.pattern, p.String())
+		})
+	}
+
+	assert.Panics(t, func() { bscript.MustNewPattern("<20 bytes") })
+}
+
+func TestPattern_Match(t *testing.T) {
+	t.Parallel()
+
+	pkh := crypto.Hash160(mustDecodeHex(t, testPubKey1))
+	p2pkh, err := bscript.NewP2PKHFromPubKeyHash(pkh)
+	assert.NoError(t, err)
+
+	t.Run("p2pkh", func(t *testing.T) {
+		p := bscript.MustNewPattern("OP_DUP OP_HASH160 <pkh:20 bytes> OP_EQUALVERIFY OP_CHECKSIG")
+
+		cc, ok := p.Match(p2pkh)
+		assert.True(t, ok)
+		assert.Len(t, cc, 1)
+		c, ok := cc.Get("pkh")
+		assert.True(t, ok)
+		assert.Equal(t, pkh, c.Data())
+
+		p2pk, err := bscript.NewP2PKFromPubKeyBytes(mustDecodeHex(t, testPubKey1))
+		assert.NoError(t, err)
+		_, ok = p.Match(p2pk)
+		assert.False(t, ok)
+
+		// The whole script must match.
+		s := append(bscript.Script{}, *p2pkh...)
+		_ = s.AppendOpcodes(bscript.OpNOP)
+		_, ok = p.Match(&s)
+		assert.False(t, ok)
+	})
+
+	t.Run("multisig", func(t *testing.T) {
+		pubKeys := [][]byte{mustDecodeHex(t, testPubKey1), mustDecodeHex(t, testPubKey2), mustDecodeHex(t, testPubKey3)}
+		s, err := bscript.NewMultiSigFromPubKeyBytes(2, pubKeys)
+		assert.NoError(t, err)
+
+		p := bscript.MustNewPattern("<m:smallint> <keys:pubkey+> <n:smallint> OP_CHECKMULTISIG")
+		cc, ok := p.Match(s)
+		assert.True(t, ok)
+
+		m, _ := cc.Get("m")
+		mi, err := m.Int()
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), mi)
+
+		keys, _ := cc.Get("keys")
+		assert.Len(t, keys.Elements, 3)
+		for i, e := range keys.Elements {
+			assert.Equal(t, pubKeys[i], e.Data)
+		}
+
+		n, _ := cc.Get("n")
+		ni, err := n.Int()
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), ni)
+	})
+
+	t.Run("backtracking", func(t *testing.T) {
+		s, err := bscript.NewFromASM("OP_FALSE OP_RETURN 6d65746169640a 01 02 03")
+		assert.NoError(t, err)
+
+		p := bscript.MustNewPattern("OP_FALSE OP_RETURN 6d65746169640a <head:data*> <last:data>")
+		cc, ok := p.Match(s)
+		assert.True(t, ok)
+		head, _ := cc.Get("head")
+		assert.Len(t, head.Elements, 2)
+		last, _ := cc.Get("last")
+		assert.Equal(t, []byte{0x03}, last.Data())
+
+		_, ok = bscript.MustNewPattern("OP_FALSE OP_RETURN 6d657461 <data*>").Match(s)
+		assert.False(t, ok)
+	})
+
+	t.Run("optional and unnamed", func(t *testing.T) {
+		p := bscript.MustNewPattern("<num> OP_NOP2 OP_DROP <op?> <any*>")
+
+		s, err := bscript.NewCLTVP2PKHFromPubKeyHash(800000, pkh)
+		assert.NoError(t, err)
+		cc, ok := p.Match(s)
+		assert.True(t, ok)
+		assert.Len(t, cc, 3)
+		assert.Equal(t, "", cc[0].Name)
+		lockTime, err := cc[0].Int()
+		assert.NoError(t, err)
+		assert.Equal(t, int64(800000), lockTime)
+		assert.Equal(t, []bscript.Element{{Op: bscript.OpDUP}}, cc[1].Elements)
+		assert.Len(t, cc[2].Elements, 4)
+	})
+
+	t.Run("truncated script", func(t *testing.T) {
+		s := bscript.NewFromBytes([]byte{bscript.OpDUP, 0x14, 0x01})
+		_, ok := bscript.MustNewPattern("OP_DUP <any*>").Match(s)
+		assert.False(t, ok)
+	})
+
+	t.Run("adjacent quantifiers are not exponential", func(t *testing.T) {
+		s := &bscript.Script{}
+		for i := 0; i < 1000; i++ {
+			assert.NoError(t, s.AppendPushData([]byte{0x01}))
+		}
+
+		p := bscript.MustNewPattern("<data*> <data*> <data*> <data*> OP_CHECKSIG")
+		start := time.Now()
+		_, ok := p.Match(s)
+		assert.False(t, ok)
+		assert.Less(t, time.Since(start), time.Second)
+
+		_ = s.AppendOpcodes(bscript.OpCHECKSIG)
+		cc, ok := p.Match(s)
+		assert.True(t, ok)
+		assert.Len(t, cc[0].Elements, 1000)
+	})
+}
+
+func TestRegisterScriptType(t *testing.T) {
+	t.Parallel()
+
+	const scriptType = "test-hashlock"
+	p := bscript.MustNewPattern("OP_SHA256 <hash:32 bytes> OP_EQUAL")
+	assert.NoError(t, bscript.RegisterScriptType(scriptType, p))
+	assert.ErrorIs(t, bscript.RegisterScriptType(scriptType, p), bscript.ErrDuplicateScriptType)
+	assert.ErrorIs(t, bscript.RegisterScriptType(bscript.ScriptTypePubKeyHash, p), bscript.ErrDuplicateScriptType)
+
+	s := &bscript.Script{}
+	_ = s.AppendOpcodes(bscript.OpSHA256)
+	assert.NoError(t, s.AppendPushDataHexString(hex.EncodeToString(crypto.Sha256([]byte("secret")))))
+	_ = s.AppendOpcodes(bscript.OpEQUAL)
+	assert.Equal(t, scriptType, s.ScriptType())
+
+	// Standard types are reported ahead of registered ones.
+	pkh := crypto.Hash160(mustDecodeHex(t, testPubKey1))
+	p2pkh, err := bscript.NewP2PKHFromPubKeyHash(pkh)
+	assert.NoError(t, err)
+	assert.Equal(t, bscript.ScriptTypePubKeyHash, p2pkh.ScriptType())
+}
@@ -11,18 +11,23 @@ import (
 
 	"github.com/mvc-labs/mvc-lib-go/keys/bec"
 	"github.com/mvc-labs/mvc-lib-go/keys/bip32"
+	"github.com/mvc-labs/mvc-lib-go/keys/chaincfg"
 	"github.com/mvc-labs/mvc-lib-go/keys/crypto"
 )
 
 // ScriptKey types.
 const (
-	ScriptTypePubKey      = "pubkey"
-	ScriptTypePubKeyHash  = "pubkeyhash"
-	ScriptTypeNonStandard = "nonstandard"
-	ScriptTypeEmpty       = "empty"
-	ScriptTypeSecureHash  = "securehash"
-	ScriptTypeMultiSig    = "multisig"
-	ScriptTypeNullData    = "nulldata"
+	ScriptTypePubKey         = "pubkey"
+	ScriptTypePubKeyHash     = "pubkeyhash"
+	ScriptTypeNonStandard    = "nonstandard"
+	ScriptTypeEmpty          = "empty"
+	ScriptTypeSecureHash     = "securehash"
+	ScriptTypeMultiSig       = "multisig"
+	ScriptTypeNullData       = "nulldata"
+	ScriptTypeScriptHash     = "scripthash"
+	ScriptTypeCLTVPubKeyHash = "cltvpubkeyhash"
+	ScriptTypeCSVPubKeyHash  = "csvpubkeyhash"
+	ScriptTypeRPuzzle        = "rpuzzle"
 )
 
 // Script type
@@ -44,6 +49,7 @@ func NewFromBytes(b []byte) *Script {
 }
 
 // NewFromASM creates a new script from a BitCoin ASM formatted string.
+// ParseASM accepts a richer syntax, including numbers and quoted strings.
 func NewFromASM(str string) (*Script, error) {
 	s := Script{}
 
@@ -112,27 +118,34 @@ func NewP2PKHFromPubKeyHashStr(pubKeyHash string) (*Script, error) {
 	return NewP2PKHFromPubKeyHash(hash)
 }
 
-// NewP2PKHFromAddress takes an address
+// NewP2PKHFromAddress takes a P2PKH address
 // and creates a P2PKH script from it.
 func NewP2PKHFromAddress(addr string) (*Script, error) {
 	a, err := NewAddressFromString(addr)
 	if err != nil {
 		return nil, err
 	}
-
-	var publicKeyHashBytes []byte
-	if publicKeyHashBytes, err = hex.DecodeString(a.PublicKeyHash); err != nil {
-		return nil, err
+	if a.Type != AddressTypeP2PKH {
+		return nil, fmt.Errorf("%w: %s is a %s address", ErrNotP2PKH, addr, a.Type)
 	}
 
-	s := new(Script)
-	_ = s.AppendOpcodes(OpDUP, OpHASH160)
-	if err = s.AppendPushData(publicKeyHashBytes); err != nil {
-		return nil, err
+	return a.LockingScript()
+}
+
+// NewP2SHFromScriptHash takes the hash160 of a redeem script
+// and creates a P2SH script from it.
+func NewP2SHFromScriptHash(scriptHash []byte) (*Script, error) {
+	if len(scriptHash) != 20 {
+		return nil, fmt.Errorf("%w: script hash is %d bytes", ErrInvalidAddressLength, len(scriptHash))
 	}
-	_ = s.AppendOpcodes(OpEQUALVERIFY, OpCHECKSIG)
 
-	return s, nil
+	b := make([]byte, 0, 23)
+	b = append(b, OpHASH160, OpDATA20)
+	b = append(b, scriptHash...)
+	b = append(b, OpEQUAL)
+
+	s := Script(b)
+	return &s, nil
 }
 
 // NewP2PKHFromBip32ExtKey takes a *bip32.ExtendedKey and creates a P2PKH script from it,
@@ -226,7 +239,8 @@ func (s *Script) String() string {
 	return hex.EncodeToString(*s)
 }
 
-// ToASM returns the string ASM opcodes of the script.
+// ToASM returns the string ASM opcodes of the script. See FormatASM to render
+// the script as the node does, or losslessly.
 func (s *Script) ToASM() (string, error) {
 	if s == nil || len(*s) == 0 {
 		return "", nil
@@ -302,7 +316,6 @@ func (s *Script) IsP2PK() bool {
 }
 
 // IsP2SH returns true if this is a p2sh output script.
-// TODO: remove all p2sh stuff from repo
 func (s *Script) IsP2SH() bool {
 	b := []byte(*s)
 
@@ -368,7 +381,20 @@ func (s *Script) PublicKeyHash() ([]byte, error) {
 	return parts[0], nil
 }
 
-// ScriptType returns the type of script this is as a string.
+// ScriptHash returns the redeem script hash byte array if the script is a P2SH script.
+func (s *Script) ScriptHash() ([]byte, error) {
+	if s == nil || len(*s) == 0 {
+		return nil, ErrEmptyScript
+	}
+	if !s.IsP2SH() {
+		return nil, ErrNotP2SH
+	}
+
+	return (*s)[2:22], nil
+}
+
+// ScriptType returns the type of script this is as a string, which may be a type
+// registered with RegisterScriptType.
 func (s *Script) ScriptType() string {
 	if len(*s) == 0 {
 		return ScriptTypeEmpty
@@ -379,24 +405,35 @@ func (s *Script) ScriptType() string {
 	if s.IsP2PK() {
 		return ScriptTypePubKey
 	}
+	if s.IsP2SH() {
+		return ScriptTypeScriptHash
+	}
 	if s.IsMultiSigOut() {
 		return ScriptTypeMultiSig
 	}
+	if s.IsCLTVP2PKH() {
+		return ScriptTypeCLTVPubKeyHash
+	}
+	if s.IsCSVP2PKH() {
+		return ScriptTypeCSVPubKeyHash
+	}
+	if s.IsRPuzzle() {
+		return ScriptTypeRPuzzle
+	}
+	if t := s.registeredScriptType(); t != "" {
+		return t
+	}
 	if s.IsData() {
 		return ScriptTypeNullData
 	}
 	return ScriptTypeNonStandard
 }
 
-// Addresses will return all addresses found in the script, if any.
+// Addresses will return all mainnet addresses found in the script, if any.
 func (s *Script) Addresses() ([]string, error) {
 	addresses := make([]string, 0)
-	if s.IsP2PKH() {
-		pkh, err := s.PublicKeyHash()
-		if err != nil {
-			return nil, err
-		}
-		a, err := NewAddressFromPublicKeyHash(pkh, true)
+	if s.IsP2PKH() || s.IsP2SH() {
+		a, err := NewAddressFromLockingScript(s, &chaincfg.MainNet)
 		if err != nil {
 			return nil, err
 		}
@@ -249,6 +249,64 @@ func TestScript_PublicKeyHash(t *testing.T) {
 	})
 }
 
+func TestScript_ScriptHash(t *testing.T) {
+	t.Parallel()
+
+	t.Run("P2SH", func(t *testing.T) {
+		s, err := bscript.NewFromHexString("a9149de5aeaff9c48431ba4dd6e8af73d51f38e451cb87")
+		assert.NoError(t, err)
+
+		sh, err := s.ScriptHash()
+		assert.NoError(t, err)
+		assert.Equal(t, "9de5aeaff9c48431ba4dd6e8af73d51f38e451cb", hex.EncodeToString(sh))
+
+		s2, err := bscript.NewP2SHFromScriptHash(sh)
+		assert.NoError(t, err)
+		assert.Equal(t, s, s2)
+	})
+
+	t.Run("P2PKH", func(t *testing.T) {
+		s, err := bscript.NewFromHexString("76a91404d03f746652cfcb6cb55119ab473a045137d26588ac")
+		assert.NoError(t, err)
+
+		_, err = s.ScriptHash()
+		assert.ErrorIs(t, err, bscript.ErrNotP2SH)
+	})
+}
+
+func TestScript_Addresses(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		script       string
+		expAddresses []string
+	}{
+		"P2PKH": {
+			script:       "76a91404d03f746652cfcb6cb55119ab473a045137d26588ac",
+			expAddresses: []string{"1STB5pAEKX9Cw5yJsYYDRrZsy6dgQGhyz"},
+		},
+		"P2SH": {
+			script:       "a914f815b036d9bbbce5e9f2a00abd1bf3dc91e9551087",
+			expAddresses: []string{"3QJmV3qfvL9SuYo34YihAf3sRCW3qSinyC"},
+		},
+		"data": {
+			script:       "006a0102",
+			expAddresses: []string{},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, err := bscript.NewFromHexString(test.script)
+			assert.NoError(t, err)
+
+			addresses, err := s.Addresses()
+			assert.NoError(t, err)
+			assert.Equal(t, test.expAddresses, addresses)
+		})
+	}
+}
+
 func TestErrorIsAppended(t *testing.T) {
 	script, _ := hex.DecodeString("6a0548656c6c6f0548656c6c")
 	s := bscript.Script(script)
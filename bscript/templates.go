@@ -0,0 +1,425 @@
+package bscript
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/mvc-labs/mvc-lib-go/keys/bec"
+	"github.com/mvc-labs/mvc-lib-go/keys/crypto"
+)
+
+// MaxMultiSigKeys is the maximum number of public keys in a multisig locking script
+// built or parsed by this package, being the largest n expressible as OP_n.
+const MaxMultiSigKeys = 16
+
+// rPuzzlePrefix extracts R from the signature of an unlocking script `<sig> <pubkey>`,
+// leaving it on top of the stack.
+var rPuzzlePrefix = []byte{
+	OpOVER, Op3, OpSPLIT, OpNIP, OpONE, OpSPLIT, OpSWAP, OpSPLIT, OpDROP,
+}
+
+// rPuzzleHashLens is the length of the digest of each opcode which an R-puzzle can
+// hash R with.
+var rPuzzleHashLens = map[byte]int{
+	OpRIPEMD160: 20,
+	OpSHA1:      20,
+	OpSHA256:    32,
+	OpHASH160:   20,
+	OpHASH256:   32,
+}
+
+// NewP2PKFromPubKeyEC takes a public key and creates a P2PK script,
+// `<pubkey> OP_CHECKSIG`, from it, using the compressed public key.
+func NewP2PKFromPubKeyEC(pubKey *bec.PublicKey) (*Script, error) {
+	return NewP2PKFromPubKeyBytes(pubKey.SerialiseCompressed())
+}
+
+// NewP2PKFromPubKeyBytes takes public key bytes (in compressed or
+// uncompressed format) and creates a P2PK script from it.
+func NewP2PKFromPubKeyBytes(pubKey []byte) (*Script, error) {
+	if !isPubKeyLen(pubKey) {
+		return nil, ErrInvalidPKLen
+	}
+
+	s := &Script{}
+	if err := s.AppendPushData(pubKey); err != nil {
+		return nil, err
+	}
+	_ = s.AppendOpcodes(OpCHECKSIG)
+
+	return s, nil
+}
+
+// NewMultiSigFromPubKeys takes the public keys and creates an m-of-n bare multisig
+// script, `OP_m <pubkey>... OP_n OP_CHECKMULTISIG`, from them, using the compressed
+// public keys, in the order provided.
+func NewMultiSigFromPubKeys(m int, pubKeys []*bec.PublicKey) (*Script, error) {
+	bb := make([][]byte, 0, len(pubKeys))
+	for _, pubKey := range pubKeys {
+		bb = append(bb, pubKey.SerialiseCompressed())
+	}
+
+	return NewMultiSigFromPubKeyBytes(m, bb)
+}
+
+// NewMultiSigFromPubKeyBytes takes public key bytes (in compressed or
+// uncompressed format) and creates an m-of-n bare multisig script from them,
+// in the order provided. Both m and n must be between 1 and MaxMultiSigKeys,
+// with m no more than n.
+func NewMultiSigFromPubKeyBytes(m int, pubKeys [][]byte) (*Script, error) {
+	n := len(pubKeys)
+	if m < 1 || n > MaxMultiSigKeys || m > n {
+		return nil, fmt.Errorf("%w: %d-of-%d", ErrInvalidMultiSig, m, n)
+	}
+
+	s := &Script{}
+	_ = s.AppendOpcodes(OpONE + byte(m-1))
+	for i, pubKey := range pubKeys {
+		if !isPubKeyLen(pubKey) {
+			return nil, fmt.Errorf("%w: key %d", ErrInvalidPKLen, i)
+		}
+		if err := s.AppendPushData(pubKey); err != nil {
+			return nil, err
+		}
+	}
+	_ = s.AppendOpcodes(OpONE+byte(n-1), OpCHECKMULTISIG)
+
+	return s, nil
+}
+
+// NewP2SHFromRedeemScript takes a redeem script and creates a P2SH script,
+// `OP_HASH160 <hash160(redeem script)> OP_EQUAL`, wrapping it.
+//
+// P2SH outputs are only executed as such before genesis; an after-genesis
+// output with this script is an ordinary hash puzzle.
+func NewP2SHFromRedeemScript(redeemScript *Script) (*Script, error) {
+	return NewP2SHFromScriptHash(crypto.Hash160(*redeemScript))
+}
+
+// NewCLTVP2PKHFromPubKeyHash takes a public key hash and creates a P2PKH script
+// which cannot be spent until the lock time, as a block height or unix timestamp,
+// is reached: `<lockTime> OP_CHECKLOCKTIMEVERIFY OP_DROP <P2PKH>`.
+//
+// The lock time is only enforced by the interpreter before genesis, after which
+// OP_CHECKLOCKTIMEVERIFY is executed as OP_NOP2.
+func NewCLTVP2PKHFromPubKeyHash(lockTime uint32, pubKeyHash []byte) (*Script, error) {
+	return newTimeLockedP2PKH(OpCHECKLOCKTIMEVERIFY, lockTime, pubKeyHash)
+}
+
+// NewCSVP2PKHFromPubKeyHash takes a public key hash and creates a P2PKH script
+// which cannot be spent until the relative lock time, encoded as in an input
+// sequence number, has passed: `<sequence> OP_CHECKSEQUENCEVERIFY OP_DROP <P2PKH>`.
+//
+// The relative lock time is only enforced by the interpreter before genesis,
+// after which OP_CHECKSEQUENCEVERIFY is executed as OP_NOP3.
+func NewCSVP2PKHFromPubKeyHash(sequence uint32, pubKeyHash []byte) (*Script, error) {
+	return newTimeLockedP2PKH(OpCHECKSEQUENCEVERIFY, sequence, pubKeyHash)
+}
+
+func newTimeLockedP2PKH(op byte, lock uint32, pubKeyHash []byte) (*Script, error) {
+	if len(pubKeyHash) != 20 {
+		return nil, fmt.Errorf("%w: public key hash is %d bytes", ErrInvalidAddressLength, len(pubKeyHash))
+	}
+
+	s := &Script{}
+	if err := s.appendScriptNum(int64(lock)); err != nil {
+		return nil, err
+	}
+	_ = s.AppendOpcodes(op, OpDROP)
+
+	p2pkh, err := NewP2PKHFromPubKeyHash(pubKeyHash)
+	if err != nil {
+		return nil, err
+	}
+	*s = append(*s, *p2pkh...)
+
+	return s, nil
+}
+
+// NewRPuzzleFromR takes the R value of a signature and creates an R-puzzle script,
+// which is unlocked by `<sig> <pubkey>` where sig is any signature by pubkey,
+// using the k value which produces R.
+func NewRPuzzleFromR(r []byte) (*Script, error) {
+	if len(r) == 0 {
+		return nil, ErrInvalidRPuzzle
+	}
+
+	return newRPuzzle(nil, r)
+}
+
+// NewRPuzzleFromRHash takes a hash of the R value of a signature, as computed by
+// hashOp, and creates an R-puzzle script which hashes R before comparing it. The
+// hashOp must be one of OP_RIPEMD160, OP_SHA1, OP_SHA256, OP_HASH160 or OP_HASH256.
+func NewRPuzzleFromRHash(hashOp byte, rHash []byte) (*Script, error) {
+	l, ok := rPuzzleHashLens[hashOp]
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported hash op %s", ErrInvalidRPuzzle, opCodeValues[hashOp])
+	}
+	if len(rHash) != l {
+		return nil, fmt.Errorf("%w: %s hash is %d bytes", ErrInvalidRPuzzle, opCodeValues[hashOp], len(rHash))
+	}
+
+	return newRPuzzle([]byte{hashOp}, rHash)
+}
+
+func newRPuzzle(hashOp []byte, value []byte) (*Script, error) {
+	s := NewFromBytes(append([]byte{}, rPuzzlePrefix...))
+	_ = s.AppendOpcodes(hashOp...)
+	if err := s.AppendPushData(value); err != nil {
+		return nil, err
+	}
+	_ = s.AppendOpcodes(OpEQUALVERIFY, OpCHECKSIG)
+
+	return s, nil
+}
+
+// IsCLTVP2PKH returns true if this is a P2PKH script guarded by OP_CHECKLOCKTIMEVERIFY.
+func (s *Script) IsCLTVP2PKH() bool {
+	_, _, err := s.CLTVP2PKH()
+	return err == nil
+}
+
+// IsCSVP2PKH returns true if this is a P2PKH script guarded by OP_CHECKSEQUENCEVERIFY.
+func (s *Script) IsCSVP2PKH() bool {
+	_, _, err := s.CSVP2PKH()
+	return err == nil
+}
+
+// IsRPuzzle returns true if this is an R-puzzle script.
+func (s *Script) IsRPuzzle() bool {
+	_, _, err := s.RPuzzle()
+	return err == nil
+}
+
+// PublicKey returns the public key byte array if the script is a P2PK script.
+func (s *Script) PublicKey() ([]byte, error) {
+	if s == nil || len(*s) == 0 {
+		return nil, ErrEmptyScript
+	}
+	if !s.IsP2PK() {
+		return nil, ErrNotP2PK
+	}
+
+	_, data, _, err := readOp(*s)
+	return data, err
+}
+
+// MultiSig returns the number of signatures required, m, and the public keys, whose
+// count is n, if the script is an m-of-n bare multisig script.
+func (s *Script) MultiSig() (int, [][]byte, error) {
+	if s == nil || len(*s) == 0 {
+		return 0, nil, ErrEmptyScript
+	}
+
+	b := []byte(*s)
+	if len(b) < 3 || b[len(b)-1] != OpCHECKMULTISIG {
+		return 0, nil, ErrNotMultiSig
+	}
+	m, n := smallInt(b[0]), smallInt(b[len(b)-2])
+	if m < 1 || n < m {
+		return 0, nil, ErrNotMultiSig
+	}
+
+	pubKeys := make([][]byte, 0, n)
+	for rest := b[1 : len(b)-2]; len(rest) > 0; {
+		op, data, next, err := readOp(rest)
+		if err != nil || op == OpZERO || op > OpPUSHDATA4 || !isPubKeyLen(data) {
+			return 0, nil, ErrNotMultiSig
+		}
+		pubKeys = append(pubKeys, data)
+		rest = next
+	}
+	if len(pubKeys) != n {
+		return 0, nil, ErrNotMultiSig
+	}
+
+	return m, pubKeys, nil
+}
+
+// CLTVP2PKH returns the lock time and public key hash if the script is a P2PKH
+// script guarded by OP_CHECKLOCKTIMEVERIFY.
+func (s *Script) CLTVP2PKH() (uint32, []byte, error) {
+	return s.timeLockedP2PKH(OpCHECKLOCKTIMEVERIFY, ErrNotCLTVP2PKH)
+}
+
+// CSVP2PKH returns the relative lock time, encoded as in an input sequence number,
+// and public key hash if the script is a P2PKH script guarded by OP_CHECKSEQUENCEVERIFY.
+func (s *Script) CSVP2PKH() (uint32, []byte, error) {
+	return s.timeLockedP2PKH(OpCHECKSEQUENCEVERIFY, ErrNotCSVP2PKH)
+}
+
+func (s *Script) timeLockedP2PKH(op byte, errNot error) (uint32, []byte, error) {
+	if s == nil || len(*s) == 0 {
+		return 0, nil, ErrEmptyScript
+	}
+
+	numOp, data, rest, err := readOp(*s)
+	if err != nil {
+		return 0, nil, errNot
+	}
+	lock, err := parseScriptNum(numOp, data)
+	if err != nil || lock < 0 || lock > 0xffffffff {
+		return 0, nil, errNot
+	}
+	if len(rest) < 2 || rest[0] != op || rest[1] != OpDROP {
+		return 0, nil, errNot
+	}
+
+	p2pkh := Script(rest[2:])
+	if !p2pkh.IsP2PKH() {
+		return 0, nil, errNot
+	}
+
+	return uint32(lock), p2pkh[3:23], nil
+}
+
+// RPuzzle returns the opcode R is hashed with, along with the hash, if the script is
+// an R-puzzle script. If R is compared directly, the returned opcode is OP_0 and the
+// R value itself is returned.
+func (s *Script) RPuzzle() (byte, []byte, error) {
+	if s == nil || len(*s) == 0 {
+		return 0, nil, ErrEmptyScript
+	}
+
+	b := []byte(*s)
+	if len(b) < len(rPuzzlePrefix)+3 || string(b[:len(rPuzzlePrefix)]) != string(rPuzzlePrefix) ||
+		b[len(b)-2] != OpEQUALVERIFY || b[len(b)-1] != OpCHECKSIG {
+		return 0, nil, ErrNotRPuzzle
+	}
+
+	b = b[len(rPuzzlePrefix) : len(b)-2]
+	var hashOp byte
+	if l, ok := rPuzzleHashLens[b[0]]; ok {
+		hashOp, b = b[0], b[1:]
+		if len(b) != l+1 {
+			return 0, nil, ErrNotRPuzzle
+		}
+	}
+
+	op, value, rest, err := readOp(b)
+	if err != nil || len(rest) > 0 || op == OpZERO || op > OpPUSHDATA4 {
+		return 0, nil, ErrNotRPuzzle
+	}
+
+	return hashOp, value, nil
+}
+
+// readOp reads the first op of the script, returning its opcode, the data it pushes
+// if any, and the remainder of the script.
+func readOp(b []byte) (byte, []byte, []byte, error) {
+	op := b[0]
+	if op == OpZERO || op > OpPUSHDATA4 {
+		return op, nil, b[1:], nil
+	}
+
+	var l, n int
+	switch op {
+	case OpPUSHDATA1:
+		if len(b) < 2 {
+			return 0, nil, nil, ErrDataTooSmall
+		}
+		l, n = int(b[1]), 2
+	case OpPUSHDATA2:
+		if len(b) < 3 {
+			return 0, nil, nil, ErrDataTooSmall
+		}
+		l, n = int(binary.LittleEndian.Uint16(b[1:])), 3
+	case OpPUSHDATA4:
+		if len(b) < 5 {
+			return 0, nil, nil, ErrDataTooSmall
+		}
+		l, n = int(binary.LittleEndian.Uint32(b[1:])), 5
+	default:
+		l, n = int(op), 1
+	}
+	if len(b)-n < l {
+		return 0, nil, nil, ErrDataTooSmall
+	}
+
+	return op, b[n : n+l], b[n+l:], nil
+}
+
+// appendScriptNum appends the number to the script as a minimally encoded script
+// number, using OP_0, OP_1NEGATE and OP_1 to OP_16 where possible.
+func (s *Script) appendScriptNum(n int64) error {
+	return s.appendScriptBigNum(big.NewInt(n))
+}
+
+// appendScriptBigNum appends a number of any size to the script as a minimally
+// encoded script number, as appendScriptNum.
+func (s *Script) appendScriptBigNum(n *big.Int) error {
+	if n.IsInt64() {
+		switch v := n.Int64(); {
+		case v == 0:
+			return s.AppendOpcodes(OpZERO)
+		case v == -1:
+			return s.AppendOpcodes(Op1NEGATE)
+		case v >= 1 && v <= 16:
+			return s.AppendOpcodes(OpONE + byte(v-1))
+		}
+	}
+
+	// Script numbers are little endian sign and magnitude.
+	mag := n.Bytes()
+	b := make([]byte, len(mag), len(mag)+1)
+	for i, c := range mag {
+		b[len(mag)-1-i] = c
+	}
+	// The most significant bit of the last byte holds the sign, so if it is already
+	// set an extra byte is needed.
+	if b[len(b)-1]&0x80 != 0 {
+		b = append(b, 0x00)
+	}
+	if n.Sign() < 0 {
+		b[len(b)-1] |= 0x80
+	}
+
+	return s.AppendPushData(b)
+}
+
+// parseScriptNum parses a number, of up to 5 bytes, as written by appendScriptNum.
+func parseScriptNum(op byte, data []byte) (int64, error) {
+	switch {
+	case op == OpZERO:
+		return 0, nil
+	case op == Op1NEGATE:
+		return -1, nil
+	case op >= OpONE && op <= Op16:
+		return int64(op-OpONE) + 1, nil
+	case op > OpPUSHDATA4:
+		return 0, fmt.Errorf("%w: %s is not a number", ErrInvalidOpCode, opCodeValues[op])
+	}
+	if len(data) > 5 {
+		return 0, fmt.Errorf("%w: number is %d bytes", ErrInvalidOpCode, len(data))
+	}
+	if len(data) == 0 {
+		return 0, nil
+	}
+
+	var n int64
+	for i, b := range data {
+		n |= int64(b) << (8 * i)
+	}
+	if data[len(data)-1]&0x80 != 0 {
+		n &^= int64(0x80) << (8 * (len(data) - 1))
+		n = -n
+	}
+
+	return n, nil
+}
+
+// smallInt returns the number pushed by OP_0 or OP_1 to OP_16, or -1 for any other opcode.
+func smallInt(op byte) int {
+	switch {
+	case op == OpZERO:
+		return 0
+	case op >= OpONE && op <= Op16:
+		return int(op-OpONE) + 1
+	}
+	return -1
+}
+
+func isPubKeyLen(pubKey []byte) bool {
+	return len(pubKey) == 33 || len(pubKey) == 65
+}
@@ -0,0 +1,297 @@
+package bscript_test
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+	"github.com/mvc-labs/mvc-lib-go/bscript/interpreter"
+	"github.com/mvc-labs/mvc-lib-go/keys/bec"
+	"github.com/mvc-labs/mvc-lib-go/keys/crypto"
+	"github.com/mvc-labs/mvc-lib-go/sighash"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testPubKey1 = "026cf33373a9f3f6c676b75b543180703df225f7f8edbffedc417718a8ad4e89ce"
+	testPubKey2 = "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+	testPubKey3 = "03a34b99f22c790c4e36b2b3c2c35a36db06226e41c692fc82b8b56ac1c540c5bd"
+)
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	assert.NoError(t, err)
+	return b
+}
+
+func assertASM(t *testing.T, s *bscript.Script, expASM string) {
+	asm, err := s.ToASM()
+	assert.NoError(t, err)
+	assert.Equal(t, expASM, asm)
+}
+
+func TestNewP2PKFromPubKeyBytes(t *testing.T) {
+	t.Parallel()
+
+	pubKey := mustDecodeHex(t, testPubKey1)
+	s, err := bscript.NewP2PKFromPubKeyBytes(pubKey)
+	assert.NoError(t, err)
+	assertASM(t, s, testPubKey1+" OP_CHECKSIG")
+	assert.Equal(t, bscript.ScriptTypePubKey, s.ScriptType())
+
+	pk, err := s.PublicKey()
+	assert.NoError(t, err)
+	assert.Equal(t, pubKey, pk)
+
+	_, err = bscript.NewP2PKFromPubKeyBytes(pubKey[1:])
+	assert.ErrorIs(t, err, bscript.ErrInvalidPKLen)
+
+	p2pkh, err := bscript.NewP2PKHFromPubKeyBytes(pubKey)
+	assert.NoError(t, err)
+	_, err = p2pkh.PublicKey()
+	assert.ErrorIs(t, err, bscript.ErrNotP2PK)
+}
+
+func TestNewMultiSigFromPubKeyBytes(t *testing.T) {
+	t.Parallel()
+
+	pubKeys := [][]byte{
+		mustDecodeHex(t, testPubKey1),
+		mustDecodeHex(t, testPubKey2),
+		mustDecodeHex(t, testPubKey3),
+	}
+
+	t.Run("2 of 3", func(t *testing.T) {
+		s, err := bscript.NewMultiSigFromPubKeyBytes(2, pubKeys)
+		assert.NoError(t, err)
+		assertASM(t, s, "OP_2 "+testPubKey1+" "+testPubKey2+" "+testPubKey3+" OP_3 OP_CHECKMULTISIG")
+		assert.Equal(t, bscript.ScriptTypeMultiSig, s.ScriptType())
+
+		m, pks, err := s.MultiSig()
+		assert.NoError(t, err)
+		assert.Equal(t, 2, m)
+		assert.Equal(t, pubKeys, pks)
+	})
+
+	t.Run("invalid m of n", func(t *testing.T) {
+		for _, m := range []int{0, 4} {
+			_, err := bscript.NewMultiSigFromPubKeyBytes(m, pubKeys)
+			assert.ErrorIs(t, err, bscript.ErrInvalidMultiSig)
+		}
+
+		tooMany := make([][]byte, bscript.MaxMultiSigKeys+1)
+		for i := range tooMany {
+			tooMany[i] = pubKeys[0]
+		}
+		_, err := bscript.NewMultiSigFromPubKeyBytes(1, tooMany)
+		assert.ErrorIs(t, err, bscript.ErrInvalidMultiSig)
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		_, err := bscript.NewMultiSigFromPubKeyBytes(1, [][]byte{pubKeys[0], pubKeys[1][1:]})
+		assert.ErrorIs(t, err, bscript.ErrInvalidPKLen)
+	})
+
+	t.Run("n does not match keys", func(t *testing.T) {
+		s, err := bscript.NewFromASM("OP_1 " + testPubKey1 + " " + testPubKey2 + " OP_3 OP_CHECKMULTISIG")
+		assert.NoError(t, err)
+
+		_, _, err = s.MultiSig()
+		assert.ErrorIs(t, err, bscript.ErrNotMultiSig)
+	})
+}
+
+func TestNewP2SHFromRedeemScript(t *testing.T) {
+	t.Parallel()
+
+	redeemScript, err := bscript.NewMultiSigFromPubKeyBytes(1, [][]byte{mustDecodeHex(t, testPubKey1)})
+	assert.NoError(t, err)
+
+	s, err := bscript.NewP2SHFromRedeemScript(redeemScript)
+	assert.NoError(t, err)
+	assert.Equal(t, bscript.ScriptTypeScriptHash, s.ScriptType())
+
+	sh, err := s.ScriptHash()
+	assert.NoError(t, err)
+	assert.Equal(t, crypto.Hash160(*redeemScript), sh)
+}
+
+func TestNewCLTVP2PKHFromPubKeyHash(t *testing.T) {
+	t.Parallel()
+
+	pkh := crypto.Hash160(mustDecodeHex(t, testPubKey1))
+
+	tests := map[string]struct {
+		lockTime  uint32
+		expLockOp string
+	}{
+		"zero": {
+			lockTime:  0,
+			expLockOp: "OP_FALSE",
+		},
+		"small int": {
+			lockTime:  16,
+			expLockOp: "OP_16",
+		},
+		"sign byte": {
+			lockTime:  128,
+			expLockOp: "8000",
+		},
+		"block height": {
+			lockTime:  800000,
+			expLockOp: "00350c",
+		},
+		"max": {
+			lockTime:  0xffffffff,
+			expLockOp: "ffffffff00",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, err := bscript.NewCLTVP2PKHFromPubKeyHash(test.lockTime, pkh)
+			assert.NoError(t, err)
+			assertASM(t, s, test.expLockOp+" OP_NOP2 OP_DROP OP_DUP OP_HASH160 "+hex.EncodeToString(pkh)+
+				" OP_EQUALVERIFY OP_CHECKSIG")
+			assert.Equal(t, bscript.ScriptTypeCLTVPubKeyHash, s.ScriptType())
+			assert.False(t, s.IsCSVP2PKH())
+
+			lockTime, pubKeyHash, err := s.CLTVP2PKH()
+			assert.NoError(t, err)
+			assert.Equal(t, test.lockTime, lockTime)
+			assert.Equal(t, pkh, pubKeyHash)
+		})
+	}
+
+	t.Run("invalid pkh", func(t *testing.T) {
+		_, err := bscript.NewCLTVP2PKHFromPubKeyHash(1, pkh[1:])
+		assert.ErrorIs(t, err, bscript.ErrInvalidAddressLength)
+	})
+
+	t.Run("negative lock time", func(t *testing.T) {
+		s, err := bscript.NewFromASM("81 OP_NOP2 OP_DROP OP_DUP OP_HASH160 " + hex.EncodeToString(pkh) +
+			" OP_EQUALVERIFY OP_CHECKSIG")
+		assert.NoError(t, err)
+
+		_, _, err = s.CLTVP2PKH()
+		assert.ErrorIs(t, err, bscript.ErrNotCLTVP2PKH)
+	})
+}
+
+func TestNewCSVP2PKHFromPubKeyHash(t *testing.T) {
+	t.Parallel()
+
+	pkh := crypto.Hash160(mustDecodeHex(t, testPubKey1))
+
+	s, err := bscript.NewCSVP2PKHFromPubKeyHash(144, pkh)
+	assert.NoError(t, err)
+	assertASM(t, s, "9000 OP_NOP3 OP_DROP OP_DUP OP_HASH160 "+hex.EncodeToString(pkh)+" OP_EQUALVERIFY OP_CHECKSIG")
+	assert.Equal(t, bscript.ScriptTypeCSVPubKeyHash, s.ScriptType())
+
+	sequence, pubKeyHash, err := s.CSVP2PKH()
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(144), sequence)
+	assert.Equal(t, pkh, pubKeyHash)
+
+	_, _, err = s.CLTVP2PKH()
+	assert.ErrorIs(t, err, bscript.ErrNotCLTVP2PKH)
+}
+
+func TestNewRPuzzle(t *testing.T) {
+	t.Parallel()
+
+	r := mustDecodeHex(t, "00e5d0d4a5d4e0c8f4c1b5f6d9f1b7d6e2c9a1d8c0b5e6f3a9d2c4b7e1f8a6c3d5")
+	const prefix = "OP_OVER OP_3 OP_SPLIT OP_NIP OP_TRUE OP_SPLIT OP_SWAP OP_SPLIT OP_DROP "
+
+	t.Run("R", func(t *testing.T) {
+		s, err := bscript.NewRPuzzleFromR(r)
+		assert.NoError(t, err)
+		assertASM(t, s, prefix+hex.EncodeToString(r)+" OP_EQUALVERIFY OP_CHECKSIG")
+		assert.Equal(t, bscript.ScriptTypeRPuzzle, s.ScriptType())
+
+		hashOp, value, err := s.RPuzzle()
+		assert.NoError(t, err)
+		assert.Equal(t, bscript.OpZERO, hashOp)
+		assert.Equal(t, r, value)
+	})
+
+	t.Run("hashed R", func(t *testing.T) {
+		rHash := crypto.Hash160(r)
+		s, err := bscript.NewRPuzzleFromRHash(bscript.OpHASH160, rHash)
+		assert.NoError(t, err)
+		assertASM(t, s, prefix+"OP_HASH160 "+hex.EncodeToString(rHash)+" OP_EQUALVERIFY OP_CHECKSIG")
+		assert.Equal(t, bscript.ScriptTypeRPuzzle, s.ScriptType())
+
+		hashOp, value, err := s.RPuzzle()
+		assert.NoError(t, err)
+		assert.Equal(t, bscript.OpHASH160, hashOp)
+		assert.Equal(t, rHash, value)
+	})
+
+	t.Run("spends", func(t *testing.T) {
+		pk, err := bec.NewPrivateKey(bec.S256())
+		assert.NoError(t, err)
+
+		// The R-puzzle is solved by a signature from any key using the same k.
+		curve := bec.S256()
+		k := big.NewInt(0xc0ffee)
+		rx, _ := curve.ScalarBaseMult(k.Bytes())
+		sigR := new(big.Int).Mod(rx, curve.N)
+		rBytes := sigR.Bytes()
+		if rBytes[0]&0x80 != 0 {
+			rBytes = append([]byte{0x00}, rBytes...)
+		}
+
+		lockingScript, err := bscript.NewRPuzzleFromRHash(bscript.OpHASH160, crypto.Hash160(rBytes))
+		assert.NoError(t, err)
+
+		tx := bt.NewTx()
+		assert.NoError(t, tx.From(
+			"45be95d2f2c64e99518ffbbce03fb15a7758f20ee5eecf0df07938d977add71d", 0, lockingScript.String(), 1000,
+		))
+		assert.NoError(t, tx.PayToAddress("1NRoySJ9Lvby6DuE2UQYnyT67AASwNZxGb", 900))
+
+		sh, err := tx.CalcInputSignatureHash(0, sighash.AllForkID)
+		assert.NoError(t, err)
+
+		// s = k^-1(z + rd) mod N, in its low form.
+		sigS := new(big.Int).Mul(sigR, pk.D)
+		sigS.Add(sigS, new(big.Int).SetBytes(sh))
+		sigS.Mul(sigS, new(big.Int).ModInverse(k, curve.N))
+		sigS.Mod(sigS, curve.N)
+		if sigS.Cmp(new(big.Int).Rsh(curve.N, 1)) > 0 {
+			sigS.Sub(curve.N, sigS)
+		}
+		sig := (&bec.Signature{R: sigR, S: sigS}).Serialise()
+
+		unlockingScript := &bscript.Script{}
+		assert.NoError(t, unlockingScript.AppendPushDataArray([][]byte{
+			append(sig, byte(sighash.AllForkID)), pk.PubKey().SerialiseCompressed(),
+		}))
+		tx.Inputs[0].UnlockingScript = unlockingScript
+
+		assert.NoError(t, interpreter.NewEngine().Execute(
+			interpreter.WithTx(tx, 0, &bt.Output{LockingScript: lockingScript, Satoshis: 1000}),
+			interpreter.WithForkID(),
+			interpreter.WithAfterGenesis(),
+		))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := bscript.NewRPuzzleFromRHash(bscript.OpSHA256, crypto.Hash160(r))
+		assert.ErrorIs(t, err, bscript.ErrInvalidRPuzzle)
+
+		_, err = bscript.NewRPuzzleFromRHash(bscript.OpDUP, crypto.Hash160(r))
+		assert.ErrorIs(t, err, bscript.ErrInvalidRPuzzle)
+
+		_, err = bscript.NewRPuzzleFromR(nil)
+		assert.ErrorIs(t, err, bscript.ErrInvalidRPuzzle)
+
+		p2pkh, err := bscript.NewP2PKHFromPubKeyHash(crypto.Hash160(r))
+		assert.NoError(t, err)
+		_, _, err = p2pkh.RPuzzle()
+		assert.ErrorIs(t, err, bscript.ErrNotRPuzzle)
+	})
+}
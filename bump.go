@@ -0,0 +1,395 @@
+package bt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+/*
+BUMP (BSV Unified Merkle Path) binary format
+--------------------------------------------------------
+Field            Description                                                               Size
+
+blockHeight      the height of the block the txs are in                                    1 - 9 bytes VI = VarInt
+
+treeHeight       the height of the merkle tree, and so the number of levels in the path    1 byte
+
+levels           for each level, the number of leaves as a VarInt followed by the leaves   <treeHeight>-many levels
+
+leaf             offset VarInt, flags byte (0 hash, 1 duplicate, 2 client txid)            1 - 42 bytes
+                 and a 32 byte hash, unless the leaf is a duplicate
+--------------------------------------------------------
+See https://brc.dev/74
+*/
+
+const (
+	bumpFlagHash      = 0x00
+	bumpFlagDuplicate = 0x01
+	bumpFlagTxID      = 0x02
+)
+
+// BUMP is a BSV Unified Merkle Path, proving the inclusion of one or more txs in
+// a block. Path holds the leaves needed at each level of the merkle tree, the
+// txids themselves at level 0 being flagged as such.
+//
+// The JSON encoding of this struct matches the BUMP JSON format.
+type BUMP struct {
+	BlockHeight uint64       `json:"blockHeight"`
+	Path        [][]BUMPLeaf `json:"path"`
+}
+
+// BUMPLeaf is a leaf within a level of a BUMP. Hash is hex encoded in display order.
+type BUMPLeaf struct {
+	Offset    uint64 `json:"offset"`
+	Hash      string `json:"hash,omitempty"`
+	TxID      bool   `json:"txid,omitempty"`
+	Duplicate bool   `json:"duplicate,omitempty"`
+}
+
+// NewBUMPFromString takes a hex string representation of a binary BUMP and
+// returns a BUMP object.
+func NewBUMPFromString(str string) (*BUMP, error) {
+	bb, err := hex.DecodeString(str)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewBUMPFromBytes(bb)
+}
+
+// NewBUMPFromBytes takes an array of bytes of a binary BUMP, constructs a
+// BUMP and returns it.
+func NewBUMPFromBytes(b []byte) (*BUMP, error) {
+	bump := &BUMP{}
+	n, err := bump.ReadFrom(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	if int(n) != len(b) {
+		return nil, fmt.Errorf("%w: read %d of %d bytes", ErrMerkleProofTrailingData, n, len(b))
+	}
+
+	return bump, nil
+}
+
+// ReadFrom reads a binary BUMP from the `io.Reader` into the `bt.BUMP`.
+func (b *BUMP) ReadFrom(r io.Reader) (int64, error) {
+	*b = BUMP{}
+	var bytesRead int64
+
+	var blockHeight VarInt
+	n64, err := blockHeight.ReadFrom(r)
+	bytesRead += n64
+	if err != nil {
+		return bytesRead, err
+	}
+	b.BlockHeight = uint64(blockHeight)
+
+	treeHeight := make([]byte, 1)
+	n, err := io.ReadFull(r, treeHeight)
+	bytesRead += int64(n)
+	if err != nil {
+		return bytesRead, errors.Wrapf(err, "treeHeight(1): got %d bytes", n)
+	}
+
+	b.Path = make([][]BUMPLeaf, treeHeight[0])
+	for h := range b.Path {
+		var nLeaves VarInt
+		n64, err = nLeaves.ReadFrom(r)
+		bytesRead += n64
+		if err != nil {
+			return bytesRead, err
+		}
+
+		b.Path[h] = make([]BUMPLeaf, 0)
+		for i := uint64(0); i < uint64(nLeaves); i++ {
+			var offset VarInt
+			n64, err = offset.ReadFrom(r)
+			bytesRead += n64
+			if err != nil {
+				return bytesRead, err
+			}
+
+			flags := make([]byte, 1)
+			n, err = io.ReadFull(r, flags)
+			bytesRead += int64(n)
+			if err != nil {
+				return bytesRead, errors.Wrapf(err, "flags(1): got %d bytes", n)
+			}
+
+			leaf := BUMPLeaf{Offset: uint64(offset)}
+			switch flags[0] {
+			case bumpFlagDuplicate:
+				leaf.Duplicate = true
+			case bumpFlagHash, bumpFlagTxID:
+				leaf.TxID = flags[0] == bumpFlagTxID
+
+				hash := make([]byte, 32)
+				n, err = io.ReadFull(r, hash)
+				bytesRead += int64(n)
+				if err != nil {
+					return bytesRead, errors.Wrapf(err, "hash(32): got %d bytes", n)
+				}
+				leaf.Hash = hex.EncodeToString(ReverseBytes(hash))
+			default:
+				return bytesRead, fmt.Errorf("%w: unknown leaf flags %x", ErrMerkleProofInvalid, flags[0])
+			}
+
+			b.Path[h] = append(b.Path[h], leaf)
+		}
+	}
+
+	return bytesRead, nil
+}
+
+// Bytes encodes the BUMP into its binary format.
+func (b *BUMP) Bytes() ([]byte, error) {
+	if len(b.Path) > 0xff {
+		return nil, fmt.Errorf("%w: tree height %d", ErrMerkleProofInvalid, len(b.Path))
+	}
+
+	h := VarInt(b.BlockHeight).Bytes()
+	h = append(h, byte(len(b.Path)))
+
+	for _, level := range b.Path {
+		h = append(h, VarInt(uint64(len(level))).Bytes()...)
+		for _, leaf := range level {
+			h = append(h, VarInt(leaf.Offset).Bytes()...)
+			if leaf.Duplicate {
+				h = append(h, bumpFlagDuplicate)
+				continue
+			}
+
+			hash, err := hex.DecodeString(leaf.Hash)
+			if err != nil {
+				return nil, errors.Wrapf(err, "leaf hash '%s'", leaf.Hash)
+			}
+			if len(hash) != 32 {
+				return nil, fmt.Errorf("%w: leaf hash '%s' is not 32 bytes", ErrMerkleProofInvalid, leaf.Hash)
+			}
+
+			if leaf.TxID {
+				h = append(h, bumpFlagTxID)
+			} else {
+				h = append(h, bumpFlagHash)
+			}
+			h = append(h, ReverseBytes(hash)...)
+		}
+	}
+
+	return h, nil
+}
+
+// String encodes the BUMP into its binary format as a hex string.
+func (b *BUMP) String() string {
+	bb, err := b.Bytes()
+	if err != nil {
+		return ""
+	}
+	return hex.EncodeToString(bb)
+}
+
+// TxIDs returns the txids flagged in the BUMP, as hex strings in display order.
+func (b *BUMP) TxIDs() []string {
+	txIDs := make([]string, 0)
+	if len(b.Path) == 0 {
+		return txIDs
+	}
+
+	for _, leaf := range b.Path[0] {
+		if leaf.TxID {
+			txIDs = append(txIDs, leaf.Hash)
+		}
+	}
+	return txIDs
+}
+
+// MerkleRoot calculates the merkle root for the provided txid, given in display
+// order, from the BUMP. The merkle root is returned in display order.
+//
+// If the txid is not found in the BUMP, or its leaf is not flagged as a txid, an
+// ErrMerkleTxNotFound error is returned.
+func (b *BUMP) MerkleRoot(txID []byte) ([]byte, error) {
+	if len(b.Path) == 0 {
+		return nil, fmt.Errorf("%w: empty path", ErrMerkleProofInvalid)
+	}
+
+	txIDStr := hex.EncodeToString(txID)
+
+	var offset uint64
+	found := false
+	for _, leaf := range b.Path[0] {
+		if leaf.TxID && leaf.Hash == txIDStr {
+			offset = leaf.Offset
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: %s", ErrMerkleTxNotFound, txIDStr)
+	}
+
+	// A block containing only the coinbase has the txid as its merkle root.
+	if len(b.Path) == 1 && len(b.Path[0]) == 1 {
+		return txID, nil
+	}
+
+	working := ReverseBytes(txID)
+	for h := range b.Path {
+		sibling, err := b.leafHash(h, offset^1, working)
+		if err != nil {
+			return nil, err
+		}
+
+		if offset&1 != 0 {
+			working = MerkleTreeParent(sibling, working)
+		} else {
+			working = MerkleTreeParent(working, sibling)
+		}
+		offset >>= 1
+	}
+
+	return ReverseBytes(working), nil
+}
+
+// Verify calculates the merkle root for the provided txid, given in display order,
+// and checks it against the provided block header.
+//
+// False is returned if the BUMP does not match the header, an error is only
+// returned if the BUMP is malformed or does not contain the txid, or if the
+// header is nil.
+func (b *BUMP) Verify(txID []byte, bh *BlockHeader) (bool, error) {
+	if bh == nil {
+		return false, ErrBlockHeaderNil
+	}
+
+	root, err := b.MerkleRoot(txID)
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(root, bh.MerkleRoot), nil
+}
+
+// leafHash returns the hash, in internal byte order, of the node at the given
+// level and offset. If the node is not held in the BUMP, it is calculated from
+// the level below. The working hash is returned for duplicate leaves.
+func (b *BUMP) leafHash(level int, offset uint64, working []byte) ([]byte, error) {
+	for _, leaf := range b.Path[level] {
+		if leaf.Offset != offset {
+			continue
+		}
+		if leaf.Duplicate {
+			return working, nil
+		}
+
+		hash, err := hex.DecodeString(leaf.Hash)
+		if err != nil {
+			return nil, errors.Wrapf(err, "leaf hash '%s'", leaf.Hash)
+		}
+		if len(hash) != 32 {
+			return nil, fmt.Errorf("%w: leaf hash '%s' is not 32 bytes", ErrMerkleProofInvalid, leaf.Hash)
+		}
+		return ReverseBytes(hash), nil
+	}
+
+	if level == 0 {
+		return nil, fmt.Errorf("%w: missing leaf at level 0, offset %d", ErrMerkleProofInvalid, offset)
+	}
+
+	left, err := b.leafHash(level-1, offset*2, nil)
+	if err != nil {
+		return nil, err
+	}
+	right, err := b.leafHash(level-1, offset*2+1, left)
+	if err != nil {
+		return nil, err
+	}
+
+	return MerkleTreeParent(left, right), nil
+}
+
+// BUMP builds a BUMP proving the inclusion of the txs at the provided indexes
+// against the merkle root of the txs.
+func (tt Txs) BUMP(blockHeight uint64, txIdxs ...int) (*BUMP, error) {
+	if len(tt) == 0 {
+		return nil, ErrMerkleNoTxs
+	}
+	if len(txIdxs) == 0 {
+		return nil, fmt.Errorf("%w: no tx indexes provided", ErrMerkleTxNotFound)
+	}
+
+	levels := merkleTreeLevels(tt.merkleLeaves())
+
+	known := make(map[int]struct{}, len(txIdxs))
+	for _, idx := range txIdxs {
+		if idx < 0 || idx >= len(tt) {
+			return nil, fmt.Errorf("%w: tx index %d of %d", ErrMerkleTxNotFound, idx, len(tt))
+		}
+		known[idx] = struct{}{}
+	}
+
+	height := len(levels) - 1
+	if height == 0 {
+		return &BUMP{
+			BlockHeight: blockHeight,
+			Path: [][]BUMPLeaf{{{
+				Hash: hex.EncodeToString(ReverseBytes(levels[0][0])),
+				TxID: true,
+			}}},
+		}, nil
+	}
+
+	bump := &BUMP{
+		BlockHeight: blockHeight,
+		Path:        make([][]BUMPLeaf, height),
+	}
+
+	for h := 0; h < height; h++ {
+		leaves := make(map[int]BUMPLeaf)
+		if h == 0 {
+			for offset := range known {
+				leaves[offset] = BUMPLeaf{
+					Offset: uint64(offset),
+					Hash:   hex.EncodeToString(ReverseBytes(levels[h][offset])),
+					TxID:   true,
+				}
+			}
+		}
+
+		parents := make(map[int]struct{}, len(known))
+		for offset := range known {
+			parents[offset>>1] = struct{}{}
+
+			sibling := offset ^ 1
+			if _, ok := known[sibling]; ok {
+				continue
+			}
+			if sibling >= len(levels[h]) {
+				leaves[sibling] = BUMPLeaf{Offset: uint64(sibling), Duplicate: true}
+				continue
+			}
+			leaves[sibling] = BUMPLeaf{
+				Offset: uint64(sibling),
+				Hash:   hex.EncodeToString(ReverseBytes(levels[h][sibling])),
+			}
+		}
+
+		bump.Path[h] = make([]BUMPLeaf, 0, len(leaves))
+		for _, leaf := range leaves {
+			bump.Path[h] = append(bump.Path[h], leaf)
+		}
+		sort.Slice(bump.Path[h], func(i, j int) bool {
+			return bump.Path[h][i].Offset < bump.Path[h][j].Offset
+		})
+
+		known = parents
+	}
+
+	return bump, nil
+}
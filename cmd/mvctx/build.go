@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+)
+
+func runBuild(e *env, args []string) error {
+	fs := newFlagSet(e, "build")
+	utxosPath := fs.String("utxos", "", "`file` of the JSON array of utxos to spend, or - for stdin")
+	var to, data listFlag
+	fs.Var(&to, "to", "an output paying `address=satoshis`, can be repeated")
+	fs.Var(&data, "data", "an OP_FALSE OP_RETURN output of the `hex` data, can be repeated")
+	change := fs.String("change", "", "`address` to pay any change to")
+	feesPath := fs.String("fees", "", "`file` of the fee quote JSON used for change, defaults to bt.NewFeeQuote()")
+	version := fs.Uint("version", 1, "tx `version`, 10 for MvcTxID txs")
+	lockTime := fs.Uint("locktime", 0, "tx `locktime`")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %q", fs.Args())
+	}
+	if *utxosPath == "" {
+		return errors.New("-utxos is required")
+	}
+
+	utxos, err := readUTXOs(e, *utxosPath)
+	if err != nil {
+		return err
+	}
+	fq, err := readFeeQuote(e, *feesPath)
+	if err != nil {
+		return err
+	}
+
+	tx := bt.NewTx()
+	tx.Version = uint32(*version)
+	tx.LockTime = uint32(*lockTime)
+	if err = tx.FromUTXOs(utxos...); err != nil {
+		return err
+	}
+
+	for _, t := range to {
+		addr, sats, ok := cut(t, "=")
+		if !ok {
+			return fmt.Errorf("invalid -to %q, expected address=satoshis", t)
+		}
+		satoshis, err := strconv.ParseUint(sats, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid -to %q: %w", t, err)
+		}
+		if err = tx.PayToAddress(addr, satoshis); err != nil {
+			return fmt.Errorf("invalid -to %q: %w", t, err)
+		}
+	}
+
+	for _, d := range data {
+		b, err := hex.DecodeString(d)
+		if err != nil {
+			return fmt.Errorf("invalid -data %q: %w", d, err)
+		}
+		if err = tx.AddOpReturnOutput(b); err != nil {
+			return err
+		}
+	}
+
+	if *change != "" {
+		s, err := bscript.NewP2PKHFromAddress(*change)
+		if err != nil {
+			return fmt.Errorf("invalid -change %q: %w", *change, err)
+		}
+		if err = tx.Change(s, fq); err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintln(e.stdout, hex.EncodeToString(tx.ExtendedBytes()))
+	return err
+}
+
+// cut slices s around the first instance of sep, as strings.Cut does.
+func cut(s, sep string) (before, after string, found bool) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
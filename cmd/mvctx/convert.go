@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/mvc-labs/mvc-lib-go"
+)
+
+func runConvert(e *env, args []string) error {
+	fs := newFlagSet(e, "convert")
+	to := fs.String("to", "extended", "`format` to convert to, standard or extended")
+	utxosPath := fs.String("utxos", "", "`file` of the JSON array of the utxos spent, needed to extend a standard tx")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	tx, err := readTx(e, fs.Args())
+	if err != nil {
+		return err
+	}
+
+	var b []byte
+	switch *to {
+	case "standard":
+		b = tx.Bytes()
+	case "extended":
+		if *utxosPath != "" {
+			utxos, err := readUTXOs(e, *utxosPath)
+			if err != nil {
+				return err
+			}
+			extend(tx, utxos)
+		}
+		for i, in := range tx.Inputs {
+			if in.PreviousTxScript == nil {
+				return fmt.Errorf("input %d: %w, provide the utxo it spends with -utxos", i, bt.ErrEmptyPreviousTxScript)
+			}
+		}
+		b = tx.ExtendedBytes()
+	default:
+		return fmt.Errorf("invalid -to %q, expected standard or extended", *to)
+	}
+
+	_, err = fmt.Fprintln(e.stdout, hex.EncodeToString(b))
+	return err
+}
+
+// extend sets the previous locking script and satoshis of each input of the tx
+// spending one of the utxos.
+func extend(tx *bt.Tx, utxos bt.UTXOs) {
+	for _, u := range utxos {
+		for _, in := range tx.Inputs {
+			if in.PreviousTxIDStr() != u.TxIDStr() || in.PreviousTxOutIndex != u.Vout {
+				continue
+			}
+			in.PreviousTxScript = u.LockingScript
+			in.PreviousTxSatoshis = u.Satoshis
+		}
+	}
+}
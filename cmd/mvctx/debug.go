@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+	"github.com/mvc-labs/mvc-lib-go/bscript/interpreter"
+	"github.com/mvc-labs/mvc-lib-go/bscript/interpreter/debug"
+)
+
+func runDebug(e *env, args []string) error {
+	fs := newFlagSet(e, "debug")
+	input := fs.Int("input", 0, "debug the input at `index` of the tx")
+	lock := fs.String("lock", "", "debug the locking script `asm` rather than a tx")
+	unlock := fs.String("unlock", "", "unlocking script `asm` to debug with -lock")
+	load := fs.String("load", "", "debug the JSON trace in `file`, as saved by -save, rather than a tx")
+	save := fs.String("save", "", "save the JSON trace of the execution to `file`, to debug elsewhere")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	oo := []interpreter.ExecutionOptionFunc{interpreter.WithForkID(), interpreter.WithAfterGenesis()}
+	switch {
+	case *load != "":
+		if fs.NArg() > 0 || *lock != "" {
+			return errors.New("a tx or -lock cannot be debugged along with -load")
+		}
+		var trace debug.Trace
+		if err := readJSON(e, *load, &trace); err != nil {
+			return err
+		}
+		s, err := debug.NewSessionFromTrace(&trace)
+		if err != nil {
+			return err
+		}
+		return s.Run(e.stdin, e.stdout)
+	case *lock != "":
+		if fs.NArg() > 0 {
+			return errors.New("a tx cannot be debugged along with -lock")
+		}
+		lockingScript, err := bscript.ParseASM(*lock)
+		if err != nil {
+			return fmt.Errorf("invalid -lock: %w", err)
+		}
+		unlockingScript, err := bscript.ParseASM(*unlock)
+		if err != nil {
+			return fmt.Errorf("invalid -unlock: %w", err)
+		}
+		oo = append(oo, interpreter.WithScripts(lockingScript, unlockingScript))
+	case fs.NArg() == 1 && fs.Arg(0) != "-":
+		// The tx cannot be read from stdin, as the debugger reads its commands
+		// from there.
+		tx, err := readTx(e, fs.Args())
+		if err != nil {
+			return err
+		}
+		if *input < 0 || *input >= len(tx.Inputs) {
+			return fmt.Errorf("tx has no input %d", *input)
+		}
+		in := tx.Inputs[*input]
+		if in.PreviousTxScript == nil {
+			return fmt.Errorf("input %d: %w, debug a tx in the extended format", *input, bt.ErrEmptyPreviousTxScript)
+		}
+		oo = append(oo, interpreter.WithTx(tx, *input, &bt.Output{
+			LockingScript: in.PreviousTxScript,
+			Satoshis:      in.PreviousTxSatoshis,
+		}))
+	default:
+		return errors.New("a tx, as an argument, or -lock is required")
+	}
+
+	s, err := debug.NewSession(oo...)
+	if err != nil {
+		return err
+	}
+	if *save != "" {
+		b, err := json.Marshal(s.Trace())
+		if err != nil {
+			return err
+		}
+		if err = os.WriteFile(*save, b, 0o600); err != nil {
+			return err
+		}
+	}
+
+	return s.Run(e.stdin, e.stdout)
+}
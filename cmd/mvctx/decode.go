@@ -0,0 +1,31 @@
+package main
+
+import (
+	"encoding/json"
+)
+
+func runDecode(e *env, args []string) error {
+	fs := newFlagSet(e, "decode")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	tx, err := readTx(e, fs.Args())
+	if err != nil {
+		return err
+	}
+
+	// The node JSON is extended with the MvcTxID, which is the txid of version 10
+	// txs, so that it can be compared against the legacy txid of any tx.
+	b, err := json.Marshal(tx.NodeJSON())
+	if err != nil {
+		return err
+	}
+	var decoded map[string]interface{}
+	if err = json.Unmarshal(b, &decoded); err != nil {
+		return err
+	}
+	decoded["mvctxid"] = tx.MvcTxID()
+
+	return writeJSON(e, decoded)
+}
@@ -0,0 +1,53 @@
+package main
+
+// feeEstimate is the output of the fee command.
+type feeEstimate struct {
+	Size        uint64 `json:"size"`
+	StdBytes    uint64 `json:"standardBytes"`
+	DataBytes   uint64 `json:"dataBytes"`
+	StdFee      uint64 `json:"standardFee"`
+	DataFee     uint64 `json:"dataFee"`
+	RequiredFee uint64 `json:"requiredFee"`
+	PaidFee     int64  `json:"paidFee"`
+	PaidEnough  bool   `json:"paidEnough"`
+}
+
+func runFee(e *env, args []string) error {
+	fs := newFlagSet(e, "fee")
+	feesPath := fs.String("fees", "", "`file` of the fee quote JSON, defaults to bt.NewFeeQuote()")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	fq, err := readFeeQuote(e, *feesPath)
+	if err != nil {
+		return err
+	}
+	tx, err := readTx(e, fs.Args())
+	if err != nil {
+		return err
+	}
+
+	// Unsigned inputs are estimated as P2PKH, so the estimate holds for a tx
+	// straight from the build command.
+	size, err := tx.EstimateSizeWithTypes()
+	if err != nil {
+		return err
+	}
+	fees, err := tx.EstimateFeesPaid(fq)
+	if err != nil {
+		return err
+	}
+	paid := int64(tx.TotalInputSatoshis()) - int64(tx.TotalOutputSatoshis())
+
+	return writeJSON(e, &feeEstimate{
+		Size:        size.TotalBytes,
+		StdBytes:    size.TotalStdBytes,
+		DataBytes:   size.TotalDataBytes,
+		StdFee:      fees.StdFeePaid,
+		DataFee:     fees.DataFeePaid,
+		RequiredFee: fees.TotalFeePaid,
+		PaidFee:     paid,
+		PaidEnough:  paid >= 0 && uint64(paid) >= fees.TotalFeePaid,
+	})
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/sighash"
+)
+
+// readTx reads a hex tx, in the standard or extended format, from the first
+// argument or, if there is none or it is "-", from stdin.
+func readTx(e *env, args []string) (*bt.Tx, error) {
+	if len(args) > 1 {
+		return nil, fmt.Errorf("unexpected arguments %q", args[1:])
+	}
+
+	var s string
+	if len(args) == 1 && args[0] != "-" {
+		s = args[0]
+	} else {
+		b, err := io.ReadAll(e.stdin)
+		if err != nil {
+			return nil, err
+		}
+		s = string(b)
+	}
+
+	tx, err := bt.NewTxFromString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("invalid tx: %w", err)
+	}
+
+	return tx, nil
+}
+
+// readJSON decodes the JSON file at the path, or stdin if the path is "-", into v.
+func readJSON(e *env, path string, v interface{}) error {
+	var r io.Reader = e.stdin
+	if path != "-" {
+		f, err := os.Open(path) //nolint:gosec // reading user supplied files is the point
+		if err != nil {
+			return err
+		}
+		defer func() { _ = f.Close() }()
+		r = f
+	}
+
+	if err := json.NewDecoder(r).Decode(v); err != nil {
+		return fmt.Errorf("invalid JSON in %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// readFeeQuote reads the fee quote JSON at the path, or returns the default fee
+// quote if the path is empty.
+func readFeeQuote(e *env, path string) (*bt.FeeQuote, error) {
+	if path == "" {
+		return bt.NewFeeQuote(), nil
+	}
+
+	fq := &bt.FeeQuote{}
+	if err := readJSON(e, path, fq); err != nil {
+		return nil, err
+	}
+
+	return fq, nil
+}
+
+// readUTXOs reads the JSON array of utxos, as marshalled by bt.UTXO, at the path.
+func readUTXOs(e *env, path string) (bt.UTXOs, error) {
+	var utxos bt.UTXOs
+	if err := readJSON(e, path, &utxos); err != nil {
+		return nil, err
+	}
+
+	return utxos, nil
+}
+
+// writeJSON writes v as indented JSON.
+func writeJSON(e *env, v interface{}) error {
+	enc := json.NewEncoder(e.stdout)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(v)
+}
+
+// parseSigHash parses a sighash type such as "ALL|FORKID", as formatted by
+// sighash.Flag, or a number such as "0x41".
+func parseSigHash(s string) (sighash.Flag, error) {
+	if n, err := strconv.ParseUint(s, 0, 8); err == nil {
+		return sighash.Flag(n), nil
+	}
+
+	var f sighash.Flag
+	for _, part := range strings.Split(strings.ToUpper(s), "|") {
+		switch part {
+		case "ALL":
+			f |= sighash.All
+		case "NONE":
+			f |= sighash.None
+		case "SINGLE":
+			f |= sighash.Single
+		case "ANYONECANPAY":
+			f |= sighash.AnyOneCanPay
+		case "FORKID":
+			f |= sighash.ForkID
+		default:
+			return 0, fmt.Errorf("invalid sighash type %q", s)
+		}
+	}
+
+	return f, nil
+}
+
+// listFlag is a flag which can be given more than once.
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
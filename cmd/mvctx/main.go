@@ -0,0 +1,135 @@
+// Command mvctx decodes, builds, signs and verifies MVC txs from the command line,
+// saving writing a throwaway program each time a tx needs inspecting or signing.
+//
+// Usage:
+//
+//	mvctx <command> [flags] [args]
+//
+// Txs are read as hex, in the standard or extended format, from the first argument
+// or, if there is none or it is "-", from stdin. Run `mvctx help` for the commands
+// and `mvctx <command> -h` for the flags of each.
+//
+// Example usage:
+//
+//	mvctx build -utxos utxos.json -to 1NRoySJ9Lvby6DuE2UQYnyT67AASwNZxGb=1000 \
+//	    -change 1NRoySJ9Lvby6DuE2UQYnyT67AASwNZxGb |
+//	    mvctx sign -wif KznvCNc6Yf4iztSThoMH6oHWzH9EgjfodKxmeuUGPq5DEX5maspS -extended |
+//	    mvctx verify
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// env holds the streams a command reads from and writes to.
+type env struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// command is a subcommand of mvctx.
+type command struct {
+	usage   string
+	summary string
+	run     func(e *env, args []string) error
+}
+
+// commands are the subcommands of mvctx by name. They are set in init, as each
+// refers back to its own usage.
+var commands map[string]*command
+
+func init() {
+	commands = map[string]*command{
+		"decode": {
+			usage:   "decode [flags] [tx]",
+			summary: "decode a tx into the node's JSON format",
+			run:     runDecode,
+		},
+		"build": {
+			usage:   "build [flags]",
+			summary: "build an unsigned tx, in the extended format, from utxos and outputs",
+			run:     runBuild,
+		},
+		"sign": {
+			usage:   "sign [flags] [tx]",
+			summary: "sign the inputs of an extended tx with a WIF or xprv",
+			run:     runSign,
+		},
+		"fee": {
+			usage:   "fee [flags] [tx]",
+			summary: "estimate the size and fees of a tx against a fee quote",
+			run:     runFee,
+		},
+		"verify": {
+			usage:   "verify [flags] [tx]",
+			summary: "verify the scripts of an extended tx, optionally tracing execution",
+			run:     runVerify,
+		},
+		"debug": {
+			usage:   "debug [flags] [tx]",
+			summary: "step through the scripts of an input of an extended tx, or of raw scripts",
+			run:     runDebug,
+		},
+		"convert": {
+			usage:   "convert [flags] [tx]",
+			summary: "convert a tx between the standard and extended formats",
+			run:     runConvert,
+		},
+	}
+}
+
+func main() {
+	e := &env{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr}
+	if err := run(e, os.Args[1:]); err != nil {
+		fmt.Fprintf(e.stderr, "mvctx: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+// run runs the command named by the first argument.
+func run(e *env, args []string) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(e.stdout)
+		return nil
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		printUsage(e.stderr)
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+
+	return cmd.run(e, args[1:])
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: mvctx <command> [flags] [args]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-8s %s\n", name, commands[name].summary)
+	}
+}
+
+// newFlagSet returns a flag set for the command, writing its usage to stderr.
+func newFlagSet(e *env, name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(e.stderr, "Usage: mvctx %s\n\n%s.\n\nFlags:\n", commands[name].usage, commands[name].summary)
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+	"github.com/mvc-labs/mvc-lib-go/keys/bec"
+	"github.com/mvc-labs/mvc-lib-go/keys/bip32"
+	"github.com/mvc-labs/mvc-lib-go/keys/chaincfg"
+	"github.com/mvc-labs/mvc-lib-go/keys/wif"
+	"github.com/mvc-labs/mvc-lib-go/sighash"
+	"github.com/stretchr/testify/assert"
+)
+
+const testWIF = "KznvCNc6Yf4iztSThoMH6oHWzH9EgjfodKxmeuUGPq5DEX5maspS"
+
+// runCmd runs mvctx with the args and stdin, returning its stdout.
+func runCmd(t *testing.T, stdin string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	err := run(&env{stdin: strings.NewReader(stdin), stdout: &stdout, stderr: &stderr}, args)
+	return stdout.String(), err
+}
+
+func writeUTXOs(t *testing.T) string {
+	w, err := wif.DecodeWIF(testWIF)
+	assert.NoError(t, err)
+	s, err := bscript.NewP2PKHFromPubKeyEC(w.PrivKey.PubKey())
+	assert.NoError(t, err)
+
+	b, err := json.Marshal(bt.UTXOs{{
+		TxID:          mustDecodeHex("11b476ad8e0a48fcd40807a111a050af51114877e09283bfa7f3505081a1819d"),
+		Vout:          0,
+		LockingScript: s,
+		Satoshis:      10000,
+	}})
+	assert.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "utxos.json")
+	assert.NoError(t, os.WriteFile(path, b, 0o600))
+	return path
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	utxos := writeUTXOs(t)
+
+	unsigned, err := runCmd(t, "", "build",
+		"-utxos", utxos,
+		"-to", "1NRoySJ9Lvby6DuE2UQYnyT67AASwNZxGb=1000",
+		"-data", "68656c6c6f",
+		"-change", "1NRoySJ9Lvby6DuE2UQYnyT67AASwNZxGb",
+		"-version", "10",
+	)
+	assert.NoError(t, err)
+
+	tx, err := bt.NewTxFromString(strings.TrimSpace(unsigned))
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(10), tx.Version)
+	assert.Len(t, tx.Outputs, 3)
+	assert.Equal(t, uint64(10000), tx.Inputs[0].PreviousTxSatoshis)
+
+	_, err = runCmd(t, unsigned, "verify")
+	assert.ErrorIs(t, err, errInvalidTx)
+
+	signed, err := runCmd(t, unsigned, "sign", "-wif", testWIF, "-extended")
+	assert.NoError(t, err)
+
+	t.Run("verify", func(t *testing.T) {
+		out, err := runCmd(t, signed, "verify")
+		assert.NoError(t, err)
+		assert.Equal(t, "input 0: ok\n", out)
+
+		out, err = runCmd(t, "", "verify", "-trace", strings.TrimSpace(signed))
+		assert.NoError(t, err)
+		assert.Contains(t, out, "input 0 script 1 op 4: OP_CHECKSIG")
+		assert.Contains(t, out, "input 0 end: [01]")
+		assert.True(t, strings.HasSuffix(out, "input 0: ok\n"))
+	})
+
+	t.Run("debug", func(t *testing.T) {
+		out, err := runCmd(t, "bp OP_CHECKSIG\nc\nc\n", "debug", strings.TrimSpace(signed))
+		assert.NoError(t, err)
+		assert.Contains(t, out, "script 1 op 4: OP_CHECKSIG")
+		assert.Contains(t, out, "finished after 7 steps: success")
+
+		out, err = runCmd(t, "c\n", "debug", "-lock", "OP_2 OP_EQUAL", "-unlock", "2")
+		assert.NoError(t, err)
+		assert.Contains(t, out, "finished after 3 steps: success")
+
+		path := filepath.Join(t.TempDir(), "trace.json")
+		_, err = runCmd(t, "", "debug", "-save", path, strings.TrimSpace(signed))
+		assert.NoError(t, err)
+		out, err = runCmd(t, "bp OP_CHECKSIG\nc\nstack\n", "debug", "-load", path)
+		assert.NoError(t, err)
+		assert.Contains(t, out, "script 1 op 4: OP_CHECKSIG")
+		assert.Contains(t, out, "  0: 02")
+
+		_, err = runCmd(t, "", "debug", "-input", "1", strings.TrimSpace(signed))
+		assert.Error(t, err)
+		_, err = runCmd(t, signed, "debug")
+		assert.Error(t, err)
+	})
+
+	t.Run("decode", func(t *testing.T) {
+		out, err := runCmd(t, signed, "decode")
+		assert.NoError(t, err)
+
+		signedTx, err := bt.NewTxFromString(strings.TrimSpace(signed))
+		assert.NoError(t, err)
+
+		var decoded map[string]interface{}
+		assert.NoError(t, json.Unmarshal([]byte(out), &decoded))
+		assert.Equal(t, signedTx.MvcTxID(), decoded["mvctxid"])
+		assert.Equal(t, signedTx.MvcTxID(), decoded["txid"])
+		assert.Len(t, decoded["vout"], 3)
+	})
+
+	t.Run("convert", func(t *testing.T) {
+		standard, err := runCmd(t, signed, "convert", "-to", "standard")
+		assert.NoError(t, err)
+		assert.Less(t, len(standard), len(signed))
+
+		_, err = runCmd(t, standard, "convert")
+		assert.ErrorIs(t, err, bt.ErrEmptyPreviousTxScript)
+
+		extended, err := runCmd(t, standard, "convert", "-utxos", utxos)
+		assert.NoError(t, err)
+		assert.Equal(t, signed, extended)
+	})
+
+	t.Run("fee", func(t *testing.T) {
+		out, err := runCmd(t, signed, "fee")
+		assert.NoError(t, err)
+
+		var fee feeEstimate
+		assert.NoError(t, json.Unmarshal([]byte(out), &fee))
+		assert.True(t, fee.PaidEnough)
+		assert.Greater(t, fee.DataBytes, uint64(0))
+		assert.Equal(t, fee.StdFee+fee.DataFee, fee.RequiredFee)
+	})
+}
+
+func TestRun_SignByKey(t *testing.T) {
+	t.Parallel()
+
+	w1, err := wif.DecodeWIF(testWIF)
+	assert.NoError(t, err)
+	pk2, err := bec.NewPrivateKey(bec.S256())
+	assert.NoError(t, err)
+	w2, err := wif.NewWIF(pk2, &chaincfg.MainNet, true)
+	assert.NoError(t, err)
+
+	utxos := make(bt.UTXOs, 0, 2)
+	for i, pk := range []*bec.PrivateKey{w1.PrivKey, pk2} {
+		s, err := bscript.NewP2PKHFromPubKeyEC(pk.PubKey())
+		assert.NoError(t, err)
+		utxos = append(utxos, &bt.UTXO{
+			TxID:          mustDecodeHex("11b476ad8e0a48fcd40807a111a050af51114877e09283bfa7f3505081a1819d"),
+			Vout:          uint32(i),
+			LockingScript: s,
+			Satoshis:      10000,
+		})
+	}
+	b, err := json.Marshal(utxos)
+	assert.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "utxos.json")
+	assert.NoError(t, os.WriteFile(path, b, 0o600))
+
+	unsigned, err := runCmd(t, "", "build",
+		"-utxos", path,
+		"-to", "1NRoySJ9Lvby6DuE2UQYnyT67AASwNZxGb=15000",
+		"-change", "1NRoySJ9Lvby6DuE2UQYnyT67AASwNZxGb",
+	)
+	assert.NoError(t, err)
+
+	var stderr bytes.Buffer
+	e := &env{stdin: strings.NewReader(unsigned), stdout: &bytes.Buffer{}, stderr: &stderr}
+	assert.NoError(t, run(e, []string{"sign", "-wif", testWIF, "-extended"}))
+	partial := e.stdout.(*bytes.Buffer).String()
+	assert.Equal(t, "signed inputs 0 of 2\n", stderr.String())
+
+	_, err = runCmd(t, partial, "verify")
+	assert.ErrorIs(t, err, errInvalidTx)
+
+	// The first key owns no unsigned inputs left.
+	_, err = runCmd(t, partial, "sign", "-wif", testWIF)
+	assert.Error(t, err)
+
+	stderr.Reset()
+	e = &env{stdin: strings.NewReader(partial), stdout: &bytes.Buffer{}, stderr: &stderr}
+	assert.NoError(t, run(e, []string{"sign", "-wif", w2.String(), "-extended"}))
+	assert.Equal(t, "signed inputs 1 of 2\n", stderr.String())
+
+	out, err := runCmd(t, e.stdout.(*bytes.Buffer).String(), "verify")
+	assert.NoError(t, err)
+	assert.Equal(t, "input 0: ok\ninput 1: ok\n", out)
+
+	t.Run("input flag", func(t *testing.T) {
+		_, err := runCmd(t, unsigned, "sign", "-wif", testWIF, "-input", "2")
+		assert.Error(t, err)
+
+		signed, err := runCmd(t, unsigned, "sign", "-wif", w2.String(), "-input", "1", "-extended")
+		assert.NoError(t, err)
+		tx, err := bt.NewTxFromString(strings.TrimSpace(signed))
+		assert.NoError(t, err)
+		assert.Empty(t, *tx.Inputs[0].UnlockingScript)
+		assert.NotEmpty(t, *tx.Inputs[1].UnlockingScript)
+	})
+}
+
+func TestRun_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := runCmd(t, "", "nope")
+	assert.Error(t, err)
+
+	out, err := runCmd(t, "", "help")
+	assert.NoError(t, err)
+	assert.Contains(t, out, "decode")
+
+	_, err = runCmd(t, "zz", "decode")
+	assert.Error(t, err)
+
+	_, err = runCmd(t, "", "build")
+	assert.Error(t, err)
+
+	_, err = runCmd(t, "", "sign", "-wif", testWIF, "-xprv", "xprv")
+	assert.Error(t, err)
+}
+
+func TestParseSigHash(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		s      string
+		exp    sighash.Flag
+		expErr bool
+	}{
+		"name":           {s: "ALL|FORKID", exp: sighash.AllForkID},
+		"lower case":     {s: "single|anyonecanpay|forkid", exp: sighash.SingleForkID | sighash.AnyOneCanPay},
+		"number":         {s: "0x41", exp: sighash.AllForkID},
+		"unknown name":   {s: "ALL|NOPE", expErr: true},
+		"number too big": {s: "0x100", expErr: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			f, err := parseSigHash(test.s)
+			if test.expErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.exp, f)
+		})
+	}
+}
+
+func mustDecodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestSigningKey(t *testing.T) {
+	t.Parallel()
+
+	master, err := bip32.NewMaster(bytes.Repeat([]byte{0x01}, 32), &chaincfg.MainNet)
+	assert.NoError(t, err)
+	child, err := master.DeriveChildFromPath("0/1")
+	assert.NoError(t, err)
+	exp, err := child.ECPrivKey()
+	assert.NoError(t, err)
+
+	for _, path := range []string{"m/0/1", "0/1"} {
+		k, err := signingKey("", master.String(), path)
+		assert.NoError(t, err)
+		assert.Equal(t, exp.Serialise(), k.Serialise())
+	}
+
+	_, err = signingKey("", master.String(), "m/x")
+	assert.Error(t, err)
+	_, err = signingKey("", "", "")
+	assert.Error(t, err)
+}
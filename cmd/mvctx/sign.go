@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/keys/bec"
+	"github.com/mvc-labs/mvc-lib-go/keys/bip32"
+	"github.com/mvc-labs/mvc-lib-go/keys/crypto"
+	"github.com/mvc-labs/mvc-lib-go/keys/wif"
+	"github.com/mvc-labs/mvc-lib-go/unlocker"
+)
+
+func runSign(e *env, args []string) error {
+	fs := newFlagSet(e, "sign")
+	wifKey := fs.String("wif", "", "`WIF` of the private key to sign with")
+	xprv := fs.String("xprv", "", "`xprv` to derive the private key to sign with from")
+	path := fs.String("path", "", "bip32 `path` of the private key under -xprv, such as m/0/1")
+	sigHash := fs.String("sighash", "ALL|FORKID", "sighash `type` to sign with")
+	extended := fs.Bool("extended", false, "output the signed tx in the extended format")
+	var inputs listFlag
+	fs.Var(&inputs, "input", "sign only the input at `index`, can be repeated, otherwise every unsigned input the key owns is signed")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	privKey, err := signingKey(*wifKey, *xprv, *path)
+	if err != nil {
+		return err
+	}
+	flag, err := parseSigHash(*sigHash)
+	if err != nil {
+		return err
+	}
+
+	tx, err := readTx(e, fs.Args())
+	if err != nil {
+		return err
+	}
+
+	idxs, err := inputsToSign(tx, privKey, inputs)
+	if err != nil {
+		return err
+	}
+	if len(idxs) == 0 {
+		return errors.New("the key owns no unsigned inputs, use -input to sign an input regardless")
+	}
+
+	ug := &unlocker.Getter{PrivateKey: privKey}
+	signed := make([]string, 0, len(idxs))
+	for _, i := range idxs {
+		u, err := ug.Unlocker(context.Background(), tx.Inputs[i].PreviousTxScript)
+		if err != nil {
+			return fmt.Errorf("input %d: %w", i, err)
+		}
+		if err = tx.FillInput(context.Background(), u, bt.UnlockerParams{
+			InputIdx:     uint32(i),
+			SigHashFlags: flag,
+		}); err != nil {
+			return fmt.Errorf("input %d: %w", i, err)
+		}
+		signed = append(signed, strconv.Itoa(i))
+	}
+	fmt.Fprintf(e.stderr, "signed inputs %s of %d\n", strings.Join(signed, ", "), tx.InputCount())
+
+	b := tx.Bytes()
+	if *extended {
+		b = tx.ExtendedBytes()
+	}
+	_, err = fmt.Fprintln(e.stdout, hex.EncodeToString(b))
+	return err
+}
+
+// inputsToSign returns the indexes of the inputs to sign. These are the inputs given
+// by -input or, if there are none, each unsigned input whose previous locking script
+// holds the public key of the private key, or its hash.
+func inputsToSign(tx *bt.Tx, privKey *bec.PrivateKey, inputs []string) ([]int, error) {
+	for i, in := range tx.Inputs {
+		if in.PreviousTxScript == nil {
+			return nil, fmt.Errorf("input %d: %w, sign a tx in the extended format", i, bt.ErrEmptyPreviousTxScript)
+		}
+	}
+
+	idxs := make([]int, 0, tx.InputCount())
+	if len(inputs) > 0 {
+		for _, s := range inputs {
+			i, err := strconv.Atoi(s)
+			if err != nil || i < 0 || i >= tx.InputCount() {
+				return nil, fmt.Errorf("invalid -input %q, the tx has %d inputs", s, tx.InputCount())
+			}
+			idxs = append(idxs, i)
+		}
+		return idxs, nil
+	}
+
+	pubKeys := [][]byte{privKey.PubKey().SerialiseCompressed(), privKey.PubKey().SerialiseUncompressed()}
+	for i, in := range tx.Inputs {
+		if in.UnlockingScript != nil && len(*in.UnlockingScript) > 0 {
+			continue
+		}
+		for _, pk := range pubKeys {
+			if bytes.Contains(*in.PreviousTxScript, pk) || bytes.Contains(*in.PreviousTxScript, crypto.Hash160(pk)) {
+				idxs = append(idxs, i)
+				break
+			}
+		}
+	}
+
+	return idxs, nil
+}
+
+// signingKey returns the private key of the WIF or, if not given, the private key
+// at the path under the xprv.
+func signingKey(wifKey, xprv, path string) (*bec.PrivateKey, error) {
+	switch {
+	case wifKey != "" && xprv != "":
+		return nil, errors.New("only one of -wif and -xprv can be given")
+	case wifKey != "":
+		w, err := wif.DecodeWIF(wifKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -wif: %w", err)
+		}
+		return w.PrivKey, nil
+	case xprv != "":
+		k, err := bip32.NewKeyFromString(xprv)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -xprv: %w", err)
+		}
+		path = strings.TrimPrefix(strings.TrimPrefix(path, "m"), "/")
+		if k, err = k.DeriveChildFromPath(path); err != nil {
+			return nil, fmt.Errorf("invalid -path: %w", err)
+		}
+		return k.ECPrivKey()
+	}
+
+	return nil, errors.New("one of -wif or -xprv is required")
+}
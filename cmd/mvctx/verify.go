@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/bscript/interpreter"
+	"github.com/mvc-labs/mvc-lib-go/bscript/interpreter/debug"
+)
+
+// errInvalidTx is returned by the verify command for a tx failing verification,
+// once the failures have been reported.
+var errInvalidTx = errors.New("tx is invalid")
+
+func runVerify(e *env, args []string) error {
+	fs := newFlagSet(e, "verify")
+	trace := fs.Bool("trace", false, "print each opcode executed along with the stack before it")
+	input := fs.Int("input", -1, "verify only the input at `index`")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	tx, err := readTx(e, fs.Args())
+	if err != nil {
+		return err
+	}
+	if *input >= len(tx.Inputs) {
+		return fmt.Errorf("tx has no input %d", *input)
+	}
+
+	if !*trace && *input < 0 {
+		report, err := interpreter.VerifyTx(context.Background(), tx)
+		if err != nil {
+			return err
+		}
+		for i, err := range report.Inputs {
+			printInputResult(e, i, err)
+		}
+		if report.ValueErr != nil {
+			fmt.Fprintf(e.stdout, "value: %s\n", report.ValueErr)
+		}
+		if !report.Valid() {
+			return errInvalidTx
+		}
+		return nil
+	}
+
+	valid := true
+	for i, in := range tx.Inputs {
+		if *input >= 0 && i != *input {
+			continue
+		}
+		if in.PreviousTxScript == nil {
+			return fmt.Errorf("input %d: %w, verify a tx in the extended format", i, bt.ErrEmptyPreviousTxScript)
+		}
+
+		oo := []interpreter.ExecutionOptionFunc{
+			interpreter.WithTx(tx, i, &bt.Output{LockingScript: in.PreviousTxScript, Satoshis: in.PreviousTxSatoshis}),
+			interpreter.WithForkID(),
+			interpreter.WithAfterGenesis(),
+		}
+		if *trace {
+			oo = append(oo, interpreter.WithDebugger(newTracer(e, i)))
+		}
+
+		err := interpreter.NewEngine().Execute(oo...)
+		printInputResult(e, i, err)
+		valid = valid && err == nil
+	}
+	if !valid {
+		return errInvalidTx
+	}
+
+	return nil
+}
+
+func printInputResult(e *env, i int, err error) {
+	if err != nil {
+		fmt.Fprintf(e.stdout, "input %d: %s\n", i, err)
+		return
+	}
+	fmt.Fprintf(e.stdout, "input %d: ok\n", i)
+}
+
+// newTracer returns a debugger printing each opcode executed for the input, along
+// with the stack it is executed against, and the final stack.
+func newTracer(e *env, input int) debug.DefaultDebugger {
+	d := debug.NewDebugger()
+	d.AttachBeforeExecuteOpcode(func(state *interpreter.State) {
+		fmt.Fprintf(e.stdout, "input %d script %d op %d: %-24s [%s]\n",
+			input, state.ScriptIdx, state.OpcodeIdx, formatOpcode(state.Opcode()), formatStack(state.DataStack))
+	})
+	d.AttachAfterExecute(func(state *interpreter.State) {
+		fmt.Fprintf(e.stdout, "input %d end: [%s]\n", input, formatStack(state.DataStack))
+	})
+
+	return d
+}
+
+// formatOpcode returns the name of the opcode, followed by its data, if any.
+func formatOpcode(op interpreter.ParsedOpcode) string {
+	if len(op.Data) == 0 {
+		return op.Name()
+	}
+	return op.Name() + " " + hex.EncodeToString(op.Data)
+}
+
+// formatStack returns the stack items, bottom first, as hex.
+func formatStack(stack [][]byte) string {
+	ss := make([]string, len(stack))
+	for i, item := range stack {
+		ss[i] = hex.EncodeToString(item)
+	}
+	return strings.Join(ss, " ")
+}
@@ -0,0 +1,45 @@
+package coinselect
+
+import (
+	"context"
+	"sort"
+
+	"github.com/mvc-labs/mvc-lib-go"
+)
+
+// LargestFirst implements the `bt.CoinSelector` interface. It selects the utxos with
+// the most satoshis first, minimising the number of inputs and so the fee paid, at
+// the cost of consolidating fewer utxos.
+type LargestFirst struct{}
+
+// SelectCoins selects utxos, largest first, until the tx is funded.
+func (l *LargestFirst) SelectCoins(ctx context.Context, ft *bt.FundingTarget, utxos bt.UTXOs) (bt.UTXOs, error) {
+	cc, err := candidates(ctx, ft, utxos)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(cc, func(i, j int) bool {
+		return cc[i].utxo.Satoshis > cc[j].utxo.Satoshis
+	})
+
+	return accumulate(ctx, ft, cc)
+}
+
+// SmallestFirst implements the `bt.CoinSelector` interface. It selects the utxos with
+// the fewest satoshis first, consolidating small utxos at the cost of a larger fee.
+type SmallestFirst struct{}
+
+// SelectCoins selects utxos, smallest first, until the tx is funded.
+func (s *SmallestFirst) SelectCoins(ctx context.Context, ft *bt.FundingTarget, utxos bt.UTXOs) (bt.UTXOs, error) {
+	cc, err := candidates(ctx, ft, utxos)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(cc, func(i, j int) bool {
+		return cc[i].utxo.Satoshis < cc[j].utxo.Satoshis
+	})
+
+	return accumulate(ctx, ft, cc)
+}
@@ -0,0 +1,129 @@
+package coinselect
+
+import (
+	"context"
+	"sort"
+
+	"github.com/mvc-labs/mvc-lib-go"
+)
+
+// defaultMaxTries is the default number of selections evaluated by BranchAndBound.
+const defaultMaxTries = 100000
+
+// BranchAndBound implements the `bt.CoinSelector` interface. It searches for a selection
+// of utxos which funds the tx exactly, or closely enough that there is nothing left over
+// for a change output, so avoiding creating change altogether.
+//
+// Of the selections found, the one wasting the fewest satoshis to the fee is returned. If
+// no such selection exists, a coinselect.ErrNoExactMatch is returned, in which case another
+// strategy should be used instead.
+type BranchAndBound struct {
+	// MaxTries the maximum number of selections to evaluate. [DEFAULT 100000]
+	MaxTries int
+}
+
+// SelectCoins searches for a selection of utxos funding the tx without change.
+func (b *BranchAndBound) SelectCoins(ctx context.Context, ft *bt.FundingTarget, utxos bt.UTXOs) (bt.UTXOs, error) {
+	if ft.Required(0, 0) == 0 {
+		return bt.UTXOs{}, nil
+	}
+
+	cc, err := candidates(ctx, ft, utxos)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(cc, func(i, j int) bool {
+		return cc[i].utxo.Satoshis > cc[j].utxo.Satoshis
+	})
+
+	// remaining[i] holds the total satoshis of the candidates from i onwards.
+	remaining := make([]uint64, len(cc)+1)
+	var allBytes uint64
+	for i := len(cc) - 1; i >= 0; i-- {
+		remaining[i] = remaining[i+1] + cc[i].utxo.Satoshis
+		allBytes += cc[i].size
+	}
+	if remaining[0] < ft.Required(len(cc), allBytes) {
+		return nil, bt.ErrInsufficientFunds
+	}
+
+	s := &bnbSearch{
+		ft:        ft,
+		cc:        cc,
+		remaining: remaining,
+		maxTries:  b.MaxTries,
+		selected:  make([]int, 0, len(cc)),
+	}
+	if s.maxTries <= 0 {
+		s.maxTries = defaultMaxTries
+	}
+	if err = s.search(ctx, 0, 0, 0); err != nil {
+		return nil, err
+	}
+	if s.best == nil {
+		return nil, ErrNoExactMatch
+	}
+
+	selection := make(bt.UTXOs, 0, len(s.best))
+	for _, i := range s.best {
+		selection = append(selection, cc[i].utxo)
+	}
+
+	return selection, nil
+}
+
+// bnbSearch holds the state of a depth first search over the inclusion, then exclusion,
+// of each candidate.
+type bnbSearch struct {
+	ft        *bt.FundingTarget
+	cc        []candidate
+	remaining []uint64
+	maxTries  int
+	tries     int
+
+	selected  []int
+	best      []int
+	bestWaste uint64
+}
+
+// search explores the selections of the candidates from i onwards, given the current
+// selection totalling total satoshis and inputBytes in size. It returns early once the
+// try limit is hit or an exact match is found.
+func (s *bnbSearch) search(ctx context.Context, i int, total, inputBytes uint64) error {
+	s.tries++
+	if s.tries > s.maxTries || (s.best != nil && s.bestWaste == 0) {
+		return nil
+	}
+	if s.tries%1000 == 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+
+	required := s.ft.Required(len(s.selected), inputBytes)
+	if total >= required {
+		// Selecting more would only add to the excess, so this branch ends here.
+		if total <= s.ft.MaxWithoutChange(len(s.selected), inputBytes) {
+			if waste := total - required; s.best == nil || waste < s.bestWaste {
+				s.best = append(s.best[:0], s.selected...)
+				s.bestWaste = waste
+			}
+		}
+		return nil
+	}
+
+	// Prune branches which can no longer fund the tx, even if every remaining
+	// candidate were selected.
+	if i == len(s.cc) || total+s.remaining[i] < required {
+		return nil
+	}
+
+	s.selected = append(s.selected, i)
+	if err := s.search(ctx, i+1, total+s.cc[i].utxo.Satoshis, inputBytes+s.cc[i].size); err != nil {
+		return err
+	}
+	s.selected = s.selected[:len(s.selected)-1]
+
+	return s.search(ctx, i+1, total, inputBytes)
+}
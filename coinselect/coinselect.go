@@ -0,0 +1,60 @@
+// Package coinselect provides strategies for choosing which utxos to fund a tx with,
+// implementing the `bt.CoinSelector` interface for use with tx.FundWithSelector(...).
+package coinselect
+
+import (
+	"context"
+
+	"github.com/mvc-labs/mvc-lib-go"
+)
+
+// candidate is a utxo alongside the estimated size of the input spending it.
+type candidate struct {
+	utxo *bt.UTXO
+	size uint64
+}
+
+// candidates pairs each utxo with the estimated size of its input. Utxos worth no
+// more than the fee of spending them are left out, as selecting them would only
+// increase what needs funded.
+func candidates(ctx context.Context, ft *bt.FundingTarget, utxos bt.UTXOs) ([]candidate, error) {
+	baseFee := ft.Fee(0, 0, false)
+
+	cc := make([]candidate, 0, len(utxos))
+	for _, u := range utxos {
+		size, err := ft.InputSize(ctx, u)
+		if err != nil {
+			return nil, err
+		}
+		if u.Satoshis <= ft.Fee(1, size, false)-baseFee {
+			continue
+		}
+		cc = append(cc, candidate{utxo: u, size: size})
+	}
+
+	return cc, nil
+}
+
+// accumulate selects candidates in the order given until the tx is funded.
+func accumulate(ctx context.Context, ft *bt.FundingTarget, cc []candidate) (bt.UTXOs, error) {
+	selected := make(bt.UTXOs, 0)
+	var total, inputBytes uint64
+	for _, c := range cc {
+		if total >= ft.Required(len(selected), inputBytes) {
+			return selected, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		selected = append(selected, c.utxo)
+		total += c.utxo.Satoshis
+		inputBytes += c.size
+	}
+
+	if total < ft.Required(len(selected), inputBytes) {
+		return nil, bt.ErrInsufficientFunds
+	}
+
+	return selected, nil
+}
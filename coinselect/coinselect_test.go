@@ -0,0 +1,176 @@
+package coinselect_test
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+	"github.com/mvc-labs/mvc-lib-go/coinselect"
+	"github.com/stretchr/testify/assert"
+)
+
+const testAddress = "1NRoySJ9Lvby6DuE2UQYnyT67AASwNZxGb"
+
+func testUTXOs(t *testing.T, sats ...uint64) bt.UTXOs {
+	s, err := bscript.NewP2PKHFromAddress(testAddress)
+	assert.NoError(t, err)
+
+	utxos := make(bt.UTXOs, 0, len(sats))
+	for i, sat := range sats {
+		utxos = append(utxos, &bt.UTXO{
+			TxID:          make([]byte, 32),
+			Vout:          uint32(i),
+			LockingScript: s,
+			Satoshis:      sat,
+		})
+	}
+	return utxos
+}
+
+func testTx(t *testing.T, sats uint64) *bt.Tx {
+	tx := bt.NewTx()
+	assert.NoError(t, tx.PayToAddress(testAddress, sats))
+	return tx
+}
+
+func selectedSats(utxos bt.UTXOs) []uint64 {
+	sats := make([]uint64, 0, len(utxos))
+	for _, u := range utxos {
+		sats = append(sats, u.Satoshis)
+	}
+	return sats
+}
+
+func TestLargestFirst(t *testing.T) {
+	t.Parallel()
+
+	tx := testTx(t, 5000)
+	sel, err := tx.FundWithSelector(context.Background(), bt.NewFeeQuote(),
+		testUTXOs(t, 1000, 4000, 3000, 2000), &coinselect.LargestFirst{})
+	assert.NoError(t, err)
+	assert.Equal(t, []uint64{4000, 3000}, selectedSats(sel.UTXOs))
+	assert.Equal(t, 2, tx.InputCount())
+	assert.True(t, sel.HasChange)
+
+	ok, err := tx.EstimateIsFeePaidEnough(bt.NewFeeQuote())
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestSmallestFirst(t *testing.T) {
+	t.Parallel()
+
+	t.Run("selects smallest first", func(t *testing.T) {
+		tx := testTx(t, 5000)
+		sel, err := tx.FundWithSelector(context.Background(), bt.NewFeeQuote(),
+			testUTXOs(t, 4000, 1000, 3000, 2000), &coinselect.SmallestFirst{})
+		assert.NoError(t, err)
+		assert.Equal(t, []uint64{1000, 2000, 3000}, selectedSats(sel.UTXOs))
+	})
+
+	t.Run("skips utxos not worth spending", func(t *testing.T) {
+		tx := testTx(t, 5000)
+		sel, err := tx.FundWithSelector(context.Background(), bt.NewFeeQuote(),
+			testUTXOs(t, 10, 6000, 20), &coinselect.SmallestFirst{})
+		assert.NoError(t, err)
+		assert.Equal(t, []uint64{6000}, selectedSats(sel.UTXOs))
+	})
+
+	t.Run("insufficient funds", func(t *testing.T) {
+		tx := testTx(t, 5000)
+		_, err := tx.FundWithSelector(context.Background(), bt.NewFeeQuote(),
+			testUTXOs(t, 1000, 2000), &coinselect.SmallestFirst{})
+		assert.True(t, errors.Is(err, bt.ErrInsufficientFunds))
+		assert.Equal(t, 0, tx.InputCount())
+	})
+}
+
+func TestBranchAndBound(t *testing.T) {
+	t.Parallel()
+
+	t.Run("finds selection without change", func(t *testing.T) {
+		tx := testTx(t, 5000)
+		ft, err := tx.FundingTarget(bt.NewFeeQuote())
+		assert.NoError(t, err)
+
+		// Two P2PKH inputs, each 148 bytes, funding the tx exactly.
+		exact := ft.Required(2, 2*148)
+		utxos := testUTXOs(t, 7000, 1200, exact-2500, 6000, 2500, 300)
+
+		sel, err := tx.FundWithSelector(context.Background(), bt.NewFeeQuote(), utxos, &coinselect.BranchAndBound{})
+		assert.NoError(t, err)
+		assert.Equal(t, []uint64{exact - 2500, 2500}, selectedSats(sel.UTXOs))
+		assert.False(t, sel.HasChange)
+		assert.Equal(t, uint64(0), sel.Change)
+		assert.Equal(t, tx.TotalInputSatoshis()-tx.TotalOutputSatoshis(), sel.Fee)
+
+		ok, err := tx.EstimateIsFeePaidEnough(bt.NewFeeQuote())
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("no exact match", func(t *testing.T) {
+		tx := testTx(t, 5000)
+		_, err := tx.FundWithSelector(context.Background(), bt.NewFeeQuote(),
+			testUTXOs(t, 10000, 20000), &coinselect.BranchAndBound{})
+		assert.True(t, errors.Is(err, coinselect.ErrNoExactMatch))
+	})
+
+	t.Run("insufficient funds", func(t *testing.T) {
+		tx := testTx(t, 5000)
+		_, err := tx.FundWithSelector(context.Background(), bt.NewFeeQuote(),
+			testUTXOs(t, 1000, 2000), &coinselect.BranchAndBound{})
+		assert.True(t, errors.Is(err, bt.ErrInsufficientFunds))
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		sats := make([]uint64, 30)
+		for i := range sats {
+			sats[i] = uint64(1000 + i*7)
+		}
+
+		tx := testTx(t, 12345)
+		_, err := tx.FundWithSelector(ctx, bt.NewFeeQuote(), testUTXOs(t, sats...), &coinselect.BranchAndBound{})
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
+}
+
+func TestRandomImprove(t *testing.T) {
+	t.Parallel()
+
+	sats := make([]uint64, 50)
+	for i := range sats {
+		sats[i] = uint64(500 + i*100)
+	}
+
+	tx := testTx(t, 5000)
+	sel, err := tx.FundWithSelector(context.Background(), bt.NewFeeQuote(), testUTXOs(t, sats...),
+		&coinselect.RandomImprove{Rand: rand.New(rand.NewSource(1))}) //nolint:gosec // deterministic test
+	assert.NoError(t, err)
+
+	ft, err := testTx(t, 5000).FundingTarget(bt.NewFeeQuote())
+	assert.NoError(t, err)
+	required := ft.Required(len(sel.UTXOs), uint64(len(sel.UTXOs))*148)
+	assert.GreaterOrEqual(t, tx.TotalInputSatoshis(), required)
+	assert.LessOrEqual(t, tx.TotalInputSatoshis(), 3*required)
+}
+
+func TestFundWithSelector_Change(t *testing.T) {
+	t.Parallel()
+
+	tx := testTx(t, 5000)
+	sel, err := tx.FundWithSelector(context.Background(), bt.NewFeeQuote(),
+		testUTXOs(t, 8000), &coinselect.LargestFirst{})
+	assert.NoError(t, err)
+	assert.True(t, sel.HasChange)
+
+	assert.NoError(t, tx.ChangeToAddress(testAddress, bt.NewFeeQuote()))
+	assert.Equal(t, 2, tx.OutputCount())
+	assert.Equal(t, sel.Change, tx.Outputs[1].Satoshis)
+}
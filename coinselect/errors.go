@@ -0,0 +1,8 @@
+package coinselect
+
+import "github.com/pkg/errors"
+
+// Sentinel errors reported by the coin selectors.
+var (
+	ErrNoExactMatch = errors.New("no selection funds the tx without change")
+)
@@ -0,0 +1,82 @@
+package coinselect
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/mvc-labs/mvc-lib-go"
+)
+
+// RandomImprove implements the `bt.CoinSelector` interface. It randomly selects utxos
+// until the tx is funded, then continues to randomly select utxos so long as they
+// bring the total closer to twice what is required, never exceeding three times.
+//
+// This leaves change of a similar size to the payment being made, which keeps a
+// healthy spread of utxo sizes in a wallet over time, and avoids revealing which
+// output is the change.
+type RandomImprove struct {
+	// Rand the source of randomness used to select utxos. [DEFAULT seeded with the current time]
+	Rand *rand.Rand
+}
+
+// SelectCoins randomly selects utxos, improving the selection towards twice what is required.
+func (r *RandomImprove) SelectCoins(ctx context.Context, ft *bt.FundingTarget, utxos bt.UTXOs) (bt.UTXOs, error) {
+	if ft.Required(0, 0) == 0 {
+		return bt.UTXOs{}, nil
+	}
+
+	cc, err := candidates(ctx, ft, utxos)
+	if err != nil {
+		return nil, err
+	}
+
+	rnd := r.Rand
+	if rnd == nil {
+		rnd = rand.New(rand.NewSource(time.Now().UnixNano())) //nolint:gosec // not used for security
+	}
+	rnd.Shuffle(len(cc), func(i, j int) {
+		cc[i], cc[j] = cc[j], cc[i]
+	})
+
+	selected := make(bt.UTXOs, 0)
+	var total, inputBytes uint64
+	i := 0
+	for ; i < len(cc) && total < ft.Required(len(selected), inputBytes); i++ {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+		selected = append(selected, cc[i].utxo)
+		total += cc[i].utxo.Satoshis
+		inputBytes += cc[i].size
+	}
+	if total < ft.Required(len(selected), inputBytes) {
+		return nil, bt.ErrInsufficientFunds
+	}
+
+	for ; i < len(cc); i++ {
+		required := ft.Required(len(selected), inputBytes)
+		nextTotal := total + cc[i].utxo.Satoshis
+		nextRequired := ft.Required(len(selected)+1, inputBytes+cc[i].size)
+
+		if nextTotal > 3*nextRequired {
+			continue
+		}
+		if distance(2*nextRequired, nextTotal) >= distance(2*required, total) {
+			continue
+		}
+
+		selected = append(selected, cc[i].utxo)
+		total = nextTotal
+		inputBytes += cc[i].size
+	}
+
+	return selected, nil
+}
+
+func distance(a, b uint64) uint64 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
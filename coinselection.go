@@ -0,0 +1,212 @@
+package bt
+
+import (
+	"context"
+)
+
+// p2pkhChangeOutputSize is the size in bytes of a P2PKH change output.
+const p2pkhChangeOutputSize = 8 + 1 + 25
+
+// CoinSelector is used for tx.FundWithSelector(...). It is provided a *bt.FundingTarget,
+// describing what is needed to fund the tx, and the utxos available, and is expected
+// to return the utxos to fund the tx with.
+//
+// If the available utxos cannot fund the tx, a bt.ErrInsufficientFunds is expected
+// to be returned.
+//
+// For implementations, see the `coinselect` package.
+type CoinSelector interface {
+	SelectCoins(ctx context.Context, ft *FundingTarget, utxos UTXOs) (UTXOs, error)
+}
+
+// CoinSelection is the outcome of selecting utxos to fund a tx.
+type CoinSelection struct {
+	// UTXOs the utxos selected to fund the tx.
+	UTXOs UTXOs
+	// Fee the estimated fee of the funded tx, including the change output if there is one.
+	Fee uint64
+	// HasChange true if there is enough left over, after fees, for a change output.
+	HasChange bool
+	// Change the satoshis of the change output, if there is one.
+	Change uint64
+}
+
+// FundingTarget describes what is needed to fund a tx, and is used by a bt.CoinSelector
+// to account for the fee of each input it selects.
+//
+// It should be created via tx.FundingTarget(...), once all outputs have been added.
+type FundingTarget struct {
+	// Outputs the total satoshis of the tx outputs.
+	Outputs uint64
+	// Inputs the total satoshis of any inputs already added to the tx.
+	Inputs uint64
+
+	inputCount  int
+	outputCount int
+	stdBytes    uint64
+	dataFees    uint64
+	stdFee      *Fee
+	ugs         []UnlockerGetter
+}
+
+// FundingTarget returns a *bt.FundingTarget describing what is needed to fund the tx
+// with the fees of the provided *bt.FeeQuote. Any inputs already added to the tx are
+// taken into account.
+//
+// The provided bt.UnlockerGetters are used to estimate the size of inputs, as described
+// in tx.EstimateSize(...).
+func (tx *Tx) FundingTarget(fq *FeeQuote, ugs ...UnlockerGetter) (*FundingTarget, error) {
+	size, err := tx.EstimateSizeWithTypes(ugs...)
+	if err != nil {
+		return nil, err
+	}
+	stdFee, err := fq.Fee(FeeTypeStandard)
+	if err != nil {
+		return nil, err
+	}
+	dataFee, err := fq.Fee(FeeTypeData)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FundingTarget{
+		Outputs:     tx.TotalOutputSatoshis(),
+		Inputs:      tx.TotalInputSatoshis(),
+		inputCount:  tx.InputCount(),
+		outputCount: tx.OutputCount(),
+		stdBytes:    size.TotalStdBytes,
+		dataFees:    size.TotalDataBytes * uint64(dataFee.MiningFee.Satoshis) / uint64(dataFee.MiningFee.Bytes),
+		stdFee:      stdFee,
+		ugs:         ugs,
+	}, nil
+}
+
+// InputSize returns the estimated size in bytes of an input spending the utxo, once
+// it has been signed.
+func (ft *FundingTarget) InputSize(ctx context.Context, u *UTXO) (uint64, error) {
+	l, err := estimateUnlockingScriptLength(ctx, u.LockingScript, ft.ugs)
+	if err != nil {
+		return 0, err
+	}
+
+	// outpoint + unlocking script + sequence number
+	return uint64(32 + 4 + VarInt(l).Length() + l + 4), nil
+}
+
+// Fee returns the estimated fee of the tx once n inputs, totalling inputBytes in size,
+// are added to it. If withChange is true, the fee includes a P2PKH change output.
+func (ft *FundingTarget) Fee(n int, inputBytes uint64, withChange bool) uint64 {
+	stdBytes := ft.stdBytes + inputBytes +
+		uint64(VarInt(ft.inputCount+n).Length()-VarInt(ft.inputCount).Length())
+
+	var outputCountFee uint64
+	if withChange {
+		stdBytes += p2pkhChangeOutputSize
+		// Mirrors tx.Change(...), which charges for the growth of the output count.
+		if inc := VarInt(ft.outputCount).UpperLimitInc(); inc > 0 {
+			outputCountFee = uint64(inc)
+		}
+	}
+
+	return stdBytes*uint64(ft.stdFee.MiningFee.Satoshis)/uint64(ft.stdFee.MiningFee.Bytes) +
+		ft.dataFees + outputCountFee
+}
+
+// Required returns the satoshis that n inputs, totalling inputBytes in size, must
+// provide to fund the tx without a change output. Zero is returned if the tx is
+// already funded.
+func (ft *FundingTarget) Required(n int, inputBytes uint64) uint64 {
+	needed := ft.Outputs + ft.Fee(n, inputBytes, false)
+	if ft.Inputs >= needed {
+		return 0
+	}
+
+	return needed - ft.Inputs
+}
+
+// MaxWithoutChange returns the most satoshis that n inputs, totalling inputBytes in size,
+// can provide without enough being left over, after fees, for a change output.
+func (ft *FundingTarget) MaxWithoutChange(n int, inputBytes uint64) uint64 {
+	max := ft.Outputs + ft.Fee(n, inputBytes, true) + DustLimit
+	if ft.Inputs >= max {
+		return 0
+	}
+
+	return max - ft.Inputs
+}
+
+// Evaluate calculates the fee and change of the tx once funded with the provided utxos.
+// If the utxos do not fund the tx, a bt.ErrInsufficientFunds is returned.
+func (ft *FundingTarget) Evaluate(ctx context.Context, utxos UTXOs) (*CoinSelection, error) {
+	var total, inputBytes uint64
+	for _, u := range utxos {
+		size, err := ft.InputSize(ctx, u)
+		if err != nil {
+			return nil, err
+		}
+		total += u.Satoshis
+		inputBytes += size
+	}
+
+	if total < ft.Required(len(utxos), inputBytes) {
+		return nil, ErrInsufficientFunds
+	}
+
+	cs := &CoinSelection{
+		UTXOs: utxos,
+		Fee:   ft.Fee(len(utxos), inputBytes, false),
+	}
+	if total > ft.MaxWithoutChange(len(utxos), inputBytes) {
+		cs.Fee = ft.Fee(len(utxos), inputBytes, true)
+		cs.HasChange = true
+		cs.Change = ft.Inputs + total - ft.Outputs - cs.Fee
+	}
+
+	return cs, nil
+}
+
+// FundWithSelector funds the tx from the provided utxos, using the provided bt.CoinSelector
+// to choose which of them to add as inputs. The returned *bt.CoinSelection details the
+// utxos added and whether the tx will have change.
+//
+// After completion, the receiver is ready for `Change(...)` to be called, and then be signed.
+// As with tx.Fund(...), the receiver *bt.Tx should already have all the outputs which need
+// covered.
+//
+// The provided bt.UnlockerGetters are used to estimate the size of inputs, as described
+// in tx.EstimateSize(...).
+//
+// If the utxos cannot fund the tx, a bt.ErrInsufficientFunds is returned.
+//
+// Example usage:
+//
+//	sel, err := tx.FundWithSelector(ctx, bt.NewFeeQuote(), utxos, &coinselect.BranchAndBound{})
+//	if err != nil {
+//	    return err
+//	}
+//	if sel.HasChange {
+//	    if err := tx.ChangeToAddress(addr, fq); err != nil {}
+//	}
+func (tx *Tx) FundWithSelector(ctx context.Context, fq *FeeQuote, utxos UTXOs,
+	cs CoinSelector, ugs ...UnlockerGetter) (*CoinSelection, error) {
+	ft, err := tx.FundingTarget(fq, ugs...)
+	if err != nil {
+		return nil, err
+	}
+
+	selected, err := cs.SelectCoins(ctx, ft, utxos)
+	if err != nil {
+		return nil, err
+	}
+
+	sel, err := ft.Evaluate(ctx, selected)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.FromUTXOs(selected...); err != nil {
+		return nil, err
+	}
+
+	return sel, nil
+}
@@ -31,12 +31,51 @@ var (
 	ErrInsufficientInputs = errors.New("satoshis inputted to the tx are less than the outputted satoshis")
 )
 
+// Sentinel errors reported by blocks.
+var (
+	ErrBlockHeaderLength = errors.New("block header must be 80 bytes long")
+	ErrBlockHeaderNil    = errors.New("block header is nil")
+	ErrBlockTrailingData = errors.New("unexpected data after end of block")
+)
+
+// Sentinel errors reported by merkle trees and proofs.
+var (
+	ErrMerkleNoTxs             = errors.New("no txs to build merkle tree from")
+	ErrMerkleTxNotFound        = errors.New("tx not found in merkle tree")
+	ErrMerkleRootMismatch      = errors.New("merkle root does not match")
+	ErrMerkleProofInvalid      = errors.New("invalid merkle proof")
+	ErrMerkleProofUnsupported  = errors.New("unsupported merkle proof")
+	ErrMerkleProofTrailingData = errors.New("unexpected data after end of merkle proof")
+)
+
+// Sentinel errors reported by BEEFs.
+var (
+	ErrBEEFVersion      = errors.New("unsupported BEEF version")
+	ErrBEEFNoTxs        = errors.New("BEEF has no txs")
+	ErrBEEFBUMPIndex    = errors.New("invalid BEEF bump index")
+	ErrBEEFInvalid      = errors.New("invalid BEEF")
+	ErrBEEFTrailingData = errors.New("unexpected data after end of BEEF")
+)
+
+// Sentinel errors reported by MvcTxID proofs.
+var (
+	ErrMvcProofInvalid      = errors.New("invalid MvcTxID proof")
+	ErrMvcProofTrailingData = errors.New("unexpected data after end of MvcTxID proof")
+)
+
 // Sentinal errors reported by signature hash.
 var (
 	ErrEmptyPreviousTxID     = errors.New("'PreviousTxID' not supplied")
 	ErrEmptyPreviousTxScript = errors.New("'PreviousTxScript' not supplied")
 )
 
+// Sentinel errors reported by preimages.
+var (
+	ErrPreimageInvalid      = errors.New("invalid preimage")
+	ErrPreimageTrailingData = errors.New("unexpected data after end of preimage")
+	ErrPushTxHighS          = errors.New("OP_PUSH_TX signature s is not low")
+)
+
 // Sentinel errors reported by the fees.
 var (
 	ErrFeeQuotesNotInit = errors.New("feeQuotes have not been setup, call NewFeeQuotes")
@@ -2,6 +2,7 @@ package main
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
 
@@ -26,18 +27,21 @@ func main() {
 	// Create buffered reader for this file.
 	r := bufio.NewReader(f)
 
-	// Read file header. This step is specific to file reading and
-	// may need omitted or modified for other implementations.
-	_, err = io.ReadFull(f, make([]byte, 80))
+	// Read the block header and tx count, ready for the txs to be streamed.
+	br, err := bt.NewBlockReader(r)
 	if err != nil {
 		panic(err)
 	}
+	fmt.Println(br.Header().HashStr())
 
-	txs := bt.Txs{}
-	if _, err = txs.ReadFrom(r); err != nil {
-		panic(err)
-	}
-	for _, tx := range txs {
+	for {
+		tx, err := br.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			panic(err)
+		}
 		fmt.Println(tx.TxID())
 	}
 }
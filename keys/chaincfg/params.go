@@ -6,28 +6,52 @@ import (
 
 // Constants for network names.
 const (
-	NetworkMain = "mainnet"
-	NetworkTest = "regtest"
+	NetworkMain    = "mainnet"
+	NetworkTest    = "testnet"
+	NetworkRegTest = "regtest"
 )
 
 var (
+	// ErrDuplicateNet describes an error where the parameters for a network
+	// could not be set due to the network already being a standard
+	// network or previously-registered into this package.
+	ErrDuplicateNet = errors.New("duplicate network")
+
+	// ErrUnknownNet describes an error where the network being looked up
+	// is not registered.
+	ErrUnknownNet = errors.New("unknown network")
+
 	// ErrUnknownHDKeyID describes an error where the provided id which
 	// is intended to identify the network for a hierarchical deterministic
 	// private extended key is not registered.
 	ErrUnknownHDKeyID = errors.New("unknown hd private extended key bytes")
 
-	scriptHashAddrIDs = make(map[byte]struct{})
-	pubKeyHashAddrIDs = make(map[byte]struct{})
+	// Registered networks, by each of the identifiers they can be
+	// looked up with.  Networks may share prefixes, so those are mapped to
+	// every network using them, in the order they were registered.
+	nameParams        = make(map[string]*Params)
+	scriptHashAddrIDs = make(map[byte][]*Params)
+	pubKeyHashAddrIDs = make(map[byte][]*Params)
+	privateKeyIDs     = make(map[byte][]*Params)
+	hdKeyIDs          = make(map[[4]byte][]*Params)
 	hdPrivToPubKeyIDs = make(map[[4]byte][]byte)
 )
 
-// Params defines a Bitcoin network by its parameters.  These parameters may be
-// used by Bitcoin applications to differentiate networks as well as addresses
+// Params defines an MVC network by its parameters.  These parameters may be
+// used by MVC applications to differentiate networks as well as addresses
 // and keys for one network from those intended for use on another network.
+//
+// Chain identity, such as the genesis block, message start bytes, ports and
+// activation heights, is not held here and should be taken from the MVC
+// node's chainparams.
 type Params struct {
 	// Name defines a human-readable identifier for the network.
 	Name string
 
+	// DustLimit is the minimum number of satoshis an output must hold to
+	// be relayed.
+	DustLimit uint64
+
 	// Address encoding magics
 	LegacyPubKeyHashAddrID byte // First byte of a P2PKH address
 	LegacyScriptHashAddrID byte // First byte of a P2SH address
@@ -38,12 +62,14 @@ type Params struct {
 	HDPublicKeyID  [4]byte
 }
 
-// MainNet defines the network parameters for the main Bitcoin network.
+// MainNet defines the network parameters for the main MVC network.
 var MainNet = Params{
-	Name: NetworkMain,
+	Name:      NetworkMain,
+	DustLimit: 1, // bt.DustLimit
 
 	// Address encoding magics
 	LegacyPubKeyHashAddrID: 0x00, // starts with 1
+	LegacyScriptHashAddrID: 0x05, // starts with 3
 	PrivateKeyID:           0x80, // starts with 5 (uncompressed) or K (compressed)
 
 	// BIP32 hierarchical deterministic extended key magics
@@ -51,14 +77,14 @@ var MainNet = Params{
 	HDPublicKeyID:  [4]byte{0x04, 0x88, 0xb2, 0x1e}, // starts with xpub
 }
 
-// TestNet defines the network parameters for the regression test
-// Bitcoin network.  Not to be confused with the test Bitcoin network (version
-// 3), this network is sometimes simply called "testnet".
+// TestNet defines the network parameters for the public test MVC network.
 var TestNet = Params{
-	Name: NetworkTest,
+	Name:      NetworkTest,
+	DustLimit: 1, // bt.DustLimit
 
 	// Address encoding magics
 	LegacyPubKeyHashAddrID: 0x6f, // starts with m or n
+	LegacyScriptHashAddrID: 0xc4, // starts with 2
 	PrivateKeyID:           0xef, // starts with 9 (uncompressed) or c (compressed)
 
 	// BIP32 hierarchical deterministic extended key magics
@@ -66,6 +92,100 @@ var TestNet = Params{
 	HDPublicKeyID:  [4]byte{0x04, 0x35, 0x87, 0xcf}, // starts with tpub
 }
 
+// RegTest defines the network parameters for the regression test MVC
+// network.  It shares its address and key magics with TestNet, so the lookups
+// by prefix below return both networks for them.
+var RegTest = Params{
+	Name:      NetworkRegTest,
+	DustLimit: 1, // bt.DustLimit
+
+	// Address encoding magics
+	LegacyPubKeyHashAddrID: 0x6f, // starts with m or n
+	LegacyScriptHashAddrID: 0xc4, // starts with 2
+	PrivateKeyID:           0xef, // starts with 9 (uncompressed) or c (compressed)
+
+	// BIP32 hierarchical deterministic extended key magics
+	HDPrivateKeyID: [4]byte{0x04, 0x35, 0x83, 0x94}, // starts with tprv
+	HDPublicKeyID:  [4]byte{0x04, 0x35, 0x87, 0xcf}, // starts with tpub
+}
+
+// ParamsForName returns the registered network with the provided name.  When
+// the name is not registered, the ErrUnknownNet error will be returned.
+func ParamsForName(name string) (*Params, error) {
+	params, ok := nameParams[name]
+	if !ok {
+		return nil, ErrUnknownNet
+	}
+	return params, nil
+}
+
+// ParamsForPubKeyHashAddrID returns the registered networks using the provided
+// P2PKH address prefix, in the order they were registered.  Networks may share a
+// prefix, as testnet and regtest do, so the caller should pick the one it expects
+// from those returned.  When the prefix is not registered, the ErrUnknownNet error
+// will be returned.
+func ParamsForPubKeyHashAddrID(id byte) ([]*Params, error) {
+	return paramsForAddrID(pubKeyHashAddrIDs, id)
+}
+
+// ParamsForScriptHashAddrID returns the registered networks using the provided
+// P2SH address prefix, in the order they were registered.  When the prefix is
+// not registered, the ErrUnknownNet error will be returned.
+func ParamsForScriptHashAddrID(id byte) ([]*Params, error) {
+	return paramsForAddrID(scriptHashAddrIDs, id)
+}
+
+// ParamsForPrivateKeyID returns the registered networks using the provided WIF
+// private key prefix, in the order they were registered.  When the prefix is not
+// registered, the ErrUnknownNet error will be returned.
+func ParamsForPrivateKeyID(id byte) ([]*Params, error) {
+	return paramsForAddrID(privateKeyIDs, id)
+}
+
+// ParamsForHDKeyID returns the registered networks using the provided
+// hierarchical deterministic extended key version, either private or public, in
+// the order they were registered.  When the version is not registered, the
+// ErrUnknownHDKeyID error will be returned.
+func ParamsForHDKeyID(id []byte) ([]*Params, error) {
+	if len(id) != 4 {
+		return nil, ErrUnknownHDKeyID
+	}
+
+	var key [4]byte
+	copy(key[:], id)
+	params, ok := hdKeyIDs[key]
+	if !ok {
+		return nil, ErrUnknownHDKeyID
+	}
+
+	// Copy so callers can't reorder the registered networks.
+	return append([]*Params(nil), params...), nil
+}
+
+func paramsForAddrID(ids map[byte][]*Params, id byte) ([]*Params, error) {
+	params, ok := ids[id]
+	if !ok {
+		return nil, ErrUnknownNet
+	}
+
+	// Copy so callers can't reorder the registered networks.
+	return append([]*Params(nil), params...), nil
+}
+
+// IsPubKeyHashAddrID returns whether the id is an identifier known to prefix a
+// pay-to-pubkey-hash address on any registered network.
+func IsPubKeyHashAddrID(id byte) bool {
+	_, ok := pubKeyHashAddrIDs[id]
+	return ok
+}
+
+// IsScriptHashAddrID returns whether the id is an identifier known to prefix a
+// pay-to-script-hash address on any registered network.
+func IsScriptHashAddrID(id byte) bool {
+	_, ok := scriptHashAddrIDs[id]
+	return ok
+}
+
 // HDPrivateKeyToPublicKeyID accepts a private hierarchical deterministic
 // extended key id and returns the associated public key id.  When the provided
 // id is not registered, the ErrUnknownHDKeyID error will be returned.
@@ -84,19 +204,33 @@ func HDPrivateKeyToPublicKeyID(id []byte) ([]byte, error) {
 	return pubBytes, nil
 }
 
-// Register registers the network parameters for a Bitcoin network.  This may
-// error with ErrDuplicateNet if the network is already registered (either
-// due to a previous Register call, or the network being one of the default
-// networks).
+// Register registers the network parameters for an MVC network.  This may
+// error with ErrDuplicateNet if the network is already registered by its name
+// (either due to a previous Register call, or the network being one of the
+// default networks).
+//
+// Networks may share address and key prefixes, as testnet and regtest do, in
+// which case lookups by prefix return each of them.
 //
 // Network parameters should be registered into this package by a main package
 // as early as possible.  Then, library packages may lookup networks or network
 // parameters based on inputs and work regardless of the network being standard
 // or not.
 func Register(params *Params) error {
-	scriptHashAddrIDs[params.LegacyScriptHashAddrID] = struct{}{}
-	pubKeyHashAddrIDs[params.LegacyPubKeyHashAddrID] = struct{}{}
-	hdPrivToPubKeyIDs[params.HDPrivateKeyID] = params.HDPublicKeyID[:]
+	if _, ok := nameParams[params.Name]; ok {
+		return ErrDuplicateNet
+	}
+
+	nameParams[params.Name] = params
+	scriptHashAddrIDs[params.LegacyScriptHashAddrID] = append(scriptHashAddrIDs[params.LegacyScriptHashAddrID], params)
+	pubKeyHashAddrIDs[params.LegacyPubKeyHashAddrID] = append(pubKeyHashAddrIDs[params.LegacyPubKeyHashAddrID], params)
+	privateKeyIDs[params.PrivateKeyID] = append(privateKeyIDs[params.PrivateKeyID], params)
+	for _, id := range [][4]byte{params.HDPrivateKeyID, params.HDPublicKeyID} {
+		hdKeyIDs[id] = append(hdKeyIDs[id], params)
+	}
+	if _, ok := hdPrivToPubKeyIDs[params.HDPrivateKeyID]; !ok {
+		hdPrivToPubKeyIDs[params.HDPrivateKeyID] = params.HDPublicKeyID[:]
+	}
 	return nil
 }
 
@@ -112,4 +246,5 @@ func init() {
 	// Register all default networks when the package is initialised.
 	mustRegister(&MainNet)
 	mustRegister(&TestNet)
+	mustRegister(&RegTest)
 }
@@ -0,0 +1,85 @@
+package chaincfg_test
+
+import (
+	"testing"
+
+	"github.com/mvc-labs/mvc-lib-go/keys/chaincfg"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParamsLookups(t *testing.T) {
+	t.Parallel()
+
+	t.Run("by name", func(t *testing.T) {
+		for _, params := range []*chaincfg.Params{&chaincfg.MainNet, &chaincfg.TestNet, &chaincfg.RegTest} {
+			p, err := chaincfg.ParamsForName(params.Name)
+			assert.NoError(t, err)
+			assert.Equal(t, params, p)
+		}
+
+		_, err := chaincfg.ParamsForName("simnet")
+		assert.ErrorIs(t, err, chaincfg.ErrUnknownNet)
+	})
+
+	t.Run("by prefix", func(t *testing.T) {
+		p, err := chaincfg.ParamsForPubKeyHashAddrID(0x00)
+		assert.NoError(t, err)
+		assert.Equal(t, []*chaincfg.Params{&chaincfg.MainNet}, p)
+
+		p, err = chaincfg.ParamsForScriptHashAddrID(0x05)
+		assert.NoError(t, err)
+		assert.Equal(t, []*chaincfg.Params{&chaincfg.MainNet}, p)
+
+		p, err = chaincfg.ParamsForHDKeyID([]byte{0x04, 0x88, 0xb2, 0x1e})
+		assert.NoError(t, err)
+		assert.Equal(t, []*chaincfg.Params{&chaincfg.MainNet}, p)
+
+		_, err = chaincfg.ParamsForPubKeyHashAddrID(0x30)
+		assert.ErrorIs(t, err, chaincfg.ErrUnknownNet)
+		_, err = chaincfg.ParamsForHDKeyID([]byte{0x04})
+		assert.ErrorIs(t, err, chaincfg.ErrUnknownHDKeyID)
+
+		assert.True(t, chaincfg.IsPubKeyHashAddrID(0x6f))
+		assert.False(t, chaincfg.IsPubKeyHashAddrID(0x05))
+		assert.True(t, chaincfg.IsScriptHashAddrID(0xc4))
+		assert.False(t, chaincfg.IsScriptHashAddrID(0x00))
+	})
+
+	t.Run("by shared prefix", func(t *testing.T) {
+		// regtest shares its prefixes with testnet, so both are returned, in the
+		// order they were registered.
+		shared := []*chaincfg.Params{&chaincfg.TestNet, &chaincfg.RegTest}
+
+		p, err := chaincfg.ParamsForPubKeyHashAddrID(0x6f)
+		assert.NoError(t, err)
+		assert.Equal(t, shared, p)
+
+		p, err = chaincfg.ParamsForScriptHashAddrID(0xc4)
+		assert.NoError(t, err)
+		assert.Equal(t, shared, p)
+
+		p, err = chaincfg.ParamsForPrivateKeyID(0xef)
+		assert.NoError(t, err)
+		assert.Equal(t, shared, p)
+
+		p, err = chaincfg.ParamsForHDKeyID([]byte{0x04, 0x35, 0x83, 0x94})
+		assert.NoError(t, err)
+		assert.Equal(t, shared, p)
+
+		// The returned networks are a copy.
+		p[0] = &chaincfg.MainNet
+		p, err = chaincfg.ParamsForHDKeyID([]byte{0x04, 0x35, 0x83, 0x94})
+		assert.NoError(t, err)
+		assert.Equal(t, shared, p)
+	})
+}
+
+func TestRegister(t *testing.T) {
+	t.Parallel()
+
+	dup := chaincfg.RegTest
+	assert.ErrorIs(t, chaincfg.Register(&dup), chaincfg.ErrDuplicateNet)
+
+	dup = chaincfg.MainNet
+	assert.ErrorIs(t, chaincfg.Register(&dup), chaincfg.ErrDuplicateNet)
+}
@@ -62,7 +62,8 @@ func NewWIF(privKey *bec.PrivateKey, net *chaincfg.Params, compress bool) (*WIF,
 }
 
 // IsForNet returns whether or not the decoded WIF structure is associated
-// with the passed bitcoin network.
+// with the passed bitcoin network.  Networks sharing a WIF prefix, such as
+// testnet and regtest, are all associated with it.
 func (w *WIF) IsForNet(net *chaincfg.Params) bool {
 	return w.netID == net.PrivateKeyID
 }
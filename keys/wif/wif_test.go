@@ -68,3 +68,20 @@ func TestEncodeDecodeWIF(t *testing.T) {
 		}
 	}
 }
+
+func TestWIFIsForNet(t *testing.T) {
+	// Testnet and regtest share the WIF prefix 0xef, so a decoded key is for both.
+	w, err := DecodeWIF("cV1Y7ARUr9Yx7BR55nTdnR7ZXNJphZtCCMBTEZBJe1hXt2kB684q")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, net := range []*chaincfg.Params{&chaincfg.TestNet, &chaincfg.RegTest} {
+		if !w.IsForNet(net) {
+			t.Errorf("IsForNet(%s) failed: want true, got false", net.Name)
+		}
+	}
+	if w.IsForNet(&chaincfg.MainNet) {
+		t.Errorf("IsForNet(%s) failed: want false, got true", chaincfg.MainNet.Name)
+	}
+}
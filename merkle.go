@@ -0,0 +1,160 @@
+package bt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/mvc-labs/mvc-lib-go/keys/crypto"
+)
+
+// MerkleTreeParent returns the merkle tree parent of two tree nodes. The nodes
+// and the returned parent are in internal (little endian) byte order, which is
+// the reverse of how txids and block hashes are displayed.
+func MerkleTreeParent(left, right []byte) []byte {
+	b := make([]byte, 0, len(left)+len(right))
+	b = append(b, left...)
+	b = append(b, right...)
+
+	return crypto.Sha256d(b)
+}
+
+// MerkleTreeParentStr returns the merkle tree parent of two tree nodes given
+// as hex strings in display order, such as txids, and returns the parent as a
+// hex string in display order.
+func MerkleTreeParentStr(left, right string) (string, error) {
+	l, err := hex.DecodeString(left)
+	if err != nil {
+		return "", err
+	}
+	r, err := hex.DecodeString(right)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(ReverseBytes(MerkleTreeParent(ReverseBytes(l), ReverseBytes(r)))), nil
+}
+
+// MerkleRootFromTxIDs calculates the merkle root of the provided txids. The txids
+// and the returned root are in display order.
+func MerkleRootFromTxIDs(txIDs [][]byte) ([]byte, error) {
+	if len(txIDs) == 0 {
+		return nil, ErrMerkleNoTxs
+	}
+
+	leaves := make([][]byte, len(txIDs))
+	for i, txID := range txIDs {
+		if !IsValidTxID(txID) {
+			return nil, fmt.Errorf("%w at index %d", ErrInvalidTxID, i)
+		}
+		leaves[i] = ReverseBytes(txID)
+	}
+
+	levels := merkleTreeLevels(leaves)
+
+	return ReverseBytes(levels[len(levels)-1][0]), nil
+}
+
+// TxIDs returns the txid of each tx in the collection, in display order.
+//
+// For txs of version 10 and above this is the MVC txid, as it is this
+// which is committed to in the block merkle root.
+func (tt Txs) TxIDs() [][]byte {
+	ids := make([][]byte, len(tt))
+	for i, tx := range tt {
+		ids[i] = tx.TxIDBytes()
+	}
+	return ids
+}
+
+// MerkleRoot calculates the merkle root of the txs, in display order.
+// The txs are expected to be in block order, with the coinbase first.
+func (tt Txs) MerkleRoot() ([]byte, error) {
+	return MerkleRootFromTxIDs(tt.TxIDs())
+}
+
+// MerkleProof builds a TSC merkle proof for the tx at the given index, proving
+// its inclusion against the merkle root of the txs.
+//
+// The proof holds the txid of the tx, and targets the merkle root. Should the
+// block hash or header be wanted as the target instead, they can be set on the
+// returned proof.
+func (tt Txs) MerkleProof(txIdx int) (*MerkleProof, error) {
+	if len(tt) == 0 {
+		return nil, ErrMerkleNoTxs
+	}
+	if txIdx < 0 || txIdx >= len(tt) {
+		return nil, fmt.Errorf("%w: tx index %d of %d", ErrMerkleTxNotFound, txIdx, len(tt))
+	}
+
+	levels := merkleTreeLevels(tt.merkleLeaves())
+
+	nodes := make([]string, 0, len(levels)-1)
+	offset := txIdx
+	for _, level := range levels[:len(levels)-1] {
+		sibling := offset ^ 1
+		if sibling >= len(level) {
+			nodes = append(nodes, MerkleProofDuplicateNode)
+		} else {
+			nodes = append(nodes, hex.EncodeToString(ReverseBytes(level[sibling])))
+		}
+		offset >>= 1
+	}
+
+	return &MerkleProof{
+		Index:      uint64(txIdx),
+		TxOrID:     tt[txIdx].TxID(),
+		Target:     hex.EncodeToString(ReverseBytes(levels[len(levels)-1][0])),
+		TargetType: MerkleProofTargetTypeMerkleRoot,
+		Nodes:      nodes,
+	}, nil
+}
+
+// CheckMerkleRoot calculates the merkle root of the txs in the block and checks
+// it against the merkle root held in the header. If they do not match, an
+// ErrMerkleRootMismatch error is returned.
+func (b *Block) CheckMerkleRoot() error {
+	root, err := b.Txs.MerkleRoot()
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(root, b.Header.MerkleRoot) {
+		return fmt.Errorf("%w: calculated %x, header has %x", ErrMerkleRootMismatch, root, b.Header.MerkleRoot)
+	}
+
+	return nil
+}
+
+// merkleLeaves returns the txids of the txs in internal byte order.
+func (tt Txs) merkleLeaves() [][]byte {
+	leaves := make([][]byte, len(tt))
+	for i, tx := range tt {
+		leaves[i] = ReverseBytes(tx.TxIDBytes())
+	}
+	return leaves
+}
+
+// merkleTreeLevels builds every level of the merkle tree for the provided
+// leaves, from the leaves themselves at index 0 up to the root. Where a level
+// has an odd number of nodes, the last node is paired with itself.
+func merkleTreeLevels(leaves [][]byte) [][][]byte {
+	levels := [][][]byte{leaves}
+
+	for len(levels[len(levels)-1]) > 1 {
+		level := levels[len(levels)-1]
+
+		parents := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i < len(level); i += 2 {
+			right := level[i]
+			if i+1 < len(level) {
+				right = level[i+1]
+			}
+			parents = append(parents, MerkleTreeParent(level[i], right))
+		}
+
+		levels = append(levels, parents)
+	}
+
+	return levels
+}
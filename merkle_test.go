@@ -0,0 +1,271 @@
+package bt_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/testing/data"
+	"github.com/stretchr/testify/assert"
+)
+
+func loadTestBlock(t *testing.T) *bt.Block {
+	b, err := data.TxBinData.Load("block.bin")
+	assert.NoError(t, err)
+
+	blk, err := bt.NewBlockFromBytes(b)
+	assert.NoError(t, err)
+
+	return blk
+}
+
+func TestMerkleTreeParentStr(t *testing.T) {
+	t.Parallel()
+
+	// block 170, the first block with more than one tx
+	p, err := bt.MerkleTreeParentStr(
+		"b1fea52486ce0c62bb442b530a3f0132b826c74e473d1f2c220bfa78111c5082",
+		"f4184fc596403b9d638783cf57adfe4c75c605f6356fbc91338530e9831e9e16",
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "7dac2c5666815c17a3b36427de37bb9d2e2c5ccec3f8633eb91a4205cb4c10ff", p)
+}
+
+func TestBlock_CheckMerkleRoot(t *testing.T) {
+	t.Parallel()
+
+	blk := loadTestBlock(t)
+	assert.NoError(t, blk.CheckMerkleRoot())
+
+	blk.Txs = blk.Txs[1:]
+	assert.ErrorIs(t, blk.CheckMerkleRoot(), bt.ErrMerkleRootMismatch)
+
+	_, err := bt.Txs{}.MerkleRoot()
+	assert.ErrorIs(t, err, bt.ErrMerkleNoTxs)
+}
+
+func TestTxs_MerkleRoot_MvcTxID(t *testing.T) {
+	t.Parallel()
+
+	tx := bt.NewTx()
+	tx.Version = 10
+	assert.NoError(t, tx.From("45be95d2f2c64e99518ffbbce03fb15a7758f20ee5eecf0df07938d977add71d", 0, "76a914c7c6987b6e2345a6b138e3384141520a0fbc18c588ac", 1000))
+	tx.Inputs[0].UnlockingScript = tx.Inputs[0].PreviousTxScript
+	assert.NoError(t, tx.PayToAddress("1NRoySJ9Lvby6DuE2UQYnyT67AASwNZxGb", 900))
+
+	root, err := bt.Txs{tx}.MerkleRoot()
+	assert.NoError(t, err)
+	assert.Equal(t, tx.MvcTxIDBytes(), root)
+	assert.NotEqual(t, hex.EncodeToString(bt.ReverseBytes(tx.Bytes())), hex.EncodeToString(root))
+}
+
+func TestTxs_MerkleProof(t *testing.T) {
+	t.Parallel()
+
+	blk := loadTestBlock(t)
+
+	for _, idx := range []int{0, 1, 2, 17, 300, len(blk.Txs) - 2, len(blk.Txs) - 1} {
+		mp, err := blk.Txs.MerkleProof(idx)
+		assert.NoError(t, err)
+		assert.Equal(t, blk.Txs[idx].TxID(), mp.TxOrID)
+
+		ok, err := mp.Verify(blk.Header)
+		assert.NoError(t, err)
+		assert.True(t, ok, "index %d", idx)
+
+		b, err := mp.Bytes()
+		assert.NoError(t, err)
+		mp2, err := bt.NewMerkleProofFromBytes(b)
+		assert.NoError(t, err)
+		assert.Equal(t, mp.Nodes, mp2.Nodes)
+		assert.Equal(t, mp.Index, mp2.Index)
+		assert.Equal(t, mp.TxOrID, mp2.TxOrID)
+		assert.Equal(t, mp.Target, mp2.Target)
+
+		bb, err := json.Marshal(mp)
+		assert.NoError(t, err)
+		var mp3 bt.MerkleProof
+		assert.NoError(t, json.Unmarshal(bb, &mp3))
+		assert.Equal(t, *mp, mp3)
+	}
+
+	t.Run("last tx uses duplicate nodes", func(t *testing.T) {
+		mp, err := blk.Txs.MerkleProof(len(blk.Txs) - 1)
+		assert.NoError(t, err)
+		assert.Contains(t, mp.Nodes, bt.MerkleProofDuplicateNode)
+	})
+
+	t.Run("full tx and header target", func(t *testing.T) {
+		mp, err := blk.Txs.MerkleProof(5)
+		assert.NoError(t, err)
+		mp.TxOrID = blk.Txs[5].String()
+		mp.TargetType = bt.MerkleProofTargetTypeHeader
+		mp.Target = blk.Header.String()
+
+		mp2, err := bt.NewMerkleProofFromString(mp.String())
+		assert.NoError(t, err)
+		assert.Equal(t, mp.TxOrID, mp2.TxOrID)
+
+		ok, err := mp2.Verify(blk.Header)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("block hash target", func(t *testing.T) {
+		mp, err := blk.Txs.MerkleProof(5)
+		assert.NoError(t, err)
+		mp.TargetType = bt.MerkleProofTargetTypeHash
+		mp.Target = blk.HashStr()
+
+		ok, err := mp.Verify(blk.Header)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		mp.Target = blk.Header.PrevHashStr()
+		ok, err = mp.Verify(blk.Header)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("wrong index", func(t *testing.T) {
+		mp, err := blk.Txs.MerkleProof(5)
+		assert.NoError(t, err)
+		mp.Index = 4
+
+		ok, err := mp.Verify(blk.Header)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("nil header", func(t *testing.T) {
+		mp, err := blk.Txs.MerkleProof(5)
+		assert.NoError(t, err)
+
+		_, err = mp.Verify(nil)
+		assert.ErrorIs(t, err, bt.ErrBlockHeaderNil)
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		_, err := blk.Txs.MerkleProof(len(blk.Txs))
+		assert.ErrorIs(t, err, bt.ErrMerkleTxNotFound)
+	})
+}
+
+func TestTxs_BUMP(t *testing.T) {
+	t.Parallel()
+
+	blk := loadTestBlock(t)
+
+	idxs := []int{0, 3, 4, 250, len(blk.Txs) - 1}
+	bump, err := blk.Txs.BUMP(123, idxs...)
+	assert.NoError(t, err)
+	assert.Len(t, bump.TxIDs(), len(idxs))
+
+	for _, idx := range idxs {
+		ok, err := bump.Verify(blk.Txs[idx].TxIDBytes(), blk.Header)
+		assert.NoError(t, err)
+		assert.True(t, ok, "index %d", idx)
+	}
+
+	_, err = bump.Verify(blk.Txs[10].TxIDBytes(), blk.Header)
+	assert.ErrorIs(t, err, bt.ErrMerkleTxNotFound)
+
+	_, err = bump.Verify(blk.Txs[0].TxIDBytes(), nil)
+	assert.ErrorIs(t, err, bt.ErrBlockHeaderNil)
+
+	t.Run("sibling hash not flagged as txid", func(t *testing.T) {
+		// tx 2 is held at level 0 as the sibling of tx 3, but is not a proven txid.
+		_, err := bump.MerkleRoot(blk.Txs[2].TxIDBytes())
+		assert.ErrorIs(t, err, bt.ErrMerkleTxNotFound)
+	})
+
+	t.Run("binary round trip", func(t *testing.T) {
+		bump2, err := bt.NewBUMPFromString(bump.String())
+		assert.NoError(t, err)
+		assert.Equal(t, bump, bump2)
+	})
+
+	t.Run("json round trip", func(t *testing.T) {
+		bb, err := json.Marshal(bump)
+		assert.NoError(t, err)
+
+		var bump2 bt.BUMP
+		assert.NoError(t, json.Unmarshal(bb, &bump2))
+		assert.Equal(t, *bump, bump2)
+	})
+
+	t.Run("single tx block", func(t *testing.T) {
+		txs := blk.Txs[:1]
+		bump, err := txs.BUMP(1, 0)
+		assert.NoError(t, err)
+
+		root, err := bump.MerkleRoot(txs[0].TxIDBytes())
+		assert.NoError(t, err)
+		assert.Equal(t, txs[0].TxIDBytes(), root)
+	})
+}
+
+func TestNewBUMPFromBytes_Malformed(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string][]byte{
+		"empty":           {},
+		"no tree height":  {0x01},
+		"truncated level": {0x01, 0x01, 0x02, 0x00, 0x02},
+		"huge leaf count": {0x01, 0x01, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f},
+		"max leaf count":  {0x01, 0x01, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		"unknown flags":   {0x01, 0x01, 0x01, 0x00, 0x07},
+	}
+	for name, b := range tests {
+		b := b
+		t.Run(name, func(t *testing.T) {
+			_, err := bt.NewBUMPFromBytes(b)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNewMerkleProofFromBytes_Malformed(t *testing.T) {
+	t.Parallel()
+
+	hash := make([]byte, 32)
+	proof := func(b ...[]byte) []byte {
+		var p []byte
+		for _, bb := range b {
+			p = append(p, bb...)
+		}
+		return p
+	}
+
+	tests := map[string][]byte{
+		"empty":              {},
+		"huge tx length":     {0x01, 0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f},
+		"max tx length":      {0x01, 0x00, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		"truncated tx":       {0x01, 0x00, 0x0a, 0x01, 0x02},
+		"truncated target":   proof([]byte{0x00, 0x00}, hash, hash[:10]),
+		"huge node count":    proof([]byte{0x00, 0x00}, hash, hash, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}),
+		"truncated node":     proof([]byte{0x00, 0x00}, hash, hash, []byte{0x01, 0x00}, hash[:5]),
+		"unknown node type":  proof([]byte{0x00, 0x00}, hash, hash, []byte{0x01, 0x05}),
+		"invalid targettype": proof([]byte{0x06, 0x00}, hash, hash, []byte{0x00}),
+	}
+	for name, b := range tests {
+		b := b
+		t.Run(name, func(t *testing.T) {
+			_, err := bt.NewMerkleProofFromBytes(b)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestMerkleProof_Bytes_InvalidNode(t *testing.T) {
+	t.Parallel()
+
+	mp := &bt.MerkleProof{
+		TxOrID: hex.EncodeToString(make([]byte, 32)),
+		Target: hex.EncodeToString(make([]byte, 32)),
+		Nodes:  []string{"abcd"},
+	}
+	_, err := mp.Bytes()
+	assert.ErrorIs(t, err, bt.ErrMerkleProofInvalid)
+}
@@ -0,0 +1,403 @@
+package bt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+/*
+TSC merkle proof binary format
+--------------------------------------------------------
+Field            Description                                                               Size
+
+flags            bit 0: txOrId holds the full tx rather than the txid                      1 byte
+                 bits 1-2: target type (00 block hash, 01 header, 10 merkle root)
+                 bit 3: proof type (0 branch, 1 tree)
+                 bit 4: composite proof
+
+index            index of the tx in the block                                              1 - 9 bytes VI = VarInt
+
+txLength         length of the tx, only present when flags bit 0 is set                    1 - 9 bytes VI = VarInt
+
+txOrId           the full tx or the txid                                                   <txLength> or 32 bytes
+
+target           the block hash, merkle root (32 bytes) or the block header (80 bytes)     32 or 80 bytes
+
+nodeCount        the number of nodes in the proof                                          1 - 9 bytes VI = VarInt
+
+nodes            each prefixed with a type byte, 0 for a hash, 1 for a duplicate           <nodeCount>-many nodes
+                 of the working hash and 2 for an index
+--------------------------------------------------------
+See https://tsc.bitcoinassociation.net/standards/merkle-proof-standardised-format/
+*/
+
+// Merkle proof target types.
+const (
+	MerkleProofTargetTypeHash       = "hash"
+	MerkleProofTargetTypeHeader     = "header"
+	MerkleProofTargetTypeMerkleRoot = "merkleRoot"
+)
+
+// Merkle proof types.
+const (
+	MerkleProofTypeBranch = "branch"
+	MerkleProofTypeTree   = "tree"
+)
+
+// MerkleProofDuplicateNode is the node value used to signal that the working
+// hash is to be paired with itself.
+const MerkleProofDuplicateNode = "*"
+
+const (
+	merkleProofFlagTx         = 0x01
+	merkleProofFlagTargetMask = 0x06
+	merkleProofFlagHeader     = 0x02
+	merkleProofFlagRoot       = 0x04
+	merkleProofFlagTree       = 0x08
+	merkleProofFlagComposite  = 0x10
+
+	merkleProofNodeHash      = 0x00
+	merkleProofNodeDuplicate = 0x01
+	merkleProofNodeIndex     = 0x02
+)
+
+// MerkleProof is a TSC format merkle proof, proving the inclusion of a tx in a
+// block. TxOrID, Target and Nodes are hex encoded, with hashes in display order.
+//
+// The JSON encoding of this struct matches the TSC JSON format.
+type MerkleProof struct {
+	Index      uint64   `json:"index"`
+	TxOrID     string   `json:"txOrId"`
+	Target     string   `json:"target"`
+	TargetType string   `json:"targetType,omitempty"`
+	ProofType  string   `json:"proofType,omitempty"`
+	Composite  bool     `json:"composite,omitempty"`
+	Nodes      []string `json:"nodes"`
+}
+
+// NewMerkleProofFromString takes a hex string representation of a binary TSC
+// merkle proof and returns a MerkleProof object.
+func NewMerkleProofFromString(str string) (*MerkleProof, error) {
+	bb, err := hex.DecodeString(str)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMerkleProofFromBytes(bb)
+}
+
+// NewMerkleProofFromBytes takes an array of bytes of a binary TSC merkle proof,
+// constructs a MerkleProof and returns it.
+func NewMerkleProofFromBytes(b []byte) (*MerkleProof, error) {
+	mp := &MerkleProof{}
+	n, err := mp.ReadFrom(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	if int(n) != len(b) {
+		return nil, fmt.Errorf("%w: read %d of %d bytes", ErrMerkleProofTrailingData, n, len(b))
+	}
+
+	return mp, nil
+}
+
+// ReadFrom reads a binary TSC merkle proof from the `io.Reader` into the `bt.MerkleProof`.
+func (mp *MerkleProof) ReadFrom(r io.Reader) (int64, error) {
+	*mp = MerkleProof{}
+	var bytesRead int64
+
+	flags := make([]byte, 1)
+	n, err := io.ReadFull(r, flags)
+	bytesRead += int64(n)
+	if err != nil {
+		return bytesRead, errors.Wrapf(err, "flags(1): got %d bytes", n)
+	}
+
+	var index VarInt
+	n64, err := index.ReadFrom(r)
+	bytesRead += n64
+	if err != nil {
+		return bytesRead, err
+	}
+	mp.Index = uint64(index)
+
+	if flags[0]&merkleProofFlagTx != 0 {
+		var l VarInt
+		n64, err = l.ReadFrom(r)
+		bytesRead += n64
+		if err != nil {
+			return bytesRead, err
+		}
+
+		// Read through a LimitReader rather than allocating l bytes up front, as l
+		// comes straight off the wire.
+		var tx []byte
+		tx, err = io.ReadAll(io.LimitReader(r, int64(l)))
+		bytesRead += int64(len(tx))
+		if err != nil {
+			return bytesRead, errors.Wrapf(err, "tx(%d): got %d bytes", l, len(tx))
+		}
+		if uint64(len(tx)) != uint64(l) {
+			return bytesRead, errors.Wrapf(io.ErrUnexpectedEOF, "tx(%d): got %d bytes", l, len(tx))
+		}
+		mp.TxOrID = hex.EncodeToString(tx)
+	} else {
+		txID := make([]byte, 32)
+		n, err = io.ReadFull(r, txID)
+		bytesRead += int64(n)
+		if err != nil {
+			return bytesRead, errors.Wrapf(err, "txid(32): got %d bytes", n)
+		}
+		mp.TxOrID = hex.EncodeToString(ReverseBytes(txID))
+	}
+
+	switch flags[0] & merkleProofFlagTargetMask {
+	case 0:
+		mp.TargetType = MerkleProofTargetTypeHash
+	case merkleProofFlagHeader:
+		mp.TargetType = MerkleProofTargetTypeHeader
+	case merkleProofFlagRoot:
+		mp.TargetType = MerkleProofTargetTypeMerkleRoot
+	default:
+		return bytesRead, fmt.Errorf("%w: invalid target type in flags %x", ErrMerkleProofInvalid, flags[0])
+	}
+
+	if mp.TargetType == MerkleProofTargetTypeHeader {
+		target := make([]byte, BlockHeaderLen)
+		n, err = io.ReadFull(r, target)
+		bytesRead += int64(n)
+		if err != nil {
+			return bytesRead, errors.Wrapf(err, "target(%d): got %d bytes", BlockHeaderLen, n)
+		}
+		mp.Target = hex.EncodeToString(target)
+	} else {
+		target := make([]byte, 32)
+		n, err = io.ReadFull(r, target)
+		bytesRead += int64(n)
+		if err != nil {
+			return bytesRead, errors.Wrapf(err, "target(32): got %d bytes", n)
+		}
+		mp.Target = hex.EncodeToString(ReverseBytes(target))
+	}
+
+	mp.ProofType = MerkleProofTypeBranch
+	if flags[0]&merkleProofFlagTree != 0 {
+		mp.ProofType = MerkleProofTypeTree
+	}
+	mp.Composite = flags[0]&merkleProofFlagComposite != 0
+
+	var nodeCount VarInt
+	n64, err = nodeCount.ReadFrom(r)
+	bytesRead += n64
+	if err != nil {
+		return bytesRead, err
+	}
+
+	mp.Nodes = make([]string, 0)
+	for i := uint64(0); i < uint64(nodeCount); i++ {
+		nodeType := make([]byte, 1)
+		n, err = io.ReadFull(r, nodeType)
+		bytesRead += int64(n)
+		if err != nil {
+			return bytesRead, errors.Wrapf(err, "nodeType(1): got %d bytes", n)
+		}
+
+		switch nodeType[0] {
+		case merkleProofNodeHash:
+			node := make([]byte, 32)
+			n, err = io.ReadFull(r, node)
+			bytesRead += int64(n)
+			if err != nil {
+				return bytesRead, errors.Wrapf(err, "node(32): got %d bytes", n)
+			}
+			mp.Nodes = append(mp.Nodes, hex.EncodeToString(ReverseBytes(node)))
+		case merkleProofNodeDuplicate:
+			mp.Nodes = append(mp.Nodes, MerkleProofDuplicateNode)
+		case merkleProofNodeIndex:
+			return bytesRead, fmt.Errorf("%w: index nodes", ErrMerkleProofUnsupported)
+		default:
+			return bytesRead, fmt.Errorf("%w: unknown node type %x", ErrMerkleProofInvalid, nodeType[0])
+		}
+	}
+
+	return bytesRead, nil
+}
+
+// Bytes encodes the merkle proof into the binary TSC format.
+func (mp *MerkleProof) Bytes() ([]byte, error) {
+	var flags byte
+
+	txOrID, err := hex.DecodeString(mp.TxOrID)
+	if err != nil {
+		return nil, errors.Wrap(err, "txOrId")
+	}
+	if len(txOrID) != 32 {
+		flags |= merkleProofFlagTx
+	}
+
+	target, err := hex.DecodeString(mp.Target)
+	if err != nil {
+		return nil, errors.Wrap(err, "target")
+	}
+
+	switch mp.TargetType {
+	case "", MerkleProofTargetTypeHash:
+	case MerkleProofTargetTypeHeader:
+		flags |= merkleProofFlagHeader
+	case MerkleProofTargetTypeMerkleRoot:
+		flags |= merkleProofFlagRoot
+	default:
+		return nil, fmt.Errorf("%w: unknown target type '%s'", ErrMerkleProofInvalid, mp.TargetType)
+	}
+
+	if mp.ProofType == MerkleProofTypeTree {
+		flags |= merkleProofFlagTree
+	}
+	if mp.Composite {
+		flags |= merkleProofFlagComposite
+	}
+
+	h := []byte{flags}
+	h = append(h, VarInt(mp.Index).Bytes()...)
+
+	if flags&merkleProofFlagTx != 0 {
+		h = append(h, VarInt(uint64(len(txOrID))).Bytes()...)
+		h = append(h, txOrID...)
+	} else {
+		h = append(h, ReverseBytes(txOrID)...)
+	}
+
+	if flags&merkleProofFlagHeader != 0 {
+		h = append(h, target...)
+	} else {
+		h = append(h, ReverseBytes(target)...)
+	}
+
+	h = append(h, VarInt(uint64(len(mp.Nodes))).Bytes()...)
+	for _, node := range mp.Nodes {
+		if node == MerkleProofDuplicateNode {
+			h = append(h, merkleProofNodeDuplicate)
+			continue
+		}
+
+		nb, err := hex.DecodeString(node)
+		if err != nil {
+			return nil, errors.Wrapf(err, "node '%s'", node)
+		}
+		if len(nb) != 32 {
+			return nil, fmt.Errorf("%w: node '%s' is not 32 bytes", ErrMerkleProofInvalid, node)
+		}
+		h = append(h, merkleProofNodeHash)
+		h = append(h, ReverseBytes(nb)...)
+	}
+
+	return h, nil
+}
+
+// String encodes the merkle proof into the binary TSC format as a hex string.
+func (mp *MerkleProof) String() string {
+	b, err := mp.Bytes()
+	if err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// TxID returns the txid being proven, in display order. If the proof holds the
+// full tx, the txid is calculated from it, taking into account MVC txids for txs
+// of version 10 and above.
+func (mp *MerkleProof) TxID() ([]byte, error) {
+	if len(mp.TxOrID) == 64 {
+		return hex.DecodeString(mp.TxOrID)
+	}
+
+	tx, err := NewTxFromString(mp.TxOrID)
+	if err != nil {
+		return nil, errors.Wrap(err, "txOrId")
+	}
+
+	return tx.TxIDBytes(), nil
+}
+
+// MerkleRoot calculates the merkle root from the txid and the nodes of the proof,
+// in display order.
+func (mp *MerkleProof) MerkleRoot() ([]byte, error) {
+	if mp.ProofType != "" && mp.ProofType != MerkleProofTypeBranch {
+		return nil, fmt.Errorf("%w: proof type '%s'", ErrMerkleProofUnsupported, mp.ProofType)
+	}
+	if mp.Composite {
+		return nil, fmt.Errorf("%w: composite proofs", ErrMerkleProofUnsupported)
+	}
+
+	txID, err := mp.TxID()
+	if err != nil {
+		return nil, err
+	}
+
+	working := ReverseBytes(txID)
+	index := mp.Index
+	for _, node := range mp.Nodes {
+		sibling := working
+		if node != MerkleProofDuplicateNode {
+			nb, err := hex.DecodeString(node)
+			if err != nil {
+				return nil, errors.Wrapf(err, "node '%s'", node)
+			}
+			if len(nb) != 32 {
+				return nil, fmt.Errorf("%w: node '%s' is not 32 bytes", ErrMerkleProofInvalid, node)
+			}
+			sibling = ReverseBytes(nb)
+		} else if index&1 != 0 {
+			return nil, fmt.Errorf("%w: duplicate node on the left of the branch", ErrMerkleProofInvalid)
+		}
+
+		if index&1 != 0 {
+			working = MerkleTreeParent(sibling, working)
+		} else {
+			working = MerkleTreeParent(working, sibling)
+		}
+		index >>= 1
+	}
+
+	if index != 0 {
+		return nil, fmt.Errorf("%w: index %d is beyond the depth of the branch", ErrMerkleProofInvalid, mp.Index)
+	}
+
+	return ReverseBytes(working), nil
+}
+
+// Verify calculates the merkle root of the proof and checks it against the
+// provided block header. The target of the proof is also checked against the
+// header, according to its target type.
+//
+// False is returned if the proof does not match the header, an error is only
+// returned if the proof is malformed or unsupported, or if the header is nil.
+func (mp *MerkleProof) Verify(bh *BlockHeader) (bool, error) {
+	if bh == nil {
+		return false, ErrBlockHeaderNil
+	}
+
+	root, err := mp.MerkleRoot()
+	if err != nil {
+		return false, err
+	}
+
+	if !bytes.Equal(root, bh.MerkleRoot) {
+		return false, nil
+	}
+
+	switch mp.TargetType {
+	case "", MerkleProofTargetTypeHash:
+		return mp.Target == bh.HashStr(), nil
+	case MerkleProofTargetTypeHeader:
+		return mp.Target == bh.String(), nil
+	case MerkleProofTargetTypeMerkleRoot:
+		return mp.Target == hex.EncodeToString(root), nil
+	}
+
+	return false, fmt.Errorf("%w: unknown target type '%s'", ErrMerkleProofInvalid, mp.TargetType)
+}
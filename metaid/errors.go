@@ -0,0 +1,34 @@
+package metaid
+
+import "github.com/pkg/errors"
+
+// Sentinel errors reported by MetaID nodes.
+//
+// When NewNodeFromScript finds an invalid public key, parent txid or encrypt flag,
+// it returns an error matching both ErrNotMetaID and the matching error below.
+var (
+	ErrNotMetaID        = errors.New("script is not a MetaID node")
+	ErrNoMetaIDOutput   = errors.New("tx has no MetaID node output")
+	ErrInvalidPublicKey = errors.New("invalid node public key")
+	ErrInvalidParent    = errors.New("invalid node parent txid")
+	ErrNoNodeName       = errors.New("node name is required")
+	ErrInvalidEncrypt   = errors.New("invalid node encrypt flag")
+)
+
+// notMetaIDError explains why a script is not a MetaID node, matching both
+// ErrNotMetaID and the error it wraps.
+type notMetaIDError struct {
+	err error
+}
+
+func (e notMetaIDError) Error() string {
+	return ErrNotMetaID.Error() + ": " + e.err.Error()
+}
+
+func (e notMetaIDError) Unwrap() error {
+	return e.err
+}
+
+func (e notMetaIDError) Is(target error) bool {
+	return target == ErrNotMetaID
+}
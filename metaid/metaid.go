@@ -0,0 +1,231 @@
+// Package metaid builds and parses MetaID nodes, the OP_RETURN outputs with which
+// MVC applications link user data into a tree rooted at each user's MetaID.
+//
+// A node output has the layout:
+//
+//	OP_FALSE OP_RETURN <flag> <public key> <parent> metaid <node name> <data> <encrypt> <version> <content type> <encoding>
+//
+// Every field but the data is UTF-8 text: the public key and parent txid are hex,
+// and encrypt is "0" or "1". The root node of a MetaID has the parent "NULL", and
+// its txid is the MetaID itself; every other node names the txid of its parent node,
+// optionally prefixed by the chain it is on, "mvc:<txid>", and so links back to the root.
+//
+// A typical flow is:
+//
+//	err := metaid.AddNodeOutput(tx, &metaid.Node{
+//		PublicKey:  pubKey,
+//		ParentTxID: infoTxID,
+//		Name:       "name",
+//		Data:       []byte("satoshi"),
+//	})
+//	...
+//	node, vout, err := metaid.NewNodeFromTx(tx)
+package metaid
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+)
+
+// Values of the fixed and default node fields.
+const (
+	// ProtocolFlag is the flag written to node outputs on MVC. Nodes with the flag
+	// "meta", used by MetaID elsewhere, are also parsed.
+	ProtocolFlag = "mvc"
+
+	// NullParent is the parent of a root node.
+	NullParent = "NULL"
+
+	// RootNodeName is the name of the root node of a MetaID.
+	RootNodeName = "Root"
+
+	DefaultVersion     = "1.0.0"
+	DefaultContentType = "text/plain"
+	DefaultEncoding    = "UTF-8"
+
+	// EncodingBinary is the encoding of node data which is not text.
+	EncodingBinary = "binary"
+)
+
+// nodePattern matches a node output, capturing each of its fields.
+var nodePattern = bscript.MustNewPattern("OP_FALSE OP_RETURN <flag:data> <pubkey:data> <parent:data> " +
+	hex.EncodeToString([]byte("metaid")) +
+	" <name:data> <body:data> <encrypt:data> <version:data> <type:data> <encoding:data>")
+
+// Node is a MetaID node.
+type Node struct {
+	// PublicKey is the public key of the node, in compressed or uncompressed format.
+	PublicKey []byte
+
+	// ParentChain is the chain the parent node is on, which may be empty if it is on
+	// the same chain.
+	ParentChain string
+
+	// ParentTxID is the txid of the parent node, or empty if this is a root node.
+	ParentTxID string
+
+	Name string
+	Data []byte
+
+	// Encrypted is true if the data is encrypted.
+	Encrypted bool
+
+	// Version, ContentType and Encoding default to DefaultVersion, DefaultContentType
+	// and DefaultEncoding, respectively, when the node is written.
+	Version     string
+	ContentType string
+	Encoding    string
+}
+
+// NewRootNode returns the root node of a new MetaID, owned by the public key.
+func NewRootNode(pubKey []byte) *Node {
+	return &Node{
+		PublicKey: pubKey,
+		Name:      RootNodeName,
+	}
+}
+
+// IsRoot returns true if the node is the root of a MetaID, having no parent.
+func (n *Node) IsRoot() bool {
+	return n.ParentTxID == ""
+}
+
+// LockingScript returns the OP_FALSE OP_RETURN locking script of the node.
+func (n *Node) LockingScript() (*bscript.Script, error) {
+	if len(n.PublicKey) != 33 && len(n.PublicKey) != 65 {
+		return nil, fmt.Errorf("%w: %d bytes", ErrInvalidPublicKey, len(n.PublicKey))
+	}
+	if n.Name == "" {
+		return nil, ErrNoNodeName
+	}
+
+	parent := NullParent
+	if !n.IsRoot() {
+		if b, err := hex.DecodeString(n.ParentTxID); err != nil || len(b) != 32 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidParent, n.ParentTxID)
+		}
+		parent = n.ParentTxID
+		if n.ParentChain != "" {
+			parent = n.ParentChain + ":" + parent
+		}
+	}
+
+	encrypt := "0"
+	if n.Encrypted {
+		encrypt = "1"
+	}
+
+	o, err := bt.CreateOpReturnOutput([][]byte{
+		[]byte(ProtocolFlag),
+		[]byte(hex.EncodeToString(n.PublicKey)),
+		[]byte(parent),
+		[]byte("metaid"),
+		[]byte(n.Name),
+		n.Data,
+		[]byte(encrypt),
+		[]byte(orDefault(n.Version, DefaultVersion)),
+		[]byte(orDefault(n.ContentType, DefaultContentType)),
+		[]byte(orDefault(n.Encoding, DefaultEncoding)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return o.LockingScript, nil
+}
+
+// AddNodeOutput adds the node to the tx as a zero satoshi output.
+func AddNodeOutput(tx *bt.Tx, n *Node) error {
+	s, err := n.LockingScript()
+	if err != nil {
+		return err
+	}
+	tx.AddOutput(&bt.Output{LockingScript: s})
+
+	return nil
+}
+
+// IsNode returns true if the script is a MetaID node output.
+func IsNode(s *bscript.Script) bool {
+	_, err := NewNodeFromScript(s)
+	return err == nil
+}
+
+// NewNodeFromScript parses a node from its locking script, returning ErrNotMetaID
+// if the script is not a well formed node.
+func NewNodeFromScript(s *bscript.Script) (*Node, error) {
+	cc, ok := nodePattern.Match(s)
+	if !ok {
+		return nil, ErrNotMetaID
+	}
+	field := func(name string) string {
+		c, _ := cc.Get(name)
+		return string(c.Data())
+	}
+
+	if flag := field("flag"); flag != ProtocolFlag && flag != "meta" {
+		return nil, fmt.Errorf("%w: unknown protocol flag %q", ErrNotMetaID, flag)
+	}
+
+	pubKey, err := hex.DecodeString(field("pubkey"))
+	if err != nil || (len(pubKey) != 33 && len(pubKey) != 65) {
+		return nil, notMetaIDError{ErrInvalidPublicKey}
+	}
+
+	n := &Node{
+		PublicKey:   pubKey,
+		Name:        field("name"),
+		Version:     field("version"),
+		ContentType: field("type"),
+		Encoding:    field("encoding"),
+	}
+	if body, _ := cc.Get("body"); len(body.Data()) > 0 {
+		n.Data = body.Data()
+	}
+
+	if parent := field("parent"); parent != NullParent {
+		if i := strings.LastIndexByte(parent, ':'); i >= 0 {
+			n.ParentChain, parent = parent[:i], parent[i+1:]
+		}
+		if b, err := hex.DecodeString(parent); err != nil || len(b) != 32 {
+			return nil, notMetaIDError{ErrInvalidParent}
+		}
+		n.ParentTxID = parent
+	}
+
+	switch field("encrypt") {
+	case "0":
+	case "1":
+		n.Encrypted = true
+	default:
+		return nil, notMetaIDError{ErrInvalidEncrypt}
+	}
+
+	return n, nil
+}
+
+// NewNodeFromTx parses the first MetaID node output of the tx, returning it along
+// with its output index.
+func NewNodeFromTx(tx *bt.Tx) (*Node, int, error) {
+	for i, o := range tx.Outputs {
+		if o.LockingScript == nil {
+			continue
+		}
+		if n, err := NewNodeFromScript(o.LockingScript); err == nil {
+			return n, i, nil
+		}
+	}
+
+	return nil, -1, ErrNoMetaIDOutput
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
@@ -0,0 +1,165 @@
+package metaid_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+	"github.com/mvc-labs/mvc-lib-go/metaid"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testPubKey   = "026cf33373a9f3f6c676b75b543180703df225f7f8edbffedc417718a8ad4e89ce"
+	testParentID = "45be95d2f2c64e99518ffbbce03fb15a7758f20ee5eecf0df07938d977add71d"
+)
+
+func TestNode_LockingScript(t *testing.T) {
+	t.Parallel()
+
+	pubKey, err := hex.DecodeString(testPubKey)
+	assert.NoError(t, err)
+
+	t.Run("root", func(t *testing.T) {
+		s, err := metaid.NewRootNode(pubKey).LockingScript()
+		assert.NoError(t, err)
+
+		expASM := "0 OP_RETURN 'mvc' '" + testPubKey + "' 'NULL' 'metaid' 'Root' 0 '0' '1.0.0' 'text/plain' 'UTF-8'"
+		exp, err := bscript.ParseASM(expASM)
+		assert.NoError(t, err)
+		assert.Equal(t, exp, s)
+		assert.True(t, metaid.IsNode(s))
+	})
+
+	t.Run("child round trips", func(t *testing.T) {
+		n := &metaid.Node{
+			PublicKey:   pubKey,
+			ParentChain: "mvc",
+			ParentTxID:  testParentID,
+			Name:        "avatar",
+			Data:        []byte{0x89, 0x50, 0x4e, 0x47},
+			Encrypted:   true,
+			Version:     "1.0.1",
+			ContentType: "image/png",
+			Encoding:    metaid.EncodingBinary,
+		}
+
+		tx := bt.NewTx()
+		assert.NoError(t, tx.PayToAddress("1NRoySJ9Lvby6DuE2UQYnyT67AASwNZxGb", 1000))
+		assert.NoError(t, metaid.AddNodeOutput(tx, n))
+		assert.Equal(t, uint64(0), tx.Outputs[1].Satoshis)
+
+		got, vout, err := metaid.NewNodeFromTx(tx)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, vout)
+		assert.Equal(t, n, got)
+		assert.False(t, got.IsRoot())
+	})
+
+	tests := map[string]struct {
+		node   *metaid.Node
+		expErr error
+	}{
+		"invalid public key": {
+			node:   &metaid.Node{PublicKey: pubKey[1:], Name: "name"},
+			expErr: metaid.ErrInvalidPublicKey,
+		},
+		"no name": {
+			node:   &metaid.Node{PublicKey: pubKey},
+			expErr: metaid.ErrNoNodeName,
+		},
+		"invalid parent": {
+			node:   &metaid.Node{PublicKey: pubKey, ParentTxID: "abcd", Name: "name"},
+			expErr: metaid.ErrInvalidParent,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := test.node.LockingScript()
+			assert.ErrorIs(t, err, test.expErr)
+		})
+	}
+}
+
+func TestNewNodeFromScript(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		asm     string
+		expNode *metaid.Node
+		expErr  error
+		// expCause is the reason the script is not a node, if any.
+		expCause error
+	}{
+		"meta flag and plain parent": {
+			asm: "0 OP_RETURN 'meta' '" + testPubKey + "' '" + testParentID + "' 'metaid' 'name' 'satoshi' '0' '1.0.0' 'text/plain' 'UTF-8'",
+			expNode: &metaid.Node{
+				PublicKey:   mustDecodeHex(t, testPubKey),
+				ParentTxID:  testParentID,
+				Name:        "name",
+				Data:        []byte("satoshi"),
+				Version:     "1.0.0",
+				ContentType: "text/plain",
+				Encoding:    "UTF-8",
+			},
+		},
+		"unknown flag": {
+			asm:    "0 OP_RETURN 'bsv' '" + testPubKey + "' 'NULL' 'metaid' 'Root' 0 '0' '1.0.0' 'text/plain' 'UTF-8'",
+			expErr: metaid.ErrNotMetaID,
+		},
+		"invalid public key": {
+			asm:      "0 OP_RETURN 'mvc' 'abcd' 'NULL' 'metaid' 'Root' 0 '0' '1.0.0' 'text/plain' 'UTF-8'",
+			expErr:   metaid.ErrNotMetaID,
+			expCause: metaid.ErrInvalidPublicKey,
+		},
+		"invalid encrypt": {
+			asm:      "0 OP_RETURN 'mvc' '" + testPubKey + "' 'NULL' 'metaid' 'Root' 0 'yes' '1.0.0' 'text/plain' 'UTF-8'",
+			expErr:   metaid.ErrNotMetaID,
+			expCause: metaid.ErrInvalidEncrypt,
+		},
+		"invalid parent": {
+			asm:      "0 OP_RETURN 'mvc' '" + testPubKey + "' 'mvc:abcd' 'metaid' 'name' 0 '0' '1.0.0' 'text/plain' 'UTF-8'",
+			expErr:   metaid.ErrNotMetaID,
+			expCause: metaid.ErrInvalidParent,
+		},
+		"missing field": {
+			asm:    "0 OP_RETURN 'mvc' '" + testPubKey + "' 'NULL' 'metaid' 'Root' 0 '0' '1.0.0' 'text/plain'",
+			expErr: metaid.ErrNotMetaID,
+		},
+		"not op return": {
+			asm:    "OP_DUP OP_HASH160 e2a623699e81b291c0327f408fea765d534baa2a OP_EQUALVERIFY OP_CHECKSIG",
+			expErr: metaid.ErrNotMetaID,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			s, err := bscript.ParseASM(test.asm)
+			assert.NoError(t, err)
+
+			n, err := metaid.NewNodeFromScript(s)
+			if test.expErr != nil {
+				assert.ErrorIs(t, err, test.expErr)
+				if test.expCause != nil {
+					assert.ErrorIs(t, err, test.expCause)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.expNode, n)
+		})
+	}
+
+	t.Run("no node in tx", func(t *testing.T) {
+		_, _, err := metaid.NewNodeFromTx(bt.NewTx())
+		assert.ErrorIs(t, err, metaid.ErrNoMetaIDOutput)
+	})
+}
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	assert.NoError(t, err)
+	return b
+}
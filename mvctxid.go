@@ -0,0 +1,287 @@
+package bt
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/mvc-labs/mvc-lib-go/keys/crypto"
+	"github.com/pkg/errors"
+)
+
+// Lengths of the parts of a tx which are hashed into its MvcTxID.
+const (
+	// MvcInputDataLen is the length of the data of each input hashed into the inputs
+	// hash: the outpoint it spends, followed by its sequence number.
+	MvcInputDataLen = 32 + 4 + 4
+
+	// MvcOutputDataLen is the length of the data of each output hashed into the
+	// outputs hash: its satoshis, followed by the sha256 of its locking script.
+	MvcOutputDataLen = 8 + 32
+
+	mvcTxIDComponentsLen = 4*4 + 3*32
+)
+
+// MvcTxIDComponents are the components of a tx which are hashed to give its MvcTxID,
+// allowing a contract, or anyone else, to check facts about a tx without having all
+// of it.
+type MvcTxIDComponents struct {
+	Version     uint32
+	LockTime    uint32
+	InputCount  uint32
+	OutputCount uint32
+
+	// InputsHash is the sha256 of the MvcInputData of every input.
+	InputsHash []byte
+
+	// UnlockingScriptsHash is the sha256 of the sha256 of every unlocking script.
+	UnlockingScriptsHash []byte
+
+	// OutputsHash is the sha256 of the MvcOutputData of every output.
+	OutputsHash []byte
+}
+
+// MvcTxIDComponents returns the components of the tx which are hashed into its MvcTxID.
+func (tx *Tx) MvcTxIDComponents() *MvcTxIDComponents {
+	inputsBuf := make([]byte, 0, len(tx.Inputs)*MvcInputDataLen)
+	inputs2Buf := make([]byte, 0, len(tx.Inputs)*32)
+	outputsBuf := make([]byte, 0, len(tx.Outputs)*MvcOutputDataLen)
+
+	for _, in := range tx.Inputs {
+		inputsBuf = append(inputsBuf, MvcInputData(in)...)
+
+		var unlockingScript []byte
+		if in.UnlockingScript != nil {
+			unlockingScript = *in.UnlockingScript
+		}
+		inputs2Buf = append(inputs2Buf, crypto.Sha256(unlockingScript)...)
+	}
+	for _, out := range tx.Outputs {
+		outputsBuf = append(outputsBuf, MvcOutputData(out)...)
+	}
+
+	return &MvcTxIDComponents{
+		Version:              tx.Version,
+		LockTime:             tx.LockTime,
+		InputCount:           uint32(len(tx.Inputs)),
+		OutputCount:          uint32(len(tx.Outputs)),
+		InputsHash:           crypto.Sha256(inputsBuf),
+		UnlockingScriptsHash: crypto.Sha256(inputs2Buf),
+		OutputsHash:          crypto.Sha256(outputsBuf),
+	}
+}
+
+// Bytes returns the serialised components, the double sha256 of which is the MvcTxID.
+func (c *MvcTxIDComponents) Bytes() []byte {
+	buf := make([]byte, 0, mvcTxIDComponentsLen)
+	buf = append(buf, LittleEndianBytes(c.Version, 4)...)
+	buf = append(buf, LittleEndianBytes(c.LockTime, 4)...)
+	buf = append(buf, LittleEndianBytes(c.InputCount, 4)...)
+	buf = append(buf, LittleEndianBytes(c.OutputCount, 4)...)
+	buf = append(buf, c.InputsHash...)
+	buf = append(buf, c.UnlockingScriptsHash...)
+	buf = append(buf, c.OutputsHash...)
+
+	return buf
+}
+
+// TxID returns the MvcTxID of the components, in display order.
+func (c *MvcTxIDComponents) TxID() []byte {
+	return ReverseBytes(crypto.Sha256d(c.Bytes()))
+}
+
+func newMvcTxIDComponentsFromBytes(b []byte) *MvcTxIDComponents {
+	return &MvcTxIDComponents{
+		Version:              binary.LittleEndian.Uint32(b[0:]),
+		LockTime:             binary.LittleEndian.Uint32(b[4:]),
+		InputCount:           binary.LittleEndian.Uint32(b[8:]),
+		OutputCount:          binary.LittleEndian.Uint32(b[12:]),
+		InputsHash:           append([]byte{}, b[16:48]...),
+		UnlockingScriptsHash: append([]byte{}, b[48:80]...),
+		OutputsHash:          append([]byte{}, b[80:112]...),
+	}
+}
+
+// MvcInputData returns the data of the input which is hashed into the inputs hash.
+func MvcInputData(in *Input) []byte {
+	buf := make([]byte, 0, MvcInputDataLen)
+	buf = append(buf, ReverseBytes(in.PreviousTxID())...)
+	buf = append(buf, LittleEndianBytes(in.PreviousTxOutIndex, 4)...)
+	buf = append(buf, LittleEndianBytes(in.SequenceNumber, 4)...)
+
+	return buf
+}
+
+// MvcOutputData returns the data of the output which is hashed into the outputs hash.
+func MvcOutputData(out *Output) []byte {
+	buf := make([]byte, 8, MvcOutputDataLen)
+	binary.LittleEndian.PutUint64(buf, out.Satoshis)
+
+	return append(buf, crypto.Sha256(*out.LockingScript)...)
+}
+
+// MvcTxIDProof proves that an input or output, at Index, belongs to a tx with a given
+// MvcTxID, being the components of the tx along with the data of every other input or
+// output, which is far smaller than the tx itself when it has large scripts.
+//
+// An output proof is created by Tx.MvcOutputProof and checked by VerifyOutput, and
+// an input proof, which covers the outpoint spent and sequence number of the input
+// but not its unlocking script, is created by Tx.MvcInputProof and checked by
+// VerifyInput.
+type MvcTxIDProof struct {
+	Components *MvcTxIDComponents
+	Index      uint32
+
+	// Before and After are the data of the inputs or outputs before and after Index.
+	Before []byte
+	After  []byte
+}
+
+// MvcOutputProof returns a proof that the output at the index belongs to the tx.
+func (tx *Tx) MvcOutputProof(index int) (*MvcTxIDProof, error) {
+	if index < 0 || index >= len(tx.Outputs) {
+		return nil, ErrOutputNoExist
+	}
+
+	p := &MvcTxIDProof{Components: tx.MvcTxIDComponents(), Index: uint32(index)}
+	for i, out := range tx.Outputs {
+		switch {
+		case i < index:
+			p.Before = append(p.Before, MvcOutputData(out)...)
+		case i > index:
+			p.After = append(p.After, MvcOutputData(out)...)
+		}
+	}
+
+	return p, nil
+}
+
+// MvcInputProof returns a proof that the input at the index belongs to the tx.
+func (tx *Tx) MvcInputProof(index int) (*MvcTxIDProof, error) {
+	if index < 0 || index >= len(tx.Inputs) {
+		return nil, ErrInputNoExist
+	}
+
+	p := &MvcTxIDProof{Components: tx.MvcTxIDComponents(), Index: uint32(index)}
+	for i, in := range tx.Inputs {
+		switch {
+		case i < index:
+			p.Before = append(p.Before, MvcInputData(in)...)
+		case i > index:
+			p.After = append(p.After, MvcInputData(in)...)
+		}
+	}
+
+	return p, nil
+}
+
+// VerifyOutput checks that the output is at the proof's index in the outputs of the
+// tx with the MvcTxID, given in display order, returning ErrMvcProofInvalid if not.
+func (p *MvcTxIDProof) VerifyOutput(txID []byte, out *Output) error {
+	if err := p.verify(txID, p.Components.OutputCount, MvcOutputDataLen); err != nil {
+		return err
+	}
+	h := crypto.Sha256(concat(p.Before, MvcOutputData(out), p.After))
+	if !bytes.Equal(h, p.Components.OutputsHash) {
+		return errors.Wrap(ErrMvcProofInvalid, "outputs hash does not match")
+	}
+
+	return nil
+}
+
+// VerifyInput checks that the input, by the outpoint it spends and its sequence
+// number, is at the proof's index in the inputs of the tx with the MvcTxID, given in
+// display order, returning ErrMvcProofInvalid if not.
+func (p *MvcTxIDProof) VerifyInput(txID []byte, in *Input) error {
+	if err := p.verify(txID, p.Components.InputCount, MvcInputDataLen); err != nil {
+		return err
+	}
+	h := crypto.Sha256(concat(p.Before, MvcInputData(in), p.After))
+	if !bytes.Equal(h, p.Components.InputsHash) {
+		return errors.Wrap(ErrMvcProofInvalid, "inputs hash does not match")
+	}
+
+	return nil
+}
+
+// verify checks the components hash to the txid and the data either side of the
+// index is the right length for count items of dataLen.
+func (p *MvcTxIDProof) verify(txID []byte, count uint32, dataLen int) error {
+	if p.Components == nil {
+		return errors.Wrap(ErrMvcProofInvalid, "no components")
+	}
+	if !bytes.Equal(p.Components.TxID(), txID) {
+		return errors.Wrap(ErrMvcProofInvalid, "components do not match txid")
+	}
+	if p.Index >= count || len(p.Before) != int(p.Index)*dataLen ||
+		len(p.After) != int(count-p.Index-1)*dataLen {
+		return errors.Wrapf(ErrMvcProofInvalid, "index %d of %d", p.Index, count)
+	}
+
+	return nil
+}
+
+// Bytes returns the serialised proof: the components, followed by the index, as a
+// 4 byte little endian number, and the data before and after it.
+func (p *MvcTxIDProof) Bytes() []byte {
+	buf := make([]byte, 0, mvcTxIDComponentsLen+4+len(p.Before)+len(p.After))
+	buf = append(buf, p.Components.Bytes()...)
+	buf = append(buf, LittleEndianBytes(p.Index, 4)...)
+	buf = append(buf, p.Before...)
+
+	return append(buf, p.After...)
+}
+
+// NewMvcOutputProofFromBytes parses a serialised output proof.
+func NewMvcOutputProofFromBytes(b []byte) (*MvcTxIDProof, error) {
+	return newMvcTxIDProofFromBytes(b, func(c *MvcTxIDComponents) uint32 { return c.OutputCount }, MvcOutputDataLen)
+}
+
+// NewMvcInputProofFromBytes parses a serialised input proof.
+func NewMvcInputProofFromBytes(b []byte) (*MvcTxIDProof, error) {
+	return newMvcTxIDProofFromBytes(b, func(c *MvcTxIDComponents) uint32 { return c.InputCount }, MvcInputDataLen)
+}
+
+func newMvcTxIDProofFromBytes(b []byte, count func(*MvcTxIDComponents) uint32, dataLen int) (*MvcTxIDProof, error) {
+	if len(b) < mvcTxIDComponentsLen+4 {
+		return nil, errors.Wrap(ErrMvcProofInvalid, "too short")
+	}
+
+	p := &MvcTxIDProof{
+		Components: newMvcTxIDComponentsFromBytes(b),
+		Index:      binary.LittleEndian.Uint32(b[mvcTxIDComponentsLen:]),
+	}
+	n := count(p.Components)
+	if p.Index >= n {
+		return nil, errors.Wrapf(ErrMvcProofInvalid, "index %d of %d", p.Index, n)
+	}
+
+	b = b[mvcTxIDComponentsLen+4:]
+	beforeLen, afterLen := uint64(p.Index)*uint64(dataLen), uint64(n-p.Index-1)*uint64(dataLen)
+	if uint64(len(b)) < beforeLen+afterLen {
+		return nil, errors.Wrap(ErrMvcProofInvalid, "too short")
+	}
+	if uint64(len(b)) > beforeLen+afterLen {
+		return nil, ErrMvcProofTrailingData
+	}
+	if beforeLen > 0 {
+		p.Before = append([]byte{}, b[:beforeLen]...)
+	}
+	if afterLen > 0 {
+		p.After = append([]byte{}, b[beforeLen:]...)
+	}
+
+	return p, nil
+}
+
+func concat(bb ...[]byte) []byte {
+	var n int
+	for _, b := range bb {
+		n += len(b)
+	}
+	buf := make([]byte, 0, n)
+	for _, b := range bb {
+		buf = append(buf, b...)
+	}
+
+	return buf
+}
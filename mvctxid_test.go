@@ -0,0 +1,138 @@
+package bt_test
+
+import (
+	"testing"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+	"github.com/stretchr/testify/assert"
+)
+
+func testMvcTx(t *testing.T) *bt.Tx {
+	tx := bt.NewTx()
+	tx.Version = 10
+	assert.NoError(t, tx.From(
+		"45be95d2f2c64e99518ffbbce03fb15a7758f20ee5eecf0df07938d977add71d",
+		2,
+		"76a914c0a3c167a28cabb9fbb495affa0761e6e74ac60d88ac",
+		1500,
+	))
+	assert.NoError(t, tx.From(
+		"3c8edde27cb9a9132c22038dac4391496be9db16fd21351565cc1006966fdad5",
+		0,
+		"76a914c0a3c167a28cabb9fbb495affa0761e6e74ac60d88ac",
+		1500,
+	))
+	assert.NoError(t, tx.PayToAddress("1NRoySJ9Lvby6DuE2UQYnyT67AASwNZxGb", 1000))
+	assert.NoError(t, tx.PayToAddress("1NRoySJ9Lvby6DuE2UQYnyT67AASwNZxGb", 1200))
+	assert.NoError(t, tx.AddOpReturnOutput([]byte("hello")))
+	tx.LockTime = 800000
+
+	return tx
+}
+
+func TestTx_MvcTxIDComponents(t *testing.T) {
+	t.Parallel()
+
+	tx := testMvcTx(t)
+	assert.Equal(t, "63562843ac4e4b17c44db68446bfc417c3d71785f9e277644211c58b3c958323", tx.TxID())
+
+	c := tx.MvcTxIDComponents()
+	assert.Equal(t, uint32(10), c.Version)
+	assert.Equal(t, uint32(800000), c.LockTime)
+	assert.Equal(t, uint32(2), c.InputCount)
+	assert.Equal(t, uint32(3), c.OutputCount)
+	assert.Len(t, c.Bytes(), 112)
+	assert.Equal(t, tx.TxIDBytes(), c.TxID())
+
+	// Unlocking scripts change the txid only through their hash.
+	tx.Inputs[0].UnlockingScript = bscript.NewFromBytes([]byte{0x51})
+	assert.NotEqual(t, c.UnlockingScriptsHash, tx.MvcTxIDComponents().UnlockingScriptsHash)
+	assert.Equal(t, c.InputsHash, tx.MvcTxIDComponents().InputsHash)
+	assert.Equal(t, c.OutputsHash, tx.MvcTxIDComponents().OutputsHash)
+}
+
+func TestMvcTxIDProof(t *testing.T) {
+	t.Parallel()
+
+	tx := testMvcTx(t)
+	txID := tx.TxIDBytes()
+
+	t.Run("outputs", func(t *testing.T) {
+		for i, out := range tx.Outputs {
+			p, err := tx.MvcOutputProof(i)
+			assert.NoError(t, err)
+			assert.NoError(t, p.VerifyOutput(txID, out))
+			assert.Len(t, p.Bytes(), 112+4+2*bt.MvcOutputDataLen)
+
+			pp, err := bt.NewMvcOutputProofFromBytes(p.Bytes())
+			assert.NoError(t, err)
+			assert.Equal(t, p, pp)
+			assert.NoError(t, pp.VerifyOutput(txID, out))
+		}
+
+		p, err := tx.MvcOutputProof(1)
+		assert.NoError(t, err)
+		assert.ErrorIs(t, p.VerifyOutput(txID, tx.Outputs[0]), bt.ErrMvcProofInvalid)
+		assert.ErrorIs(t, p.VerifyOutput(txID, &bt.Output{
+			LockingScript: tx.Outputs[1].LockingScript,
+			Satoshis:      tx.Outputs[1].Satoshis + 1,
+		}), bt.ErrMvcProofInvalid)
+		assert.ErrorIs(t, p.VerifyOutput(tx.Inputs[0].PreviousTxID(), tx.Outputs[1]), bt.ErrMvcProofInvalid)
+
+		p.Index = 0
+		assert.ErrorIs(t, p.VerifyOutput(txID, tx.Outputs[1]), bt.ErrMvcProofInvalid)
+
+		_, err = tx.MvcOutputProof(3)
+		assert.ErrorIs(t, err, bt.ErrOutputNoExist)
+	})
+
+	t.Run("inputs", func(t *testing.T) {
+		for i, in := range tx.Inputs {
+			p, err := tx.MvcInputProof(i)
+			assert.NoError(t, err)
+			assert.NoError(t, p.VerifyInput(txID, in))
+
+			pp, err := bt.NewMvcInputProofFromBytes(p.Bytes())
+			assert.NoError(t, err)
+			assert.Equal(t, p, pp)
+		}
+
+		p, err := tx.MvcInputProof(0)
+		assert.NoError(t, err)
+		assert.ErrorIs(t, p.VerifyInput(txID, tx.Inputs[1]), bt.ErrMvcProofInvalid)
+
+		_, err = tx.MvcInputProof(-1)
+		assert.ErrorIs(t, err, bt.ErrInputNoExist)
+	})
+
+	t.Run("invalid bytes", func(t *testing.T) {
+		p, err := tx.MvcOutputProof(1)
+		assert.NoError(t, err)
+		b := p.Bytes()
+
+		tests := map[string]struct {
+			b   []byte
+			err error
+		}{
+			"too short for components": {b: b[:100], err: bt.ErrMvcProofInvalid},
+			"too short for data":       {b: b[:len(b)-1], err: bt.ErrMvcProofInvalid},
+			"trailing data":            {b: append(append([]byte{}, b...), 0x00), err: bt.ErrMvcProofTrailingData},
+			"index out of range": {
+				b:   append(append(append([]byte{}, b[:112]...), 0x03, 0x00, 0x00, 0x00), b[116:]...),
+				err: bt.ErrMvcProofInvalid,
+			},
+		}
+		for name, test := range tests {
+			t.Run(name, func(t *testing.T) {
+				_, err := bt.NewMvcOutputProofFromBytes(test.b)
+				assert.ErrorIs(t, err, test.err)
+			})
+		}
+
+		// A valid output proof of a tx with 2 inputs and 3 outputs is the wrong
+		// length to be an input proof.
+		_, err = bt.NewMvcInputProofFromBytes(b)
+		assert.ErrorIs(t, err, bt.ErrMvcProofTrailingData)
+	})
+}
@@ -0,0 +1,8 @@
+package policy
+
+import "github.com/pkg/errors"
+
+// Sentinel errors reported by policy checks.
+var (
+	ErrNonStandard = errors.New("tx is non-standard")
+)
@@ -0,0 +1,273 @@
+// Package policy checks txs against the standardness rules nodes apply before
+// accepting a tx into their mempool and relaying it, so that a tx which would be
+// rejected can be caught before it is broadcast.
+//
+// Policy rules are stricter than, and separate to, the consensus rules checked by
+// the interpreter package: a non-standard tx can still be valid, and be mined, if
+// it reaches a miner which accepts it.
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+	"github.com/mvc-labs/mvc-lib-go/bscript/interpreter"
+	"github.com/pkg/errors"
+)
+
+// Node defaults for the policy rules, after genesis.
+const (
+	// DefaultMaxTxSize is the largest tx, in bytes, relayed by default.
+	DefaultMaxTxSize = 10000000
+
+	// maxPubKeysPerMultiSig is the number of sigops counted for an
+	// OP_CHECKMULTISIG not preceded by its number of public keys.
+	maxPubKeysPerMultiSig = 20
+)
+
+// Rule identifies the policy rule a Violation breaks.
+type Rule string
+
+// The policy rules.
+const (
+	// RuleTxSize is broken by a tx larger than Policy.MaxTxSize.
+	RuleTxSize Rule = "tx-size"
+
+	// RuleDust is broken by an output holding fewer than Policy.DustLimit
+	// satoshis. Provably unspendable OP_FALSE OP_RETURN outputs are exempt.
+	RuleDust Rule = "dust"
+
+	// RuleScriptType is broken by an output with a locking script of a type
+	// not in Policy.AllowedScriptTypes.
+	RuleScriptType Rule = "script-type"
+
+	// RulePushOnly is broken by an input with an unlocking script containing
+	// anything but pushes, when Policy.RequirePushOnly is set.
+	RulePushOnly Rule = "push-only"
+
+	// RuleDataSize is broken by a data output with a locking script larger
+	// than Policy.MaxDataSize.
+	RuleDataSize Rule = "data-size"
+
+	// RuleSigOps is broken by a tx with more signature operations than
+	// Policy.MaxSigOps.
+	RuleSigOps Rule = "sigops"
+
+	// RuleFee is broken by a tx paying too little fee for Policy.FeeQuote.
+	RuleFee Rule = "fee"
+)
+
+// Violation is a breach of a policy rule by a tx.
+type Violation struct {
+	Rule Rule
+
+	// Index is the index of the input, for RulePushOnly, or output, for
+	// RuleDust, RuleScriptType and RuleDataSize, breaking the rule. It is
+	// -1 for rules applying to the whole tx.
+	Index int
+
+	Message string
+}
+
+// String returns a description of the violation.
+func (v Violation) String() string {
+	if v.Index < 0 {
+		return fmt.Sprintf("%s: %s", v.Rule, v.Message)
+	}
+	return fmt.Sprintf("%s[%d]: %s", v.Rule, v.Index, v.Message)
+}
+
+// Violations are the policy rules broken by a tx.
+type Violations []Violation
+
+// Err returns nil if there are no violations, or ErrNonStandard describing them.
+func (vv Violations) Err() error {
+	if len(vv) == 0 {
+		return nil
+	}
+
+	ss := make([]string, 0, len(vv))
+	for _, v := range vv {
+		ss = append(ss, v.String())
+	}
+	return errors.Wrap(ErrNonStandard, strings.Join(ss, "; "))
+}
+
+// Has returns true if any violation breaks the rule.
+func (vv Violations) Has(r Rule) bool {
+	for _, v := range vv {
+		if v.Rule == r {
+			return true
+		}
+	}
+	return false
+}
+
+// Policy is a set of standardness rules. A zero limit disables the rule it
+// configures, so the zero value Policy checks nothing.
+type Policy struct {
+	// MaxTxSize is the largest tx size, in bytes.
+	MaxTxSize int
+
+	// DustLimit is the fewest satoshis an output can hold.
+	DustLimit uint64
+
+	// AllowedScriptTypes are the bscript.ScriptType(...)s allowed for
+	// locking scripts, with any type allowed if empty.
+	AllowedScriptTypes []string
+
+	// RequirePushOnly requires unlocking scripts to contain only pushes.
+	RequirePushOnly bool
+
+	// MaxDataSize is the largest locking script, in bytes, of a data output.
+	MaxDataSize int
+
+	// MaxSigOps is the most signature operations in the scripts of a tx.
+	MaxSigOps int
+
+	// FeeQuote, if set, is the fee quote the tx must pay enough fee for. The
+	// inputs of the tx must have their previous satoshis set.
+	FeeQuote *bt.FeeQuote
+}
+
+// NewPolicy returns the default policy of a node after genesis: txs of up to
+// DefaultMaxTxSize bytes, with outputs of at least bt.DustLimit satoshis, of any
+// script type, spent by push-only unlocking scripts. Data outputs and sigops are
+// not limited and fees are not checked.
+func NewPolicy() *Policy {
+	return &Policy{
+		MaxTxSize:       DefaultMaxTxSize,
+		DustLimit:       bt.DustLimit,
+		RequirePushOnly: true,
+	}
+}
+
+// Check checks the tx against every rule of the policy, returning the violations
+// found, or none if the tx is standard.
+//
+// Example usage:
+//
+//	if err := policy.NewPolicy().Check(tx).Err(); err != nil {
+//	    return err
+//	}
+func (p *Policy) Check(tx *bt.Tx) Violations {
+	var vv Violations
+
+	if p.MaxTxSize > 0 {
+		if size := tx.Size(); size > p.MaxTxSize {
+			vv = append(vv, Violation{
+				Rule:    RuleTxSize,
+				Index:   -1,
+				Message: fmt.Sprintf("size %d exceeds %d", size, p.MaxTxSize),
+			})
+		}
+	}
+
+	var sigOps int
+	for i, in := range tx.Inputs {
+		if in.UnlockingScript == nil {
+			continue
+		}
+		ps, err := (&interpreter.DefaultOpcodeParser{}).Parse(in.UnlockingScript)
+		if p.RequirePushOnly && (err != nil || !ps.IsPushOnly()) {
+			vv = append(vv, Violation{Rule: RulePushOnly, Index: i, Message: "unlocking script is not push only"})
+		}
+		sigOps += countSigOps(ps)
+	}
+
+	for i, out := range tx.Outputs {
+		vv = append(vv, p.checkOutput(i, out)...)
+		if out.LockingScript != nil {
+			ps, _ := (&interpreter.DefaultOpcodeParser{}).Parse(out.LockingScript)
+			sigOps += countSigOps(ps)
+		}
+	}
+
+	if p.MaxSigOps > 0 && sigOps > p.MaxSigOps {
+		vv = append(vv, Violation{
+			Rule:    RuleSigOps,
+			Index:   -1,
+			Message: fmt.Sprintf("%d sigops exceeds %d", sigOps, p.MaxSigOps),
+		})
+	}
+
+	if p.FeeQuote != nil {
+		ok, err := tx.IsFeePaidEnough(p.FeeQuote)
+		switch {
+		case err != nil:
+			vv = append(vv, Violation{Rule: RuleFee, Index: -1, Message: err.Error()})
+		case !ok:
+			vv = append(vv, Violation{
+				Rule:    RuleFee,
+				Index:   -1,
+				Message: fmt.Sprintf("fee of %d is too low", int64(tx.TotalInputSatoshis())-int64(tx.TotalOutputSatoshis())),
+			})
+		}
+	}
+
+	return vv
+}
+
+func (p *Policy) checkOutput(i int, out *bt.Output) Violations {
+	s := out.LockingScript
+	if s == nil {
+		s = &bscript.Script{}
+	}
+	unspendable := len(*s) > 1 && (*s)[0] == bscript.OpFALSE && (*s)[1] == bscript.OpRETURN
+
+	var vv Violations
+	if !unspendable && out.Satoshis < p.DustLimit {
+		vv = append(vv, Violation{
+			Rule:    RuleDust,
+			Index:   i,
+			Message: fmt.Sprintf("%d satoshis is below the dust limit of %d", out.Satoshis, p.DustLimit),
+		})
+	}
+
+	if len(p.AllowedScriptTypes) > 0 {
+		st := s.ScriptType()
+		allowed := false
+		for _, t := range p.AllowedScriptTypes {
+			allowed = allowed || t == st
+		}
+		if !allowed {
+			vv = append(vv, Violation{
+				Rule:    RuleScriptType,
+				Index:   i,
+				Message: fmt.Sprintf("script type %s is not allowed", st),
+			})
+		}
+	}
+
+	if p.MaxDataSize > 0 && s.IsData() && len(*s) > p.MaxDataSize {
+		vv = append(vv, Violation{
+			Rule:    RuleDataSize,
+			Index:   i,
+			Message: fmt.Sprintf("data size %d exceeds %d", len(*s), p.MaxDataSize),
+		})
+	}
+
+	return vv
+}
+
+// countSigOps counts the signature operations in a script the way nodes do,
+// taking the number of public keys of an OP_CHECKMULTISIG from the op before it
+// where it is a small int, and counting the most allowed otherwise.
+func countSigOps(ps interpreter.ParsedScript) int {
+	var n int
+	for i, op := range ps {
+		switch op.Value() {
+		case bscript.OpCHECKSIG, bscript.OpCHECKSIGVERIFY:
+			n++
+		case bscript.OpCHECKMULTISIG, bscript.OpCHECKMULTISIGVERIFY:
+			if i > 0 && ps[i-1].Value() >= bscript.Op1 && ps[i-1].Value() <= bscript.Op16 {
+				n += int(ps[i-1].Value() - bscript.Op1 + 1)
+			} else {
+				n += maxPubKeysPerMultiSig
+			}
+		}
+	}
+	return n
+}
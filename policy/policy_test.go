@@ -0,0 +1,160 @@
+package policy_test
+
+import (
+	"testing"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+	"github.com/mvc-labs/mvc-lib-go/policy"
+	"github.com/stretchr/testify/assert"
+)
+
+func testTx(t *testing.T) *bt.Tx {
+	tx := bt.NewTx()
+	assert.NoError(t, tx.From(
+		"45be95d2f2c64e99518ffbbce03fb15a7758f20ee5eecf0df07938d977add71d",
+		0,
+		"76a914c0a3c167a28cabb9fbb495affa0761e6e74ac60d88ac",
+		10000,
+	))
+	s, err := bscript.ParseASM("3044022064f633ccfc4e937ef9e3edcaa9835ea9a98d31fbea1622c1d8a38d4e7f8f6cb602204bffef45a094de1306f99da055bd5a603a15c277a59a48f40a615aa4f7e5038001 " +
+		"03c9f4836b9a4f77fc0d81f7bcb01b7f1b35916864b9476c241ce9fc198bd25432")
+	assert.NoError(t, err)
+	tx.Inputs[0].UnlockingScript = s
+	assert.NoError(t, tx.PayToAddress("1NRoySJ9Lvby6DuE2UQYnyT67AASwNZxGb", 5000))
+	assert.NoError(t, tx.AddOpReturnOutput([]byte("hello")))
+
+	return tx
+}
+
+func TestPolicy_Check(t *testing.T) {
+	t.Parallel()
+
+	multiSig, err := bscript.ParseASM("OP_2 " +
+		"03c9f4836b9a4f77fc0d81f7bcb01b7f1b35916864b9476c241ce9fc198bd25432 " +
+		"0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798 " +
+		"OP_2 OP_CHECKMULTISIG")
+	assert.NoError(t, err)
+	notPushOnly, err := bscript.ParseASM("OP_1 OP_DUP")
+	assert.NoError(t, err)
+
+	tests := map[string]struct {
+		policy  *policy.Policy
+		modify  func(tx *bt.Tx)
+		expRule []policy.Rule
+		expIdx  []int
+	}{
+		"standard tx passes the default policy": {
+			policy: policy.NewPolicy(),
+		},
+		"zero value policy checks nothing": {
+			policy: &policy.Policy{},
+			modify: func(tx *bt.Tx) {
+				tx.Inputs[0].UnlockingScript = notPushOnly
+				tx.Outputs[0].Satoshis = 0
+			},
+		},
+		"tx too large": {
+			policy:  &policy.Policy{MaxTxSize: 100},
+			expRule: []policy.Rule{policy.RuleTxSize},
+			expIdx:  []int{-1},
+		},
+		"dust output": {
+			policy: &policy.Policy{DustLimit: 10},
+			modify: func(tx *bt.Tx) {
+				tx.Outputs[0].Satoshis = 9
+			},
+			expRule: []policy.Rule{policy.RuleDust},
+			expIdx:  []int{0},
+		},
+		"script type not allowed": {
+			policy: &policy.Policy{AllowedScriptTypes: []string{bscript.ScriptTypePubKeyHash, bscript.ScriptTypeNullData}},
+			modify: func(tx *bt.Tx) {
+				tx.AddOutput(&bt.Output{LockingScript: multiSig, Satoshis: 1})
+			},
+			expRule: []policy.Rule{policy.RuleScriptType},
+			expIdx:  []int{2},
+		},
+		"unlocking script not push only": {
+			policy: policy.NewPolicy(),
+			modify: func(tx *bt.Tx) {
+				tx.Inputs[0].UnlockingScript = notPushOnly
+			},
+			expRule: []policy.Rule{policy.RulePushOnly},
+			expIdx:  []int{0},
+		},
+		"data output too large": {
+			policy:  &policy.Policy{MaxDataSize: 5},
+			expRule: []policy.Rule{policy.RuleDataSize},
+			expIdx:  []int{1},
+		},
+		"too many sigops": {
+			policy: &policy.Policy{MaxSigOps: 2},
+			modify: func(tx *bt.Tx) {
+				tx.AddOutput(&bt.Output{LockingScript: multiSig, Satoshis: 1})
+			},
+			expRule: []policy.Rule{policy.RuleSigOps},
+			expIdx:  []int{-1},
+		},
+		"multisig sigops counted by key count": {
+			policy: &policy.Policy{MaxSigOps: 3},
+			modify: func(tx *bt.Tx) {
+				tx.AddOutput(&bt.Output{LockingScript: multiSig, Satoshis: 1})
+			},
+		},
+		"fee too low": {
+			policy: &policy.Policy{FeeQuote: bt.NewFeeQuote()},
+			modify: func(tx *bt.Tx) {
+				tx.Outputs[0].Satoshis = 10000
+			},
+			expRule: []policy.Rule{policy.RuleFee},
+			expIdx:  []int{-1},
+		},
+		"fee paid": {
+			policy: &policy.Policy{FeeQuote: bt.NewFeeQuote()},
+		},
+		"several violations": {
+			policy: &policy.Policy{MaxTxSize: 100, DustLimit: 10, RequirePushOnly: true},
+			modify: func(tx *bt.Tx) {
+				tx.Inputs[0].UnlockingScript = notPushOnly
+				tx.Outputs[0].Satoshis = 1
+				tx.Outputs[1].Satoshis = 0
+			},
+			expRule: []policy.Rule{policy.RuleTxSize, policy.RulePushOnly, policy.RuleDust},
+			expIdx:  []int{-1, 0, 0},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tx := testTx(t)
+			if test.modify != nil {
+				test.modify(tx)
+			}
+
+			vv := test.policy.Check(tx)
+			rules := make([]policy.Rule, 0, len(vv))
+			idxs := make([]int, 0, len(vv))
+			for _, v := range vv {
+				rules = append(rules, v.Rule)
+				idxs = append(idxs, v.Index)
+			}
+			if test.expRule == nil {
+				assert.Empty(t, vv)
+				assert.NoError(t, vv.Err())
+				return
+			}
+			assert.Equal(t, test.expRule, rules)
+			assert.Equal(t, test.expIdx, idxs)
+			assert.ErrorIs(t, vv.Err(), policy.ErrNonStandard)
+			assert.True(t, vv.Has(test.expRule[0]))
+		})
+	}
+}
+
+func TestViolation_String(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "dust[2]: too small", policy.Violation{Rule: policy.RuleDust, Index: 2, Message: "too small"}.String())
+	assert.Equal(t, "fee: too low", policy.Violation{Rule: policy.RuleFee, Index: -1, Message: "too low"}.String())
+}
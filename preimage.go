@@ -0,0 +1,197 @@
+package bt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math/big"
+
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+	"github.com/mvc-labs/mvc-lib-go/keys/bec"
+	"github.com/mvc-labs/mvc-lib-go/keys/crypto"
+	"github.com/mvc-labs/mvc-lib-go/sighash"
+	"github.com/pkg/errors"
+)
+
+// Preimage is the BIP143 style signature hash preimage of a tx input, as returned by
+// CalcInputPreimage and pushed into the unlocking scripts of OP_PUSH_TX contracts.
+type Preimage struct {
+	Version      uint32
+	HashPrevOuts []byte
+	HashSequence []byte
+
+	// PreviousTxID is the txid of the output being spent, in display order.
+	PreviousTxID       []byte
+	PreviousTxOutIndex uint32
+
+	// ScriptCode is the locking script of the output being spent.
+	ScriptCode         *bscript.Script
+	PreviousTxSatoshis uint64
+	SequenceNumber     uint32
+
+	HashOutputs []byte
+	LockTime    uint32
+	SigHashType sighash.Flag
+}
+
+// NewPreimageFromBytes parses a signature hash preimage.
+func NewPreimageFromBytes(b []byte) (*Preimage, error) {
+	r := bytes.NewReader(b)
+	p := &Preimage{
+		HashPrevOuts: make([]byte, 32),
+		HashSequence: make([]byte, 32),
+		PreviousTxID: make([]byte, 32),
+		HashOutputs:  make([]byte, 32),
+	}
+
+	var sigHashType uint32
+	var scriptLen VarInt
+	for _, f := range []func() error{
+		func() error { return binary.Read(r, binary.LittleEndian, &p.Version) },
+		func() error { _, err := io.ReadFull(r, p.HashPrevOuts); return err },
+		func() error { _, err := io.ReadFull(r, p.HashSequence); return err },
+		func() error { _, err := io.ReadFull(r, p.PreviousTxID); return err },
+		func() error { return binary.Read(r, binary.LittleEndian, &p.PreviousTxOutIndex) },
+		func() error { _, err := scriptLen.ReadFrom(r); return err },
+		func() error {
+			if uint64(r.Len()) < uint64(scriptLen) {
+				return io.ErrUnexpectedEOF
+			}
+			s := make([]byte, scriptLen)
+			_, err := io.ReadFull(r, s)
+			p.ScriptCode = bscript.NewFromBytes(s)
+			return err
+		},
+		func() error { return binary.Read(r, binary.LittleEndian, &p.PreviousTxSatoshis) },
+		func() error { return binary.Read(r, binary.LittleEndian, &p.SequenceNumber) },
+		func() error { _, err := io.ReadFull(r, p.HashOutputs); return err },
+		func() error { return binary.Read(r, binary.LittleEndian, &p.LockTime) },
+		func() error { return binary.Read(r, binary.LittleEndian, &sigHashType) },
+	} {
+		if err := f(); err != nil {
+			return nil, errors.Wrap(ErrPreimageInvalid, err.Error())
+		}
+	}
+	if r.Len() > 0 {
+		return nil, ErrPreimageTrailingData
+	}
+	if sigHashType > 0xff {
+		return nil, errors.Wrapf(ErrPreimageInvalid, "sighash type %#x", sigHashType)
+	}
+
+	p.PreviousTxID = ReverseBytes(p.PreviousTxID)
+	p.SigHashType = sighash.Flag(sigHashType)
+
+	return p, nil
+}
+
+// Bytes returns the serialised preimage.
+func (p *Preimage) Bytes() []byte {
+	var scriptCode []byte
+	if p.ScriptCode != nil {
+		scriptCode = *p.ScriptCode
+	}
+
+	buf := make([]byte, 0, 4+32+32+36+9+len(scriptCode)+8+4+32+4+4)
+	buf = append(buf, LittleEndianBytes(p.Version, 4)...)
+	buf = append(buf, p.HashPrevOuts...)
+	buf = append(buf, p.HashSequence...)
+	buf = append(buf, ReverseBytes(p.PreviousTxID)...)
+	buf = append(buf, LittleEndianBytes(p.PreviousTxOutIndex, 4)...)
+	buf = append(buf, VarInt(uint64(len(scriptCode))).Bytes()...)
+	buf = append(buf, scriptCode...)
+	sat := make([]byte, 8)
+	binary.LittleEndian.PutUint64(sat, p.PreviousTxSatoshis)
+	buf = append(buf, sat...)
+	buf = append(buf, LittleEndianBytes(p.SequenceNumber, 4)...)
+	buf = append(buf, p.HashOutputs...)
+	buf = append(buf, LittleEndianBytes(p.LockTime, 4)...)
+	buf = append(buf, LittleEndianBytes(uint32(p.SigHashType), 4)...)
+
+	return buf
+}
+
+// SignatureHash returns the hash of the preimage, which is signed by the input.
+func (p *Preimage) SignatureHash() []byte {
+	return crypto.Sha256d(p.Bytes())
+}
+
+// InputPreimage returns the parsed signature hash preimage of the input. Only
+// preimages of sighash types including sighash.ForkID are supported.
+func (tx *Tx) InputPreimage(inputNumber uint32, sigHashFlag sighash.Flag) (*Preimage, error) {
+	b, err := tx.CalcInputPreimage(inputNumber, sigHashFlag)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPreimageFromBytes(b)
+}
+
+// PushTxPublicKey returns the compressed public key of the private key 1, the
+// generator point G, against which OP_PUSH_TX signatures are checked.
+func PushTxPublicKey() []byte {
+	curve := bec.S256()
+	return (&bec.PublicKey{Curve: curve, X: curve.Gx, Y: curve.Gy}).SerialiseCompressed()
+}
+
+// PushTxSignature returns the DER signature, followed by the sighash type, which an
+// OP_PUSH_TX contract computes for the preimage.
+//
+// Such contracts check the preimage in their unlocking script is that of the tx
+// spending them by signing it in script, with both the private key and the nonce k
+// fixed at 1, and checking the signature against PushTxPublicKey with OP_CHECKSIG.
+// The signature is then r = Gx and s = z + Gx, where z is the signature hash, which
+// is only valid if s is in its low form, as a contract cannot cheaply negate it.
+// ErrPushTxHighS is returned if it is not, in which case the tx must be changed,
+// such as with PushTxPreimage, until it is.
+func PushTxSignature(preimage []byte, sigHashFlag sighash.Flag) ([]byte, error) {
+	curve := bec.S256()
+	r := new(big.Int).Mod(curve.Gx, curve.N)
+	s := new(big.Int).SetBytes(crypto.Sha256d(preimage))
+	s.Add(s, r)
+	s.Mod(s, curve.N)
+	if s.Cmp(new(big.Int).Rsh(curve.N, 1)) > 0 {
+		return nil, ErrPushTxHighS
+	}
+
+	sig := (&bec.Signature{R: r, S: s}).Serialise()
+	return append(sig, byte(sigHashFlag)), nil
+}
+
+// PushTxPreimage returns the preimage of the input, along with the OP_PUSH_TX
+// signature for it, incrementing the tx lock time until the signature is in its
+// low form, for at most maxAttempts preimages. As changing the lock time only
+// leaves the tx valid if every input has a final sequence number, the lock time
+// is left alone, and ErrPushTxHighS returned, if any does not. If no attempt
+// gives a low form signature, the lock time is restored and ErrPushTxHighS is
+// returned.
+//
+// Changing the lock time invalidates signatures over it, so this must be called
+// before the other inputs are signed.
+func (tx *Tx) PushTxPreimage(inputNumber uint32, sigHashFlag sighash.Flag, maxAttempts int) ([]byte, []byte, error) {
+	final := true
+	for _, in := range tx.Inputs {
+		final = final && in.SequenceNumber == DefaultSequenceNumber
+	}
+
+	lockTime := tx.LockTime
+	for i := 0; i < maxAttempts; i++ {
+		preimage, err := tx.CalcInputPreimage(inputNumber, sigHashFlag)
+		if err != nil {
+			tx.LockTime = lockTime
+			return nil, nil, err
+		}
+		sig, err := PushTxSignature(preimage, sigHashFlag)
+		if err == nil {
+			return preimage, sig, nil
+		}
+		if !errors.Is(err, ErrPushTxHighS) || !final {
+			tx.LockTime = lockTime
+			return nil, nil, err
+		}
+		tx.LockTime++
+	}
+
+	tx.LockTime = lockTime
+	return nil, nil, ErrPushTxHighS
+}
@@ -0,0 +1,127 @@
+package bt_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+	"github.com/mvc-labs/mvc-lib-go/bscript/interpreter"
+	"github.com/mvc-labs/mvc-lib-go/sighash"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPreimageFromBytes(t *testing.T) {
+	t.Parallel()
+
+	tx := bt.NewTx()
+	assert.NoError(t, tx.From(
+		"45be95d2f2c64e99518ffbbce03fb15a7758f20ee5eecf0df07938d977add71d",
+		2,
+		"76a914c0a3c167a28cabb9fbb495affa0761e6e74ac60d88ac",
+		1500,
+	))
+	assert.NoError(t, tx.PayToAddress("1NRoySJ9Lvby6DuE2UQYnyT67AASwNZxGb", 1000))
+	tx.LockTime = 800000
+
+	b, err := tx.CalcInputPreimage(0, sighash.AllForkID)
+	assert.NoError(t, err)
+
+	p, err := bt.NewPreimageFromBytes(b)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(1), p.Version)
+	assert.Equal(t, tx.PreviousOutHash(), p.HashPrevOuts)
+	assert.Equal(t, tx.SequenceHash(), p.HashSequence)
+	assert.Equal(t, "45be95d2f2c64e99518ffbbce03fb15a7758f20ee5eecf0df07938d977add71d", hex.EncodeToString(p.PreviousTxID))
+	assert.Equal(t, uint32(2), p.PreviousTxOutIndex)
+	assert.Equal(t, "76a914c0a3c167a28cabb9fbb495affa0761e6e74ac60d88ac", p.ScriptCode.String())
+	assert.Equal(t, uint64(1500), p.PreviousTxSatoshis)
+	assert.Equal(t, bt.DefaultSequenceNumber, p.SequenceNumber)
+	assert.Equal(t, tx.OutputsHash(-1), p.HashOutputs)
+	assert.Equal(t, uint32(800000), p.LockTime)
+	assert.Equal(t, sighash.AllForkID, p.SigHashType)
+
+	assert.Equal(t, b, p.Bytes())
+
+	sh, err := tx.CalcInputSignatureHash(0, sighash.AllForkID)
+	assert.NoError(t, err)
+	assert.Equal(t, sh, p.SignatureHash())
+
+	ip, err := tx.InputPreimage(0, sighash.AllForkID)
+	assert.NoError(t, err)
+	assert.Equal(t, p, ip)
+
+	_, err = bt.NewPreimageFromBytes(b[:len(b)-1])
+	assert.ErrorIs(t, err, bt.ErrPreimageInvalid)
+	_, err = bt.NewPreimageFromBytes(b[:110])
+	assert.ErrorIs(t, err, bt.ErrPreimageInvalid)
+	_, err = bt.NewPreimageFromBytes(append(b, 0x00))
+	assert.ErrorIs(t, err, bt.ErrPreimageTrailingData)
+}
+
+func TestPushTx(t *testing.T) {
+	t.Parallel()
+
+	lockingScript, err := bscript.NewP2PKFromPubKeyBytes(bt.PushTxPublicKey())
+	assert.NoError(t, err)
+	assert.Equal(t, "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798 OP_CHECKSIG",
+		lockingScript.FormatASM(bscript.ASMModeNode))
+
+	tx := bt.NewTx()
+	assert.NoError(t, tx.From(
+		"45be95d2f2c64e99518ffbbce03fb15a7758f20ee5eecf0df07938d977add71d", 0, lockingScript.String(), 1000,
+	))
+	assert.NoError(t, tx.PayToAddress("1NRoySJ9Lvby6DuE2UQYnyT67AASwNZxGb", 900))
+
+	preimage, sig, err := tx.PushTxPreimage(0, sighash.AllForkID, 64)
+	assert.NoError(t, err)
+
+	expPreimage, err := tx.CalcInputPreimage(0, sighash.AllForkID)
+	assert.NoError(t, err)
+	assert.Equal(t, expPreimage, preimage)
+
+	unlockingScript := &bscript.Script{}
+	assert.NoError(t, unlockingScript.AppendPushData(sig))
+	tx.Inputs[0].UnlockingScript = unlockingScript
+
+	assert.NoError(t, interpreter.NewEngine().Execute(
+		interpreter.WithTx(tx, 0, &bt.Output{LockingScript: lockingScript, Satoshis: 1000}),
+		interpreter.WithForkID(),
+		interpreter.WithAfterGenesis(),
+	))
+
+	t.Run("attempts exhausted", func(t *testing.T) {
+		for lockTime := uint32(0); lockTime < 64; lockTime++ {
+			tx.LockTime = lockTime
+			preimage, err := tx.CalcInputPreimage(0, sighash.AllForkID)
+			assert.NoError(t, err)
+			if _, err = bt.PushTxSignature(preimage, sighash.AllForkID); err != nil {
+				// The only attempt fails, so the lock time it bumped is restored.
+				_, _, err = tx.PushTxPreimage(0, sighash.AllForkID, 1)
+				assert.ErrorIs(t, err, bt.ErrPushTxHighS)
+				assert.Equal(t, lockTime, tx.LockTime)
+				return
+			}
+		}
+		t.Fatal("no high s preimage found")
+	})
+
+	t.Run("high s", func(t *testing.T) {
+		// The lock time cannot be changed while an input is not final.
+		tx.Inputs[0].SequenceNumber = 0
+		for lockTime := uint32(0); lockTime < 64; lockTime++ {
+			tx.LockTime = lockTime
+			preimage, err := tx.CalcInputPreimage(0, sighash.AllForkID)
+			assert.NoError(t, err)
+			if _, err = bt.PushTxSignature(preimage, sighash.AllForkID); err != nil {
+				assert.ErrorIs(t, err, bt.ErrPushTxHighS)
+
+				_, _, err = tx.PushTxPreimage(0, sighash.AllForkID, 64)
+				assert.ErrorIs(t, err, bt.ErrPushTxHighS)
+				assert.Equal(t, lockTime, tx.LockTime)
+				return
+			}
+		}
+		t.Fatal("no high s preimage found")
+	})
+}
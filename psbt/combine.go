@@ -0,0 +1,147 @@
+package psbt
+
+import (
+	"bytes"
+
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+)
+
+// Combine merges the signing data of the other partially signed txs, as returned by
+// each co-signer, into the receiver. All must share the same unsigned tx, otherwise a
+// psbt.ErrTxMismatch is returned.
+//
+// If the partially signed txs conflict, such as by having different sighash flags or
+// redeem scripts for the same input, an error is returned and the receiver is left
+// unchanged.
+func (p *PSBT) Combine(others ...*PSBT) error {
+	combined, err := NewFromBytes(p.Bytes())
+	if err != nil {
+		return err
+	}
+
+	txBytes := p.Tx.ExtendedBytes()
+	for _, o := range others {
+		if !bytes.Equal(txBytes, o.Tx.ExtendedBytes()) {
+			return ErrTxMismatch
+		}
+		if len(o.Inputs) != len(combined.Inputs) {
+			return ErrInputCountMismatch
+		}
+		if len(o.Outputs) != len(combined.Outputs) {
+			return ErrOutputCountMismatch
+		}
+
+		combined.Unknowns = combineUnknowns(combined.Unknowns, o.Unknowns)
+		for i, in := range o.Inputs {
+			if err = combined.Inputs[i].combine(in); err != nil {
+				return err
+			}
+		}
+		for i, out := range o.Outputs {
+			if err = combined.Outputs[i].combine(out); err != nil {
+				return err
+			}
+		}
+	}
+
+	*p = *combined
+
+	return nil
+}
+
+func (i *Input) combine(o *Input) error {
+	// Signing data is dropped once an input is finalized, so a finalized input wins.
+	if o.IsFinalized() && !i.IsFinalized() {
+		*i = Input{FinalUnlockingScript: o.FinalUnlockingScript, Unknowns: i.Unknowns}
+	}
+	if i.IsFinalized() {
+		i.Unknowns = combineUnknowns(i.Unknowns, o.Unknowns)
+		return nil
+	}
+
+	switch {
+	case o.SigHashFlag == 0:
+	case i.SigHashFlag == 0:
+		i.SigHashFlag = o.SigHashFlag
+	case i.SigHashFlag != o.SigHashFlag:
+		return ErrSigHashMismatch
+	}
+
+	rs, err := combineRedeemScripts(i.RedeemScript, o.RedeemScript)
+	if err != nil {
+		return err
+	}
+	i.RedeemScript = rs
+
+	for _, ps := range o.PartialSigs {
+		i.addPartialSig(ps)
+	}
+	i.Bip32Derivations = combineBip32Derivations(i.Bip32Derivations, o.Bip32Derivations)
+	i.Unknowns = combineUnknowns(i.Unknowns, o.Unknowns)
+
+	return nil
+}
+
+func (o *Output) combine(other *Output) error {
+	rs, err := combineRedeemScripts(o.RedeemScript, other.RedeemScript)
+	if err != nil {
+		return err
+	}
+	o.RedeemScript = rs
+
+	o.Bip32Derivations = combineBip32Derivations(o.Bip32Derivations, other.Bip32Derivations)
+	o.Unknowns = combineUnknowns(o.Unknowns, other.Unknowns)
+
+	return nil
+}
+
+func combineRedeemScripts(a, b *bscript.Script) (*bscript.Script, error) {
+	switch {
+	case b == nil:
+		return a, nil
+	case a == nil:
+		return b, nil
+	case !a.Equals(b):
+		return nil, ErrRedeemScriptMismatch
+	}
+
+	return a, nil
+}
+
+// combineBip32Derivations adds the derivations of b to a, keeping those of a for any
+// public keys found in both.
+func combineBip32Derivations(a, b []*Bip32Derivation) []*Bip32Derivation {
+	for _, d := range b {
+		found := false
+		for _, existing := range a {
+			if bytes.Equal(existing.PubKey, d.PubKey) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			a = addBip32Derivation(a, d)
+		}
+	}
+
+	return a
+}
+
+// combineUnknowns adds the unknowns of b to a, keeping those of a for any keys found
+// in both.
+func combineUnknowns(a, b []*Unknown) []*Unknown {
+	for _, u := range b {
+		found := false
+		for _, existing := range a {
+			if bytes.Equal(existing.Key, u.Key) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			a = append(a, u)
+		}
+	}
+
+	return a
+}
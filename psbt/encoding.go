@@ -0,0 +1,332 @@
+package psbt
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+	"github.com/mvc-labs/mvc-lib-go/sighash"
+	"github.com/pkg/errors"
+)
+
+/*
+Binary format of a partially signed tx
+--------------------------------------------------------
+Field            Description                                                  Size
+
+magic            0x70736274ff ("psbt" followed by 0xff)                      5 bytes
+
+global map       key-value pairs, holding the unsigned tx in extended format  variable
+
+input maps       key-value pairs for each tx input, in order                  variable
+
+output maps      key-value pairs for each tx output, in order                 variable
+--------------------------------------------------------
+
+Each map is a list of key-value pairs, terminated by a zero length key. A key is a VarInt
+length followed by the key type and any key data, and a value is a VarInt length followed
+by the value itself. Known key-value pairs are written in key type order, then key data
+order, followed by any unknown pairs in the order they were read, so encoding is stable.
+*/
+
+var magic = []byte{0x70, 0x73, 0x62, 0x74, 0xff}
+
+// Key types of the global map.
+const (
+	globalTx byte = 0x00
+)
+
+// Key types of the input maps.
+const (
+	inputPartialSig           byte = 0x02
+	inputSigHashType          byte = 0x03
+	inputRedeemScript         byte = 0x04
+	inputBip32Derivation      byte = 0x06
+	inputFinalUnlockingScript byte = 0x07
+)
+
+// Key types of the output maps.
+const (
+	outputRedeemScript    byte = 0x00
+	outputBip32Derivation byte = 0x02
+)
+
+// Bytes encodes the partially signed tx into its binary format.
+func (p *PSBT) Bytes() []byte {
+	var w kvWriter
+	w.Write(magic)
+
+	w.pair([]byte{globalTx}, p.Tx.ExtendedBytes())
+	w.unknowns(p.Unknowns)
+	w.end()
+
+	for _, in := range p.Inputs {
+		for _, ps := range in.PartialSigs {
+			w.pair(append([]byte{inputPartialSig}, ps.PubKey...), ps.Signature)
+		}
+		if in.SigHashFlag != 0 {
+			w.pair([]byte{inputSigHashType}, bt.LittleEndianBytes(uint32(in.SigHashFlag), 4))
+		}
+		if in.RedeemScript != nil {
+			w.pair([]byte{inputRedeemScript}, *in.RedeemScript)
+		}
+		w.bip32Derivations(inputBip32Derivation, in.Bip32Derivations)
+		if in.FinalUnlockingScript != nil {
+			w.pair([]byte{inputFinalUnlockingScript}, *in.FinalUnlockingScript)
+		}
+		w.unknowns(in.Unknowns)
+		w.end()
+	}
+
+	for _, out := range p.Outputs {
+		if out.RedeemScript != nil {
+			w.pair([]byte{outputRedeemScript}, *out.RedeemScript)
+		}
+		w.bip32Derivations(outputBip32Derivation, out.Bip32Derivations)
+		w.unknowns(out.Unknowns)
+		w.end()
+	}
+
+	return w.Bytes()
+}
+
+// String encodes the partially signed tx into its binary format, as a hex string.
+func (p *PSBT) String() string {
+	return hex.EncodeToString(p.Bytes())
+}
+
+// ReadFrom reads from the `io.Reader` into the `psbt.PSBT`.
+func (p *PSBT) ReadFrom(r io.Reader) (int64, error) {
+	*p = PSBT{}
+	kr := &kvReader{r: r}
+
+	m := make([]byte, len(magic))
+	n, err := io.ReadFull(r, m)
+	kr.n += int64(n)
+	if err != nil {
+		return kr.n, errors.Wrapf(err, "magic(%d): got %d bytes", len(magic), n)
+	}
+	if !bytes.Equal(m, magic) {
+		return kr.n, ErrInvalidMagic
+	}
+
+	if err = kr.readMap(func(k, v []byte) (bool, error) {
+		if k[0] != globalTx {
+			return false, nil
+		}
+		if len(k) != 1 {
+			return false, ErrInvalidKey
+		}
+		tx, err := bt.NewTxFromBytes(v)
+		if err != nil {
+			return false, errors.Wrap(err, "tx")
+		}
+		p.Tx = tx
+		return true, nil
+	}, &p.Unknowns); err != nil {
+		return kr.n, err
+	}
+	if p.Tx == nil {
+		return kr.n, ErrMissingTx
+	}
+	for _, in := range p.Tx.Inputs {
+		if len(*in.UnlockingScript) > 0 {
+			return kr.n, ErrTxSigned
+		}
+	}
+
+	p.Inputs = make([]*Input, len(p.Tx.Inputs))
+	for i := range p.Inputs {
+		in := &Input{}
+		if err = kr.readMap(in.readPair, &in.Unknowns); err != nil {
+			return kr.n, errors.Wrapf(err, "input %d", i)
+		}
+		p.Inputs[i] = in
+	}
+
+	p.Outputs = make([]*Output, len(p.Tx.Outputs))
+	for i := range p.Outputs {
+		out := &Output{}
+		if err = kr.readMap(out.readPair, &out.Unknowns); err != nil {
+			return kr.n, errors.Wrapf(err, "output %d", i)
+		}
+		p.Outputs[i] = out
+	}
+
+	return kr.n, nil
+}
+
+func (i *Input) readPair(k, v []byte) (bool, error) {
+	switch k[0] {
+	case inputPartialSig:
+		if len(k) == 1 || len(v) == 0 {
+			return false, ErrInvalidValue
+		}
+		i.addPartialSig(&PartialSig{PubKey: k[1:], Signature: v})
+	case inputSigHashType:
+		if len(k) != 1 {
+			return false, ErrInvalidKey
+		}
+		if len(v) != 4 {
+			return false, ErrInvalidValue
+		}
+		i.SigHashFlag = sighash.Flag(binary.LittleEndian.Uint32(v))
+	case inputRedeemScript:
+		if len(k) != 1 {
+			return false, ErrInvalidKey
+		}
+		i.RedeemScript = bscript.NewFromBytes(v)
+	case inputBip32Derivation:
+		d, err := readBip32Derivation(k, v)
+		if err != nil {
+			return false, err
+		}
+		i.Bip32Derivations = addBip32Derivation(i.Bip32Derivations, d)
+	case inputFinalUnlockingScript:
+		if len(k) != 1 {
+			return false, ErrInvalidKey
+		}
+		i.FinalUnlockingScript = bscript.NewFromBytes(v)
+	default:
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func (o *Output) readPair(k, v []byte) (bool, error) {
+	switch k[0] {
+	case outputRedeemScript:
+		if len(k) != 1 {
+			return false, ErrInvalidKey
+		}
+		o.RedeemScript = bscript.NewFromBytes(v)
+	case outputBip32Derivation:
+		d, err := readBip32Derivation(k, v)
+		if err != nil {
+			return false, err
+		}
+		o.Bip32Derivations = addBip32Derivation(o.Bip32Derivations, d)
+	default:
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// readBip32Derivation reads a derivation keyed by public key, with a value of the 4 byte
+// master key fingerprint followed by each 4 byte path index.
+func readBip32Derivation(k, v []byte) (*Bip32Derivation, error) {
+	if len(k) == 1 {
+		return nil, ErrInvalidKey
+	}
+	if len(v) < 4 || len(v)%4 != 0 {
+		return nil, ErrInvalidValue
+	}
+
+	d := &Bip32Derivation{
+		PubKey:               k[1:],
+		MasterKeyFingerprint: binary.BigEndian.Uint32(v[:4]),
+		Path:                 make([]uint32, 0, len(v)/4-1),
+	}
+	for i := 4; i < len(v); i += 4 {
+		d.Path = append(d.Path, binary.LittleEndian.Uint32(v[i:i+4]))
+	}
+
+	return d, nil
+}
+
+type kvWriter struct {
+	bytes.Buffer
+}
+
+func (w *kvWriter) pair(k, v []byte) {
+	w.Write(bt.VarInt(len(k)).Bytes())
+	w.Write(k)
+	w.Write(bt.VarInt(len(v)).Bytes())
+	w.Write(v)
+}
+
+func (w *kvWriter) end() {
+	w.WriteByte(0x00)
+}
+
+func (w *kvWriter) bip32Derivations(keyType byte, dd []*Bip32Derivation) {
+	for _, d := range dd {
+		v := make([]byte, 4, 4+4*len(d.Path))
+		binary.BigEndian.PutUint32(v, d.MasterKeyFingerprint)
+		for _, idx := range d.Path {
+			v = append(v, bt.LittleEndianBytes(idx, 4)...)
+		}
+		w.pair(append([]byte{keyType}, d.PubKey...), v)
+	}
+}
+
+func (w *kvWriter) unknowns(uu []*Unknown) {
+	for _, u := range uu {
+		w.pair(u.Key, u.Value)
+	}
+}
+
+type kvReader struct {
+	r io.Reader
+	n int64
+}
+
+// readMap reads key-value pairs until the end of the map, passing each to fn, which
+// reports whether it understood the pair. Pairs not understood are added to unknowns.
+func (kr *kvReader) readMap(fn func(k, v []byte) (bool, error), unknowns *[]*Unknown) error {
+	seen := make(map[string]struct{})
+	for {
+		k, err := kr.read("key")
+		if err != nil {
+			return err
+		}
+		if len(k) == 0 {
+			return nil
+		}
+
+		if _, ok := seen[string(k)]; ok {
+			return ErrDuplicateKey
+		}
+		seen[string(k)] = struct{}{}
+
+		v, err := kr.read("value")
+		if err != nil {
+			return err
+		}
+
+		ok, err := fn(k, v)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			*unknowns = append(*unknowns, &Unknown{Key: k, Value: v})
+		}
+	}
+}
+
+func (kr *kvReader) read(field string) ([]byte, error) {
+	var l bt.VarInt
+	n64, err := l.ReadFrom(kr.r)
+	kr.n += n64
+	if err != nil {
+		return nil, err
+	}
+
+	// Read through a LimitReader rather than allocating l bytes up front, as l comes
+	// straight off the wire.
+	b, err := io.ReadAll(io.LimitReader(kr.r, int64(l)))
+	kr.n += int64(len(b))
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s(%d): got %d bytes", field, l, len(b))
+	}
+	if uint64(len(b)) != uint64(l) {
+		return nil, errors.Wrapf(io.ErrUnexpectedEOF, "%s(%d): got %d bytes", field, l, len(b))
+	}
+
+	return b, nil
+}
@@ -0,0 +1,26 @@
+package psbt
+
+import "github.com/pkg/errors"
+
+// Sentinel errors reported by partially signed txs.
+var (
+	ErrTxSigned             = errors.New("tx inputs must not have unlocking scripts")
+	ErrTxMismatch           = errors.New("partially signed txs do not share the same tx")
+	ErrInputCountMismatch   = errors.New("input count does not match the tx")
+	ErrOutputCountMismatch  = errors.New("output count does not match the tx")
+	ErrInputFinalized       = errors.New("input has already been finalized")
+	ErrNotFinalized         = errors.New("not all inputs have been finalized")
+	ErrSigHashMismatch      = errors.New("signature sighash flag does not match the input")
+	ErrInvalidSignature     = errors.New("signature is not valid for the input")
+	ErrKeyNotInScript       = errors.New("key is not used by the input's script code")
+	ErrNoRedeemScript       = errors.New("redeem script required for P2SH input")
+	ErrRedeemScriptMismatch = errors.New("redeem script does not match the input")
+	ErrNotEnoughSignatures  = errors.New("not enough partial signatures to finalize input")
+	ErrUnsupportedScript    = errors.New("unsupported script type")
+	ErrInvalidMagic         = errors.New("invalid partially signed tx magic bytes")
+	ErrDuplicateKey         = errors.New("duplicate key in partially signed tx")
+	ErrInvalidKey           = errors.New("invalid key in partially signed tx")
+	ErrInvalidValue         = errors.New("invalid value in partially signed tx")
+	ErrMissingTx            = errors.New("partially signed tx has no tx")
+	ErrTrailingData         = errors.New("unexpected data after end of partially signed tx")
+)
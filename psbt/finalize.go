@@ -0,0 +1,153 @@
+package psbt
+
+import (
+	"bytes"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+	"github.com/mvc-labs/mvc-lib-go/keys/crypto"
+	"github.com/pkg/errors"
+)
+
+// Finalize finalizes every input not yet finalized, as with FinalizeInput(...). If an
+// input cannot be finalized, the error is returned, wrapped with the input index.
+func (p *PSBT) Finalize() error {
+	for i, in := range p.Inputs {
+		if in.IsFinalized() {
+			continue
+		}
+		if err := p.FinalizeInput(i); err != nil {
+			return errors.Wrapf(err, "input %d", i)
+		}
+	}
+
+	return nil
+}
+
+// FinalizeInput builds the final unlocking script of the input at the index from its
+// partial signatures, then drops the signing data which is no longer needed.
+//
+// P2PKH, P2PK and bare multisig inputs are supported, along with P2SH inputs whose
+// redeem script is one of them. If there are not enough partial signatures, a
+// psbt.ErrNotEnoughSignatures is returned.
+func (p *PSBT) FinalizeInput(idx int) error {
+	in, err := p.input(idx)
+	if err != nil {
+		return err
+	}
+
+	sc, err := p.ScriptCode(idx)
+	if err != nil {
+		return err
+	}
+	if sc == nil {
+		return ErrUnsupportedScript
+	}
+
+	var s *bscript.Script
+	switch {
+	case sc.IsP2PKH():
+		s, err = in.finalizeP2PKH(sc)
+	case sc.IsP2PK():
+		s, err = in.finalizeP2PK(sc)
+	case sc.IsMultiSigOut():
+		s, err = in.finalizeMultiSig(sc)
+	default:
+		err = ErrUnsupportedScript
+	}
+	if err != nil {
+		return err
+	}
+
+	// The redeem script is only pushed for P2SH inputs, as a stray one left by another
+	// party would otherwise break the unlocking script of any other kind of input.
+	if p.Tx.Inputs[idx].PreviousTxScript.IsP2SH() {
+		if err = s.AppendPushData(*in.RedeemScript); err != nil {
+			return err
+		}
+	}
+
+	*in = Input{FinalUnlockingScript: s, Unknowns: in.Unknowns}
+
+	return nil
+}
+
+// Extract returns the signed tx, with the final unlocking script of each input. If not
+// every input has been finalized, a psbt.ErrNotFinalized is returned.
+func (p *PSBT) Extract() (*bt.Tx, error) {
+	if !p.IsComplete() {
+		return nil, ErrNotFinalized
+	}
+
+	tx := p.Tx.Clone()
+	for i, in := range p.Inputs {
+		tx.Inputs[i].UnlockingScript = bscript.NewFromBytes(append([]byte{}, *in.FinalUnlockingScript...))
+	}
+
+	return tx, nil
+}
+
+func (i *Input) finalizeP2PKH(sc *bscript.Script) (*bscript.Script, error) {
+	pkh := (*sc)[3:23]
+	for _, ps := range i.PartialSigs {
+		if !bytes.Equal(crypto.Hash160(ps.PubKey), pkh) {
+			continue
+		}
+
+		s := &bscript.Script{}
+		if err := s.AppendPushDataArray([][]byte{ps.Signature, ps.PubKey}); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+
+	return nil, ErrNotEnoughSignatures
+}
+
+func (i *Input) finalizeP2PK(sc *bscript.Script) (*bscript.Script, error) {
+	pubKey, err := sc.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+
+	sig := i.partialSig(pubKey)
+	if sig == nil {
+		return nil, ErrNotEnoughSignatures
+	}
+
+	s := &bscript.Script{}
+	if err = s.AppendPushData(sig); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (i *Input) finalizeMultiSig(sc *bscript.Script) (*bscript.Script, error) {
+	required, pubKeys, err := sc.MultiSig()
+	if err != nil {
+		return nil, err
+	}
+
+	// OP_CHECKMULTISIG pops one more item than it uses, so a dummy OP_0 leads, then
+	// the signatures follow in the order of their public keys.
+	s := &bscript.Script{}
+	_ = s.AppendOpcodes(bscript.OpZERO)
+	found := 0
+	for _, pubKey := range pubKeys {
+		if found == required {
+			break
+		}
+		if sig := i.partialSig(pubKey); sig != nil {
+			if err = s.AppendPushData(sig); err != nil {
+				return nil, err
+			}
+			found++
+		}
+	}
+	if found < required {
+		return nil, ErrNotEnoughSignatures
+	}
+
+	return s, nil
+}
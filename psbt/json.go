@@ -0,0 +1,217 @@
+package psbt
+
+import (
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+	"github.com/mvc-labs/mvc-lib-go/sighash"
+	"github.com/pkg/errors"
+)
+
+type psbtJSON struct {
+	Tx       string         `json:"tx"`
+	Inputs   []*inputJSON   `json:"inputs"`
+	Outputs  []*outputJSON  `json:"outputs"`
+	Unknowns []*unknownJSON `json:"unknowns,omitempty"`
+}
+
+type inputJSON struct {
+	PartialSigs          []*partialSigJSON      `json:"partialSigs,omitempty"`
+	SigHashFlag          sighash.Flag           `json:"sigHashFlag,omitempty"`
+	RedeemScript         *bscript.Script        `json:"redeemScript,omitempty"`
+	Bip32Derivations     []*bip32DerivationJSON `json:"bip32Derivations,omitempty"`
+	FinalUnlockingScript *bscript.Script        `json:"finalUnlockingScript,omitempty"`
+	Unknowns             []*unknownJSON         `json:"unknowns,omitempty"`
+}
+
+type outputJSON struct {
+	RedeemScript     *bscript.Script        `json:"redeemScript,omitempty"`
+	Bip32Derivations []*bip32DerivationJSON `json:"bip32Derivations,omitempty"`
+	Unknowns         []*unknownJSON         `json:"unknowns,omitempty"`
+}
+
+type partialSigJSON struct {
+	PubKey    string `json:"pubKey"`
+	Signature string `json:"signature"`
+}
+
+type bip32DerivationJSON struct {
+	PubKey               string   `json:"pubKey"`
+	MasterKeyFingerprint uint32   `json:"masterKeyFingerprint"`
+	Path                 []uint32 `json:"path"`
+}
+
+type unknownJSON struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// MarshalJSON will serialise a partially signed tx to json, with the tx in extended
+// format and all bytes hex encoded.
+func (p *PSBT) MarshalJSON() ([]byte, error) {
+	if p.Tx == nil {
+		return nil, errors.Wrap(ErrMissingTx, "cannot marshal psbt")
+	}
+
+	pj := psbtJSON{
+		Tx:       hex.EncodeToString(p.Tx.ExtendedBytes()),
+		Inputs:   make([]*inputJSON, 0, len(p.Inputs)),
+		Outputs:  make([]*outputJSON, 0, len(p.Outputs)),
+		Unknowns: unknownsToJSON(p.Unknowns),
+	}
+	for _, in := range p.Inputs {
+		ij := &inputJSON{
+			SigHashFlag:          in.SigHashFlag,
+			RedeemScript:         in.RedeemScript,
+			Bip32Derivations:     bip32DerivationsToJSON(in.Bip32Derivations),
+			FinalUnlockingScript: in.FinalUnlockingScript,
+			Unknowns:             unknownsToJSON(in.Unknowns),
+		}
+		for _, ps := range in.PartialSigs {
+			ij.PartialSigs = append(ij.PartialSigs, &partialSigJSON{
+				PubKey:    hex.EncodeToString(ps.PubKey),
+				Signature: hex.EncodeToString(ps.Signature),
+			})
+		}
+		pj.Inputs = append(pj.Inputs, ij)
+	}
+	for _, out := range p.Outputs {
+		pj.Outputs = append(pj.Outputs, &outputJSON{
+			RedeemScript:     out.RedeemScript,
+			Bip32Derivations: bip32DerivationsToJSON(out.Bip32Derivations),
+			Unknowns:         unknownsToJSON(out.Unknowns),
+		})
+	}
+
+	return json.Marshal(pj)
+}
+
+// UnmarshalJSON will unmarshall a partially signed tx that has been marshalled with
+// this library.
+func (p *PSBT) UnmarshalJSON(b []byte) error {
+	var pj psbtJSON
+	if err := json.Unmarshal(b, &pj); err != nil {
+		return err
+	}
+
+	if pj.Tx == "" {
+		return ErrMissingTx
+	}
+	tx, err := bt.NewTxFromString(pj.Tx)
+	if err != nil {
+		return err
+	}
+	if len(pj.Inputs) != tx.InputCount() {
+		return ErrInputCountMismatch
+	}
+	if len(pj.Outputs) != tx.OutputCount() {
+		return ErrOutputCountMismatch
+	}
+
+	np, err := New(tx)
+	if err != nil {
+		return err
+	}
+
+	if np.Unknowns, err = unknownsFromJSON(pj.Unknowns); err != nil {
+		return err
+	}
+
+	for i, ij := range pj.Inputs {
+		in := np.Inputs[i]
+		in.SigHashFlag = ij.SigHashFlag
+		in.RedeemScript = ij.RedeemScript
+		in.FinalUnlockingScript = ij.FinalUnlockingScript
+		if in.Bip32Derivations, err = bip32DerivationsFromJSON(ij.Bip32Derivations); err != nil {
+			return err
+		}
+		if in.Unknowns, err = unknownsFromJSON(ij.Unknowns); err != nil {
+			return err
+		}
+		for _, psj := range ij.PartialSigs {
+			ps := &PartialSig{}
+			if ps.PubKey, err = hex.DecodeString(psj.PubKey); err != nil {
+				return err
+			}
+			if ps.Signature, err = hex.DecodeString(psj.Signature); err != nil {
+				return err
+			}
+			in.addPartialSig(ps)
+		}
+	}
+	for i, oj := range pj.Outputs {
+		out := np.Outputs[i]
+		out.RedeemScript = oj.RedeemScript
+		if out.Bip32Derivations, err = bip32DerivationsFromJSON(oj.Bip32Derivations); err != nil {
+			return err
+		}
+		if out.Unknowns, err = unknownsFromJSON(oj.Unknowns); err != nil {
+			return err
+		}
+	}
+
+	*p = *np
+
+	return nil
+}
+
+func bip32DerivationsToJSON(dd []*Bip32Derivation) []*bip32DerivationJSON {
+	var djs []*bip32DerivationJSON
+	for _, d := range dd {
+		djs = append(djs, &bip32DerivationJSON{
+			PubKey:               hex.EncodeToString(d.PubKey),
+			MasterKeyFingerprint: d.MasterKeyFingerprint,
+			Path:                 d.Path,
+		})
+	}
+
+	return djs
+}
+
+func bip32DerivationsFromJSON(djs []*bip32DerivationJSON) ([]*Bip32Derivation, error) {
+	var dd []*Bip32Derivation
+	for _, dj := range djs {
+		pubKey, err := hex.DecodeString(dj.PubKey)
+		if err != nil {
+			return nil, err
+		}
+		dd = addBip32Derivation(dd, &Bip32Derivation{
+			PubKey:               pubKey,
+			MasterKeyFingerprint: dj.MasterKeyFingerprint,
+			Path:                 dj.Path,
+		})
+	}
+
+	return dd, nil
+}
+
+func unknownsToJSON(uu []*Unknown) []*unknownJSON {
+	var ujs []*unknownJSON
+	for _, u := range uu {
+		ujs = append(ujs, &unknownJSON{
+			Key:   hex.EncodeToString(u.Key),
+			Value: hex.EncodeToString(u.Value),
+		})
+	}
+
+	return ujs
+}
+
+func unknownsFromJSON(ujs []*unknownJSON) ([]*Unknown, error) {
+	var uu []*Unknown
+	for _, uj := range ujs {
+		k, err := hex.DecodeString(uj.Key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := hex.DecodeString(uj.Value)
+		if err != nil {
+			return nil, err
+		}
+		uu = append(uu, &Unknown{Key: k, Value: v})
+	}
+
+	return uu, nil
+}
@@ -0,0 +1,361 @@
+// Package psbt provides a partially signed tx container, allowing an unsigned `bt.Tx`
+// to be passed between several co-signers, each adding their partial signatures,
+// before being finalized and extracted as a fully signed tx.
+//
+// It is modelled on BIP174, but carries the unsigned tx in extended format, so the
+// previous output of each input travels with the tx itself.
+//
+// A typical flow is:
+//
+//	p, err := psbt.New(tx)                  // creator
+//	err = p.Sign(0, privateKey)             // each signer, on their own copy
+//	err = p.Combine(otherSignersPSBTs...)   // combiner
+//	err = p.Finalize()                      // finalizer
+//	signedTx, err := p.Extract()            // extractor
+package psbt
+
+import (
+	"bytes"
+	"encoding/hex"
+	"sort"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+	"github.com/mvc-labs/mvc-lib-go/keys/bec"
+	"github.com/mvc-labs/mvc-lib-go/keys/crypto"
+	"github.com/mvc-labs/mvc-lib-go/sighash"
+)
+
+// PSBT is a partially signed tx. Tx holds the unsigned tx, and Inputs and Outputs
+// hold the signing data for the tx input and output of the same index.
+type PSBT struct {
+	Tx      *bt.Tx
+	Inputs  []*Input
+	Outputs []*Output
+	// Unknowns any global key-value pairs not understood, kept so they survive a round trip.
+	Unknowns []*Unknown
+}
+
+// Input holds the data needed to sign, and the signatures collected for, a tx input.
+type Input struct {
+	// PartialSigs the signatures collected so far, ordered by public key.
+	PartialSigs []*PartialSig
+	// SigHashFlag the sighash flag signers must use. [DEFAULT ALL|FORKID]
+	SigHashFlag sighash.Flag
+	// RedeemScript the redeem script of a P2SH input.
+	RedeemScript *bscript.Script
+	// Bip32Derivations hints of where the keys which can sign the input are derived from.
+	Bip32Derivations []*Bip32Derivation
+	// FinalUnlockingScript the unlocking script of the input, once finalized.
+	FinalUnlockingScript *bscript.Script
+	// Unknowns any key-value pairs not understood, kept so they survive a round trip.
+	Unknowns []*Unknown
+}
+
+// Output holds the data describing a tx output, allowing signers to recognise
+// outputs, such as change, which pay back to themselves.
+type Output struct {
+	// RedeemScript the redeem script of a P2SH output.
+	RedeemScript *bscript.Script
+	// Bip32Derivations hints of where the keys the output pays to are derived from.
+	Bip32Derivations []*Bip32Derivation
+	// Unknowns any key-value pairs not understood, kept so they survive a round trip.
+	Unknowns []*Unknown
+}
+
+// PartialSig is a signature, with the sighash flag appended, made by the private key
+// of PubKey.
+type PartialSig struct {
+	PubKey    []byte
+	Signature []byte
+}
+
+// Bip32Derivation describes the bip32 derivation of PubKey, from the master key with
+// the fingerprint MasterKeyFingerprint, along Path.
+type Bip32Derivation struct {
+	PubKey               []byte
+	MasterKeyFingerprint uint32
+	Path                 []uint32
+}
+
+// Unknown is a key-value pair not understood by this package.
+type Unknown struct {
+	Key   []byte
+	Value []byte
+}
+
+// New creates a *psbt.PSBT for the tx. The tx inputs should have their previous outputs
+// set, as they are needed for signing, and must not yet have unlocking scripts.
+func New(tx *bt.Tx) (*PSBT, error) {
+	for _, in := range tx.Inputs {
+		if in.UnlockingScript != nil && len(*in.UnlockingScript) > 0 {
+			return nil, ErrTxSigned
+		}
+	}
+
+	p := &PSBT{
+		Tx:      tx.Clone(),
+		Inputs:  make([]*Input, tx.InputCount()),
+		Outputs: make([]*Output, tx.OutputCount()),
+	}
+	for i := range p.Inputs {
+		p.Inputs[i] = &Input{}
+	}
+	for i := range p.Outputs {
+		p.Outputs[i] = &Output{}
+	}
+
+	return p, nil
+}
+
+// NewFromString creates a *psbt.PSBT from its hex encoded binary format.
+func NewFromString(str string) (*PSBT, error) {
+	b, err := hex.DecodeString(str)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewFromBytes(b)
+}
+
+// NewFromBytes creates a *psbt.PSBT from its binary format.
+func NewFromBytes(b []byte) (*PSBT, error) {
+	r := bytes.NewReader(b)
+
+	p := &PSBT{}
+	if _, err := p.ReadFrom(r); err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, ErrTrailingData
+	}
+
+	return p, nil
+}
+
+// IsFinalized returns true if the input has a final unlocking script.
+func (i *Input) IsFinalized() bool {
+	return i.FinalUnlockingScript != nil
+}
+
+// IsComplete returns true if every input has been finalized, and so the signed tx
+// can be extracted.
+func (p *PSBT) IsComplete() bool {
+	for _, in := range p.Inputs {
+		if !in.IsFinalized() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Sign adds a partial signature, made with the private key, to the input at the index.
+// The input is signed with its SigHashFlag, defaulting to ALL|FORKID.
+//
+// If the public key of the private key, or its hash, is not found in the script code
+// of the input, a psbt.ErrKeyNotInScript is returned.
+func (p *PSBT) Sign(idx int, pk *bec.PrivateKey) error {
+	in, err := p.input(idx)
+	if err != nil {
+		return err
+	}
+
+	sc, err := p.ScriptCode(idx)
+	if err != nil {
+		return err
+	}
+	pubKey, err := signingPubKey(sc, pk.PubKey())
+	if err != nil {
+		return err
+	}
+
+	shf := in.sigHashFlag()
+	sh, err := p.signatureHash(idx, shf)
+	if err != nil {
+		return err
+	}
+
+	sig, err := pk.Sign(sh)
+	if err != nil {
+		return err
+	}
+
+	in.addPartialSig(&PartialSig{
+		PubKey:    pubKey,
+		Signature: append(sig.Serialise(), uint8(shf)),
+	})
+
+	return nil
+}
+
+// AddPartialSig adds a signature made by a co-signer to the input at the index. The
+// signature must have the sighash flag of the input appended, and be valid for the
+// public key, otherwise a psbt.ErrSigHashMismatch or psbt.ErrInvalidSignature is
+// returned.
+func (p *PSBT) AddPartialSig(idx int, pubKey, sig []byte) error {
+	in, err := p.input(idx)
+	if err != nil {
+		return err
+	}
+	if len(sig) == 0 {
+		return ErrInvalidSignature
+	}
+
+	shf := sighash.Flag(sig[len(sig)-1])
+	if shf != in.sigHashFlag() {
+		return ErrSigHashMismatch
+	}
+
+	sh, err := p.signatureHash(idx, shf)
+	if err != nil {
+		return err
+	}
+
+	pk, err := bec.ParsePubKey(pubKey, bec.S256())
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	s, err := bec.ParseDERSignature(sig[:len(sig)-1], bec.S256())
+	if err != nil || !s.Verify(sh, pk) {
+		return ErrInvalidSignature
+	}
+
+	in.addPartialSig(&PartialSig{PubKey: pubKey, Signature: sig})
+
+	return nil
+}
+
+// AddBip32Derivation adds a bip32 derivation hint to the input at the index, replacing
+// any existing hint for the same public key.
+func (p *PSBT) AddBip32Derivation(idx int, d *Bip32Derivation) error {
+	in, err := p.input(idx)
+	if err != nil {
+		return err
+	}
+
+	in.Bip32Derivations = addBip32Derivation(in.Bip32Derivations, d)
+
+	return nil
+}
+
+// ScriptCode returns the script signed over for the input at the index. This is the
+// previous output's locking script, or the RedeemScript of a P2SH input.
+func (p *PSBT) ScriptCode(idx int) (*bscript.Script, error) {
+	in, err := p.input(idx)
+	if err != nil {
+		return nil, err
+	}
+
+	ls := p.Tx.Inputs[idx].PreviousTxScript
+	if ls == nil || !ls.IsP2SH() {
+		return ls, nil
+	}
+	if in.RedeemScript == nil {
+		return nil, ErrNoRedeemScript
+	}
+	if !bytes.Equal((*ls)[2:22], crypto.Hash160(*in.RedeemScript)) {
+		return nil, ErrRedeemScriptMismatch
+	}
+
+	return in.RedeemScript, nil
+}
+
+func (p *PSBT) input(idx int) (*Input, error) {
+	if idx < 0 || idx >= len(p.Inputs) {
+		return nil, bt.ErrInputNoExist
+	}
+
+	in := p.Inputs[idx]
+	if in.IsFinalized() {
+		return nil, ErrInputFinalized
+	}
+
+	return in, nil
+}
+
+// signatureHash calculates the signature hash of the input at the index, with the
+// script code standing in for the previous output's locking script.
+func (p *PSBT) signatureHash(idx int, shf sighash.Flag) ([]byte, error) {
+	sc, err := p.ScriptCode(idx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := *p.Tx
+	tx.Inputs = make([]*bt.Input, len(p.Tx.Inputs))
+	copy(tx.Inputs, p.Tx.Inputs)
+	in := *tx.Inputs[idx]
+	in.PreviousTxScript = sc
+	tx.Inputs[idx] = &in
+
+	return tx.CalcInputSignatureHash(uint32(idx), shf)
+}
+
+// signingPubKey returns the serialisation of the public key used by the script code,
+// being uncompressed only if the script code pays to the uncompressed key. If the script
+// code holds neither serialisation, nor their hash, a psbt.ErrKeyNotInScript is returned.
+func signingPubKey(sc *bscript.Script, pubKey *bec.PublicKey) ([]byte, error) {
+	if sc == nil {
+		return nil, ErrKeyNotInScript
+	}
+
+	for _, pk := range [][]byte{pubKey.SerialiseUncompressed(), pubKey.SerialiseCompressed()} {
+		if bytes.Contains(*sc, pk) || bytes.Contains(*sc, crypto.Hash160(pk)) {
+			return pk, nil
+		}
+	}
+
+	return nil, ErrKeyNotInScript
+}
+
+func (i *Input) sigHashFlag() sighash.Flag {
+	if i.SigHashFlag == 0 {
+		return sighash.AllForkID
+	}
+
+	return i.SigHashFlag
+}
+
+// addPartialSig adds the partial signature, keeping them ordered by public key. An
+// existing signature for the same public key is kept.
+func (i *Input) addPartialSig(ps *PartialSig) {
+	n := sort.Search(len(i.PartialSigs), func(j int) bool {
+		return bytes.Compare(i.PartialSigs[j].PubKey, ps.PubKey) >= 0
+	})
+	if n < len(i.PartialSigs) && bytes.Equal(i.PartialSigs[n].PubKey, ps.PubKey) {
+		return
+	}
+
+	i.PartialSigs = append(i.PartialSigs, nil)
+	copy(i.PartialSigs[n+1:], i.PartialSigs[n:])
+	i.PartialSigs[n] = ps
+}
+
+// partialSig returns the partial signature made by the public key, if there is one.
+func (i *Input) partialSig(pubKey []byte) []byte {
+	for _, ps := range i.PartialSigs {
+		if bytes.Equal(ps.PubKey, pubKey) {
+			return ps.Signature
+		}
+	}
+
+	return nil
+}
+
+// addBip32Derivation adds the derivation, keeping them ordered by public key.
+func addBip32Derivation(dd []*Bip32Derivation, d *Bip32Derivation) []*Bip32Derivation {
+	n := sort.Search(len(dd), func(j int) bool {
+		return bytes.Compare(dd[j].PubKey, d.PubKey) >= 0
+	})
+	if n < len(dd) && bytes.Equal(dd[n].PubKey, d.PubKey) {
+		dd[n] = d
+		return dd
+	}
+
+	dd = append(dd, nil)
+	copy(dd[n+1:], dd[n:])
+	dd[n] = d
+
+	return dd
+}
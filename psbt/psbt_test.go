@@ -0,0 +1,286 @@
+package psbt_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+	"github.com/mvc-labs/mvc-lib-go/bscript/interpreter"
+	"github.com/mvc-labs/mvc-lib-go/keys/bec"
+	"github.com/mvc-labs/mvc-lib-go/keys/crypto"
+	"github.com/mvc-labs/mvc-lib-go/psbt"
+	"github.com/mvc-labs/mvc-lib-go/sighash"
+	"github.com/stretchr/testify/assert"
+)
+
+type fixture struct {
+	keys         []*bec.PrivateKey
+	redeemScript *bscript.Script
+	tx           *bt.Tx
+}
+
+// newFixture builds an unsigned tx spending a P2PKH output of keys[0] and a P2SH output
+// locked to a 2 of 3 multisig redeem script of keys[1:].
+func newFixture(t *testing.T) *fixture {
+	f := &fixture{keys: make([]*bec.PrivateKey, 4)}
+	for i := range f.keys {
+		pk, err := bec.NewPrivateKey(bec.S256())
+		assert.NoError(t, err)
+		f.keys[i] = pk
+	}
+
+	f.redeemScript = &bscript.Script{}
+	assert.NoError(t, f.redeemScript.AppendOpcodes(bscript.Op2))
+	for _, pk := range f.keys[1:] {
+		assert.NoError(t, f.redeemScript.AppendPushData(pk.PubKey().SerialiseCompressed()))
+	}
+	assert.NoError(t, f.redeemScript.AppendOpcodes(bscript.Op3, bscript.OpCHECKMULTISIG))
+
+	p2pkh, err := bscript.NewP2PKHFromPubKeyEC(f.keys[0].PubKey())
+	assert.NoError(t, err)
+	p2sh := &bscript.Script{}
+	assert.NoError(t, p2sh.AppendOpcodes(bscript.OpHASH160))
+	assert.NoError(t, p2sh.AppendPushData(crypto.Hash160(*f.redeemScript)))
+	assert.NoError(t, p2sh.AppendOpcodes(bscript.OpEQUAL))
+
+	f.tx = bt.NewTx()
+	assert.NoError(t, f.tx.From(
+		"45be95d2f2c64e99518ffbbce03fb15a7758f20ee5eecf0df07938d977add71d", 0, p2pkh.String(), 10000,
+	))
+	assert.NoError(t, f.tx.From(
+		"45be95d2f2c64e99518ffbbce03fb15a7758f20ee5eecf0df07938d977add71d", 1, p2sh.String(), 20000,
+	))
+	assert.NoError(t, f.tx.PayToAddress("1NRoySJ9Lvby6DuE2UQYnyT67AASwNZxGb", 29000))
+
+	return f
+}
+
+func (f *fixture) psbt(t *testing.T) *psbt.PSBT {
+	p, err := psbt.New(f.tx)
+	assert.NoError(t, err)
+	p.Inputs[1].RedeemScript = f.redeemScript
+	assert.NoError(t, p.AddBip32Derivation(0, &psbt.Bip32Derivation{
+		PubKey:               f.keys[0].PubKey().SerialiseCompressed(),
+		MasterKeyFingerprint: 0xdeadbeef,
+		Path:                 []uint32{44 | 1<<31, 0, 7},
+	}))
+	return p
+}
+
+// roundTrip passes the partially signed tx through its binary encoding, as it would be
+// when sent to a co-signer.
+func roundTrip(t *testing.T, p *psbt.PSBT) *psbt.PSBT {
+	rt, err := psbt.NewFromBytes(p.Bytes())
+	assert.NoError(t, err)
+	return rt
+}
+
+func TestPSBT_MultiPartySigning(t *testing.T) {
+	t.Parallel()
+
+	f := newFixture(t)
+	creator := f.psbt(t)
+
+	signerA := roundTrip(t, creator)
+	assert.NoError(t, signerA.Sign(0, f.keys[0]))
+	assert.NoError(t, signerA.Sign(1, f.keys[3]))
+
+	signerB := roundTrip(t, creator)
+	assert.NoError(t, signerB.Sign(1, f.keys[1]))
+
+	combined := roundTrip(t, creator)
+	assert.NoError(t, combined.Combine(roundTrip(t, signerA), roundTrip(t, signerB)))
+	assert.Len(t, combined.Inputs[1].PartialSigs, 2)
+
+	// Combining is idempotent.
+	assert.NoError(t, combined.Combine(signerA))
+	assert.Len(t, combined.Inputs[1].PartialSigs, 2)
+
+	_, err := combined.Extract()
+	assert.True(t, errors.Is(err, psbt.ErrNotFinalized))
+
+	assert.NoError(t, combined.Finalize())
+	assert.True(t, combined.IsComplete())
+	assert.Empty(t, combined.Inputs[0].PartialSigs)
+	assert.Empty(t, combined.Inputs[0].Bip32Derivations)
+
+	tx, err := roundTrip(t, combined).Extract()
+	assert.NoError(t, err)
+
+	report, err := interpreter.VerifyTx(context.Background(), tx,
+		interpreter.WithExecutionOptions(interpreter.WithForkID(), interpreter.WithP2SH()))
+	assert.NoError(t, err)
+	assert.NoError(t, report.Err())
+}
+
+func TestPSBT_AddPartialSig(t *testing.T) {
+	t.Parallel()
+
+	f := newFixture(t)
+
+	// Signature made by a co-signer, outside of the psbt.
+	signed := f.psbt(t)
+	assert.NoError(t, signed.Sign(0, f.keys[0]))
+	ps := signed.Inputs[0].PartialSigs[0]
+
+	t.Run("valid signature", func(t *testing.T) {
+		p := f.psbt(t)
+		assert.NoError(t, p.AddPartialSig(0, ps.PubKey, ps.Signature))
+		assert.NoError(t, p.FinalizeInput(0))
+	})
+
+	t.Run("signature for another input", func(t *testing.T) {
+		p := f.psbt(t)
+		err := p.AddPartialSig(1, ps.PubKey, ps.Signature)
+		assert.True(t, errors.Is(err, psbt.ErrInvalidSignature))
+	})
+
+	t.Run("sighash flag mismatch", func(t *testing.T) {
+		p := f.psbt(t)
+		p.Inputs[0].SigHashFlag = sighash.SingleForkID
+		err := p.AddPartialSig(0, ps.PubKey, ps.Signature)
+		assert.True(t, errors.Is(err, psbt.ErrSigHashMismatch))
+	})
+
+	t.Run("redeem script on P2PKH input", func(t *testing.T) {
+		p := f.psbt(t)
+		p.Inputs[0].RedeemScript = f.redeemScript
+		assert.NoError(t, p.AddPartialSig(0, ps.PubKey, ps.Signature))
+		assert.NoError(t, p.FinalizeInput(0))
+
+		parts, err := bscript.DecodeParts(*p.Inputs[0].FinalUnlockingScript)
+		assert.NoError(t, err)
+		assert.Equal(t, [][]byte{ps.Signature, ps.PubKey}, parts)
+	})
+
+	t.Run("input finalized", func(t *testing.T) {
+		p := f.psbt(t)
+		assert.NoError(t, p.Sign(0, f.keys[0]))
+		assert.NoError(t, p.FinalizeInput(0))
+		assert.True(t, errors.Is(p.Sign(0, f.keys[0]), psbt.ErrInputFinalized))
+	})
+
+	t.Run("no redeem script", func(t *testing.T) {
+		p, err := psbt.New(f.tx)
+		assert.NoError(t, err)
+		assert.True(t, errors.Is(p.Sign(1, f.keys[1]), psbt.ErrNoRedeemScript))
+	})
+
+	t.Run("key not in script", func(t *testing.T) {
+		p := f.psbt(t)
+		assert.True(t, errors.Is(p.Sign(0, f.keys[1]), psbt.ErrKeyNotInScript))
+		assert.True(t, errors.Is(p.Sign(1, f.keys[0]), psbt.ErrKeyNotInScript))
+		assert.Empty(t, p.Inputs[0].PartialSigs)
+		assert.Empty(t, p.Inputs[1].PartialSigs)
+	})
+}
+
+func TestPSBT_Combine(t *testing.T) {
+	t.Parallel()
+
+	f := newFixture(t)
+
+	t.Run("different tx", func(t *testing.T) {
+		other := newFixture(t)
+		err := f.psbt(t).Combine(other.psbt(t))
+		assert.True(t, errors.Is(err, psbt.ErrTxMismatch))
+	})
+
+	t.Run("conflicting sighash flags leave receiver unchanged", func(t *testing.T) {
+		p := f.psbt(t)
+		p.Inputs[1].SigHashFlag = sighash.AllForkID
+		assert.NoError(t, p.Sign(0, f.keys[0]))
+		before := p.Bytes()
+
+		other := f.psbt(t)
+		other.Inputs[1].SigHashFlag = sighash.SingleForkID
+		assert.NoError(t, other.Sign(1, f.keys[1]))
+
+		err := p.Combine(other)
+		assert.True(t, errors.Is(err, psbt.ErrSigHashMismatch))
+		assert.Equal(t, before, p.Bytes())
+	})
+}
+
+func TestPSBT_Finalize(t *testing.T) {
+	t.Parallel()
+
+	f := newFixture(t)
+	p := f.psbt(t)
+	assert.NoError(t, p.Sign(0, f.keys[0]))
+	assert.NoError(t, p.Sign(1, f.keys[2]))
+
+	err := p.Finalize()
+	assert.True(t, errors.Is(err, psbt.ErrNotEnoughSignatures))
+	assert.True(t, p.Inputs[0].IsFinalized())
+	assert.False(t, p.Inputs[1].IsFinalized())
+}
+
+func TestPSBT_Encoding(t *testing.T) {
+	t.Parallel()
+
+	f := newFixture(t)
+	p := f.psbt(t)
+	p.Inputs[1].SigHashFlag = sighash.AllForkID
+	assert.NoError(t, p.Sign(0, f.keys[0]))
+	assert.NoError(t, p.Sign(1, f.keys[1]))
+	assert.NoError(t, p.FinalizeInput(0))
+	p.Inputs[1].Unknowns = []*psbt.Unknown{{Key: []byte{0xfc, 0x01}, Value: []byte("hello")}}
+	p.Unknowns = []*psbt.Unknown{{Key: []byte{0xfc, 0x02}, Value: []byte("global")}}
+	p.Outputs[0].Bip32Derivations = []*psbt.Bip32Derivation{{
+		PubKey: f.keys[0].PubKey().SerialiseCompressed(),
+		Path:   []uint32{1, 2},
+	}}
+
+	t.Run("binary", func(t *testing.T) {
+		rt, err := psbt.NewFromString(p.String())
+		assert.NoError(t, err)
+		assert.Equal(t, p.Bytes(), rt.Bytes())
+		assert.Equal(t, p, rt)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		bb, err := json.Marshal(p)
+		assert.NoError(t, err)
+
+		var rt psbt.PSBT
+		assert.NoError(t, json.Unmarshal(bb, &rt))
+		assert.Equal(t, p.Bytes(), rt.Bytes())
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		b := p.Bytes()
+
+		_, err := psbt.NewFromBytes(append([]byte{0x00}, b[1:]...))
+		assert.True(t, errors.Is(err, psbt.ErrInvalidMagic))
+
+		_, err = psbt.NewFromBytes(append(b, 0x00))
+		assert.True(t, errors.Is(err, psbt.ErrTrailingData))
+
+		_, err = psbt.NewFromBytes(b[:len(b)-1])
+		assert.Error(t, err)
+
+		// A key length of 2^63-1, far beyond the bytes left in the reader.
+		huge := []byte{0x70, 0x73, 0x62, 0x74, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f}
+		_, err = psbt.NewFromBytes(huge)
+		assert.Error(t, err)
+
+		huge[len(huge)-1] = 0xff
+		_, err = psbt.NewFromBytes(huge)
+		assert.Error(t, err)
+	})
+
+	t.Run("signed tx", func(t *testing.T) {
+		tx, err := p.Extract()
+		assert.True(t, errors.Is(err, psbt.ErrNotFinalized))
+		assert.Nil(t, tx)
+
+		signed := f.tx.Clone()
+		signed.Inputs[0].UnlockingScript = bscript.NewFromBytes([]byte{0x51})
+		_, err = psbt.New(signed)
+		assert.True(t, errors.Is(err, psbt.ErrTxSigned))
+	})
+}
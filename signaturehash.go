@@ -18,9 +18,11 @@ type sigHashFunc func(inputIdx uint32, shf sighash.Flag) ([]byte, error)
 // The legacy serialisation will be used for txs pre-fork
 // whereas the new serialisation will be used for post-fork
 // txs (and they should include the sighash_forkid flag).
-func (tx *Tx) sigStrat(shf sighash.Flag) sigHashFunc {
+func (tx *Tx) sigStrat(shf sighash.Flag, sh *SigHashes) sigHashFunc {
 	if shf.Has(sighash.ForkID) {
-		return tx.CalcInputPreimage
+		return func(inputIdx uint32, shf sighash.Flag) ([]byte, error) {
+			return tx.CalcInputPreimageWithSigHashes(inputIdx, shf, sh)
+		}
 	}
 	return tx.CalcInputPreimageLegacy
 }
@@ -29,7 +31,18 @@ func (tx *Tx) sigStrat(shf sighash.Flag) sigHashFunc {
 // to be signed.
 //
 func (tx *Tx) CalcInputSignatureHash(inputNumber uint32, sigHashFlag sighash.Flag) ([]byte, error) {
-	sigHashFn := tx.sigStrat(sigHashFlag)
+	return tx.CalcInputSignatureHashWithSigHashes(inputNumber, sigHashFlag, nil)
+}
+
+// CalcInputSignatureHashWithSigHashes serialised the transaction and returns the hash digest
+// to be signed, using the provided precomputed *bt.SigHashes rather than calculating them.
+// If sh is nil, the hashes are calculated as normal.
+//
+// This should be preferred over CalcInputSignatureHash when calculating the signature hash
+// of many inputs of the same tx.
+func (tx *Tx) CalcInputSignatureHashWithSigHashes(inputNumber uint32, sigHashFlag sighash.Flag,
+	sh *SigHashes) ([]byte, error) {
+	sigHashFn := tx.sigStrat(sigHashFlag, sh)
 	buf, err := sigHashFn(inputNumber, sigHashFlag)
 	if err != nil {
 		return nil, err
@@ -55,6 +68,15 @@ func (tx *Tx) CalcInputSignatureHash(inputNumber uint32, sigHashFlag sighash.Fla
 // and returns the preimage before double hashing (SHA256d).
 //
 func (tx *Tx) CalcInputPreimage(inputNumber uint32, sigHashFlag sighash.Flag) ([]byte, error) {
+	return tx.CalcInputPreimageWithSigHashes(inputNumber, sigHashFlag, nil)
+}
+
+// CalcInputPreimageWithSigHashes serialises the transaction based on the input index and the
+// SIGHASH flag, using the provided precomputed *bt.SigHashes rather than calculating them, and
+// returns the preimage before double hashing (SHA256d). If sh is nil, the hashes are calculated
+// as normal.
+func (tx *Tx) CalcInputPreimageWithSigHashes(inputNumber uint32, sigHashFlag sighash.Flag,
+	sh *SigHashes) ([]byte, error) {
 	if tx.InputIdx(int(inputNumber)) == nil {
 		return nil, ErrInputNoExist
 	}
@@ -71,18 +93,31 @@ func (tx *Tx) CalcInputPreimage(inputNumber uint32, sigHashFlag sighash.Flag) ([
 	hashSequence := make([]byte, 32)
 	hashOutputs := make([]byte, 32)
 
+	if sh == nil {
+		sh = &SigHashes{}
+	}
+
 	if sigHashFlag&sighash.AnyOneCanPay == 0 {
-		hashPreviousOuts = tx.PreviousOutHash()
+		hashPreviousOuts = sh.HashPrevOuts
+		if hashPreviousOuts == nil {
+			hashPreviousOuts = tx.PreviousOutHash()
+		}
 	}
 
 	if sigHashFlag&sighash.AnyOneCanPay == 0 &&
 		(sigHashFlag&31) != sighash.Single &&
 		(sigHashFlag&31) != sighash.None {
-		hashSequence = tx.SequenceHash()
+		hashSequence = sh.HashSequence
+		if hashSequence == nil {
+			hashSequence = tx.SequenceHash()
+		}
 	}
 
 	if (sigHashFlag&31) != sighash.Single && (sigHashFlag&31) != sighash.None {
-		hashOutputs = tx.OutputsHash(-1)
+		hashOutputs = sh.HashOutputs
+		if hashOutputs == nil {
+			hashOutputs = tx.OutputsHash(-1)
+		}
 	} else if (sigHashFlag&31) == sighash.Single && inputNumber < uint32(tx.OutputCount()) {
 		hashOutputs = tx.OutputsHash(int32(inputNumber))
 	}
@@ -254,6 +289,27 @@ func (tx *Tx) CalcInputPreimageLegacy(inputNumber uint32, shf sighash.Flag) ([]b
 	return append(buf, sh...), nil
 }
 
+// SigHashes holds the hashes of the previous outputs, sequence numbers and outputs
+// of a tx, which are common to the signature hash preimage of every one of its inputs.
+//
+// Calculating these once per tx, rather than once per input, avoids hashing the whole
+// tx for each input being signed or verified. A *SigHashes is only valid for the tx
+// it was created from, and must be recreated if the inputs or outputs of the tx change.
+type SigHashes struct {
+	HashPrevOuts []byte
+	HashSequence []byte
+	HashOutputs  []byte
+}
+
+// NewSigHashes calculates and returns the *bt.SigHashes of the provided tx.
+func NewSigHashes(tx *Tx) *SigHashes {
+	return &SigHashes{
+		HashPrevOuts: tx.PreviousOutHash(),
+		HashSequence: tx.SequenceHash(),
+		HashOutputs:  tx.OutputsHash(-1),
+	}
+}
+
 // OutputsHash returns a bytes slice of the requested output, used for generating
 // the txs signature hash. If n is -1, it will create the byte slice from all outputs.
 func (tx *Tx) OutputsHash(n int32) []byte {
@@ -0,0 +1,13 @@
+package spv
+
+import "github.com/pkg/errors"
+
+// Sentinel errors reported by SPV verification.
+var (
+	ErrNoHeaderGetter = errors.New("header getter not supplied")
+	ErrMissingInput   = errors.New("input spends a tx not included in the BEEF")
+	ErrNotTopological = errors.New("BEEF txs are not in topological order")
+	ErrInvalidProof   = errors.New("merkle proof does not match the block header")
+	ErrInvalidTx      = errors.New("tx failed verification")
+	ErrNoInputs       = errors.New("unconfirmed tx has no inputs to verify")
+)
@@ -0,0 +1,160 @@
+// Package spv provides Simplified Payment Verification of txs packaged as a `bt.BEEF`,
+// checking a tx against its ancestors and the block headers they were mined in,
+// rather than against a node.
+package spv
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/bscript/interpreter"
+)
+
+// HeaderGetter is used for spv.VerifyBEEF(...). It provides the block header of the
+// block at the given height, from the chain the caller trusts.
+type HeaderGetter interface {
+	BlockHeader(ctx context.Context, height uint64) (*bt.BlockHeader, error)
+}
+
+// HeaderGetterFunc is a function implementing spv.HeaderGetter.
+type HeaderGetterFunc func(ctx context.Context, height uint64) (*bt.BlockHeader, error)
+
+// BlockHeader calls f(ctx, height).
+func (f HeaderGetterFunc) BlockHeader(ctx context.Context, height uint64) (*bt.BlockHeader, error) {
+	return f(ctx, height)
+}
+
+// VerifyBEEF verifies the tx the BEEF is about by walking its ancestry, in the order
+// the txs appear in the BEEF. For each tx:
+//   - confirmed txs have their BUMP checked against the block header, provided by
+//     the spv.HeaderGetter, for the height of the BUMP.
+//   - unconfirmed txs must spend outputs of txs earlier in the BEEF, and have the
+//     scripts of every input verified against those outputs.
+//
+// The provided interpreter.VerifyOptionFuncs are used when verifying scripts, as
+// described in interpreter.VerifyTx(...).
+//
+// Nil is returned only if every tx in the BEEF is valid.
+//
+// Example usage:
+//
+//	beef, err := bt.NewBEEFFromBytes(b)
+//	if err != nil {
+//	    return err
+//	}
+//	if err = spv.VerifyBEEF(ctx, beef, headers); err != nil {
+//	    return err
+//	}
+func VerifyBEEF(ctx context.Context, beef *bt.BEEF, hg HeaderGetter, oo ...interpreter.VerifyOptionFunc) error {
+	if beef == nil || len(beef.Txs) == 0 {
+		return bt.ErrBEEFNoTxs
+	}
+	if hg == nil {
+		return ErrNoHeaderGetter
+	}
+
+	v := &verifier{
+		hg:      hg,
+		oo:      oo,
+		headers: make(map[uint64]*bt.BlockHeader),
+		txs:     make(map[string]*bt.Tx, len(beef.Txs)),
+		pending: make(map[string]struct{}, len(beef.Txs)),
+	}
+	for _, btx := range beef.Txs {
+		v.pending[btx.Tx.TxID()] = struct{}{}
+	}
+
+	for _, btx := range beef.Txs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		txID := btx.Tx.TxID()
+		var err error
+		if btx.BUMP != nil {
+			err = v.verifyProof(ctx, btx.Tx, btx.BUMP)
+		} else {
+			err = v.verifyScripts(ctx, btx.Tx)
+		}
+		if err != nil {
+			return fmt.Errorf("tx %s: %w", txID, err)
+		}
+
+		delete(v.pending, txID)
+		v.txs[txID] = btx.Tx
+	}
+
+	return nil
+}
+
+type verifier struct {
+	hg      HeaderGetter
+	oo      []interpreter.VerifyOptionFunc
+	headers map[uint64]*bt.BlockHeader
+	// txs the txs verified so far, by txid.
+	txs map[string]*bt.Tx
+	// pending the txs yet to be verified, by txid.
+	pending map[string]struct{}
+}
+
+func (v *verifier) verifyProof(ctx context.Context, tx *bt.Tx, bump *bt.BUMP) error {
+	bh, ok := v.headers[bump.BlockHeight]
+	if !ok {
+		var err error
+		if bh, err = v.hg.BlockHeader(ctx, bump.BlockHeight); err != nil {
+			return err
+		}
+		if bh == nil {
+			return fmt.Errorf("%w: no header for block %d", ErrInvalidProof, bump.BlockHeight)
+		}
+		v.headers[bump.BlockHeight] = bh
+	}
+
+	ok, err := bump.Verify(tx.TxIDBytes(), bh)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: block %d", ErrInvalidProof, bump.BlockHeight)
+	}
+
+	return nil
+}
+
+func (v *verifier) verifyScripts(ctx context.Context, tx *bt.Tx) error {
+	if tx.InputCount() == 0 {
+		return ErrNoInputs
+	}
+
+	// The outputs being spent are written onto a copy, leaving the BEEF untouched.
+	tx = tx.Clone()
+	for i, in := range tx.Inputs {
+		parentID := in.PreviousTxIDStr()
+		parent, ok := v.txs[parentID]
+		if !ok {
+			if _, ok = v.pending[parentID]; ok {
+				return fmt.Errorf("%w: input %d spends %s, which follows it", ErrNotTopological, i, parentID)
+			}
+			return fmt.Errorf("%w: input %d spends %s", ErrMissingInput, i, parentID)
+		}
+		if int(in.PreviousTxOutIndex) >= parent.OutputCount() {
+			return fmt.Errorf("%w: input %d spends output %d of %s, which has %d outputs",
+				ErrMissingInput, i, in.PreviousTxOutIndex, parentID, parent.OutputCount())
+		}
+
+		out := parent.Outputs[in.PreviousTxOutIndex]
+		in.PreviousTxScript = out.LockingScript
+		in.PreviousTxSatoshis = out.Satoshis
+	}
+
+	report, err := interpreter.VerifyTx(ctx, tx, v.oo...)
+	if err != nil {
+		return err
+	}
+	if err = report.Err(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTx, err)
+	}
+
+	return nil
+}
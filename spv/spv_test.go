@@ -0,0 +1,162 @@
+package spv_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+	"github.com/mvc-labs/mvc-lib-go/keys/bec"
+	"github.com/mvc-labs/mvc-lib-go/spv"
+	"github.com/mvc-labs/mvc-lib-go/unlocker"
+	"github.com/stretchr/testify/assert"
+)
+
+const blockHeight = 800000
+
+type chain struct {
+	key     *bec.PrivateKey
+	header  *bt.BlockHeader
+	bump    *bt.BUMP
+	funding *bt.Tx
+	parent  *bt.Tx
+	child   *bt.Tx
+}
+
+// newChain builds a funding tx mined in a block, an unconfirmed parent spending it,
+// and an unconfirmed child spending the parent.
+func newChain(t *testing.T) *chain {
+	key, err := bec.NewPrivateKey(bec.S256())
+	assert.NoError(t, err)
+	lockingScript, err := bscript.NewP2PKHFromPubKeyEC(key.PubKey())
+	assert.NoError(t, err)
+
+	c := &chain{key: key}
+
+	other := bt.NewTx()
+	assert.NoError(t, other.PayTo(lockingScript, 1))
+
+	c.funding = bt.NewTx()
+	assert.NoError(t, c.funding.From(
+		"45be95d2f2c64e99518ffbbce03fb15a7758f20ee5eecf0df07938d977add71d", 0, lockingScript.String(), 20000,
+	))
+	assert.NoError(t, c.funding.PayTo(lockingScript, 15000))
+	assert.NoError(t, c.funding.FillAllInputs(context.Background(), &unlocker.Getter{PrivateKey: key}))
+
+	block := bt.Txs{other, c.funding}
+	root, err := block.MerkleRoot()
+	assert.NoError(t, err)
+	c.header = &bt.BlockHeader{MerkleRoot: root}
+	c.bump, err = block.BUMP(blockHeight, 1)
+	assert.NoError(t, err)
+
+	c.parent = c.spend(t, c.funding, 10000)
+	c.child = c.spend(t, c.parent, 5000)
+
+	return c
+}
+
+func (c *chain) spend(t *testing.T, prev *bt.Tx, sats uint64) *bt.Tx {
+	out := prev.Outputs[0]
+	tx := bt.NewTx()
+	assert.NoError(t, tx.From(prev.TxID(), 0, out.LockingScriptHexString(), out.Satoshis))
+	assert.NoError(t, tx.PayTo(out.LockingScript, sats))
+	assert.NoError(t, tx.FillAllInputs(context.Background(), &unlocker.Getter{PrivateKey: c.key}))
+	return tx
+}
+
+func (c *chain) headers() spv.HeaderGetter {
+	return spv.HeaderGetterFunc(func(ctx context.Context, height uint64) (*bt.BlockHeader, error) {
+		if height != blockHeight {
+			return nil, errors.New("unknown block")
+		}
+		return c.header, nil
+	})
+}
+
+func (c *chain) beef(t *testing.T) *bt.BEEF {
+	beef, err := bt.NewBEEF(bt.Txs{c.funding, c.parent, c.child}, c.bump)
+	assert.NoError(t, err)
+
+	// Verification is performed on what a receiver decodes.
+	beef, err = bt.NewBEEFFromString(beef.String())
+	assert.NoError(t, err)
+	return beef
+}
+
+func TestVerifyBEEF(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid", func(t *testing.T) {
+		c := newChain(t)
+		assert.NoError(t, spv.VerifyBEEF(context.Background(), c.beef(t), c.headers()))
+	})
+
+	t.Run("invalid proof", func(t *testing.T) {
+		c := newChain(t)
+		beef := c.beef(t)
+		c.header = &bt.BlockHeader{MerkleRoot: make([]byte, 32)}
+
+		err := spv.VerifyBEEF(context.Background(), beef, c.headers())
+		assert.True(t, errors.Is(err, spv.ErrInvalidProof))
+	})
+
+	t.Run("header lookup fails", func(t *testing.T) {
+		c := newChain(t)
+		beef := c.beef(t)
+		beef.BUMPs[0].BlockHeight++
+
+		assert.Error(t, spv.VerifyBEEF(context.Background(), beef, c.headers()))
+	})
+
+	t.Run("header getter returns nil", func(t *testing.T) {
+		c := newChain(t)
+		hg := spv.HeaderGetterFunc(func(ctx context.Context, height uint64) (*bt.BlockHeader, error) {
+			return nil, nil
+		})
+
+		err := spv.VerifyBEEF(context.Background(), c.beef(t), hg)
+		assert.True(t, errors.Is(err, spv.ErrInvalidProof))
+	})
+
+	t.Run("missing ancestor", func(t *testing.T) {
+		c := newChain(t)
+		beef := c.beef(t)
+		beef.Txs = beef.Txs[1:]
+
+		err := spv.VerifyBEEF(context.Background(), beef, c.headers())
+		assert.True(t, errors.Is(err, spv.ErrMissingInput))
+	})
+
+	t.Run("not topological", func(t *testing.T) {
+		c := newChain(t)
+		beef := c.beef(t)
+		beef.Txs[1], beef.Txs[2] = beef.Txs[2], beef.Txs[1]
+
+		err := spv.VerifyBEEF(context.Background(), beef, c.headers())
+		assert.True(t, errors.Is(err, spv.ErrNotTopological))
+	})
+
+	t.Run("invalid scripts", func(t *testing.T) {
+		c := newChain(t)
+		c.child.Outputs[0].Satoshis--
+
+		err := spv.VerifyBEEF(context.Background(), c.beef(t), c.headers())
+		assert.True(t, errors.Is(err, spv.ErrInvalidTx))
+	})
+
+	t.Run("spends more than inputs", func(t *testing.T) {
+		c := newChain(t)
+		c.child = c.spend(t, c.parent, 20000)
+
+		err := spv.VerifyBEEF(context.Background(), c.beef(t), c.headers())
+		assert.True(t, errors.Is(err, spv.ErrInvalidTx))
+	})
+
+	t.Run("no header getter", func(t *testing.T) {
+		c := newChain(t)
+		err := spv.VerifyBEEF(context.Background(), c.beef(t), nil)
+		assert.True(t, errors.Is(err, spv.ErrNoHeaderGetter))
+	})
+}
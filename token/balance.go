@@ -0,0 +1,80 @@
+package token
+
+import (
+	"bytes"
+
+	"github.com/mvc-labs/mvc-lib-go"
+)
+
+// Balance is the balance of a token held in a set of UTXOs.
+type Balance struct {
+	Type        Type
+	CodeHash    []byte
+	GenesisHash []byte
+	SensibleID  Outpoint
+
+	// Name, Symbol and Decimal are only set for fungible tokens.
+	Name    string
+	Symbol  string
+	Decimal uint8
+
+	// Amount is the total amount of a fungible token, or the number of tokens
+	// held of a non-fungible token collection.
+	Amount uint64
+
+	// UTXOs are the token UTXOs making up the balance.
+	UTXOs bt.UTXOs
+}
+
+// Balances returns the balance of each token held in the UTXOs, in the order each
+// token is first seen. If owner is not nil, only tokens owned by that public key
+// hash are counted. UTXOs which are not token contracts, and genesis contracts, are
+// ignored.
+func Balances(utxos bt.UTXOs, owner []byte) []*Balance {
+	var bb []*Balance
+	for _, u := range utxos {
+		if u.LockingScript == nil {
+			continue
+		}
+		c, err := NewContractFromScript(u.LockingScript)
+		if err != nil {
+			continue
+		}
+		if (c.FT != nil && c.FT.IsGenesis()) || (c.NFT != nil && c.NFT.Genesis) {
+			continue
+		}
+		if owner != nil && !bytes.Equal(owner, c.Owner()) {
+			continue
+		}
+
+		codeHash := c.CodeHash()
+		var b *Balance
+		for _, existing := range bb {
+			if bytes.Equal(existing.CodeHash, codeHash) && bytes.Equal(existing.GenesisHash, c.GenesisHash()) {
+				b = existing
+				break
+			}
+		}
+		if b == nil {
+			b = &Balance{
+				Type:        c.Type(),
+				CodeHash:    codeHash,
+				GenesisHash: c.GenesisHash(),
+				SensibleID:  c.SensibleID(),
+			}
+			if c.FT != nil {
+				b.Name, b.Symbol, b.Decimal = c.FT.Name, c.FT.Symbol, c.FT.Decimal
+			}
+			bb = append(bb, b)
+		}
+
+		if c.FT != nil {
+			b.Amount += c.FT.Amount
+		} else {
+			b.Amount++
+		}
+		b.UTXOs = append(b.UTXOs, u)
+	}
+
+	return bb
+}
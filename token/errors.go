@@ -0,0 +1,16 @@
+package token
+
+import "github.com/pkg/errors"
+
+// Sentinel errors reported by token contracts.
+var (
+	ErrNotToken           = errors.New("script is not a token contract")
+	ErrTypeMismatch       = errors.New("token contract is not of the expected type")
+	ErrInvalidOwner       = errors.New("owner must be a 20 byte public key hash")
+	ErrInvalidOutpoint    = errors.New("outpoint txid must be 32 bytes")
+	ErrInvalidGenesisHash = errors.New("genesis hash must be 20 bytes")
+	ErrFieldTooLong       = errors.New("token field is too long")
+	ErrNotGenesis         = errors.New("token contract is not a genesis contract")
+	ErrIsGenesis          = errors.New("token contract is a genesis contract")
+	ErrTokenIndex         = errors.New("nft token index exceeds total supply")
+)
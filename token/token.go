@@ -0,0 +1,475 @@
+// Package token recognises, decodes and builds the locking scripts of MVC fungible
+// token (FT) and non-fungible token (NFT) contracts.
+//
+// A token contract locking script is made up of a code part, which is the same for
+// every output of a token and ends with OP_RETURN, followed by a single push of its
+// data part. The data part is a fixed layout, ending with a protocol header:
+//
+//	FT:  <name 40> <symbol 20> <decimal 1> <owner 20> <amount 8> <genesis hash 20> <sensible id 36> <header>
+//	NFT: <metaid outpoint 36> <is genesis 1> <owner 20> <total supply 8> <token index 8> <genesis hash 20> <sensible id 36> <header>
+//
+//	header: <proto version 4> <proto type 4> "metacontract"
+//
+// where numbers are little endian, text is zero padded, the owner is the hash160 of
+// the owner's public key, and the sensible id is the outpoint of the genesis output
+// which issued the token. A token is identified by the hash160 of its code part,
+// the code hash, along with its genesis hash.
+//
+// New outputs of a token are built from the contract being spent, or the genesis
+// contract, which serves as a template for the code part:
+//
+//	s, err := token.NewFTTransferScript(utxo.LockingScript, receiverPKH, 1000)
+package token
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+	"github.com/mvc-labs/mvc-lib-go/keys/chaincfg"
+	"github.com/mvc-labs/mvc-lib-go/keys/crypto"
+)
+
+// ProtoFlag is the flag which ends the data part of every token contract.
+const ProtoFlag = "metacontract"
+
+// Type is the protocol type of a token contract.
+type Type uint32
+
+// Supported token contract types.
+const (
+	TypeFT  Type = 1
+	TypeNFT Type = 3
+)
+
+// Lengths of the fixed width fields of the data part.
+const (
+	NameLen   = 40
+	SymbolLen = 20
+
+	outpointLen = 36
+	headerLen   = 4 + 4 + len(ProtoFlag)
+	ftDataLen   = NameLen + SymbolLen + 1 + 20 + 8 + 20 + outpointLen + headerLen
+	nftDataLen  = outpointLen + 1 + 20 + 8 + 8 + 20 + outpointLen + headerLen
+)
+
+// tokenPattern matches a contract, capturing the push of its data part.
+var tokenPattern = bscript.MustNewPattern("<any*> OP_RETURN <data:data>")
+
+// Outpoint is a tx output, as referenced by a token contract.
+type Outpoint struct {
+	// TxID is the txid, in display order.
+	TxID []byte
+	Vout uint32
+}
+
+// IsZero returns true if the outpoint is unset, as it is in a genesis contract.
+func (o Outpoint) IsZero() bool {
+	return o.Vout == 0 && (len(o.TxID) == 0 || bytes.Equal(o.TxID, make([]byte, 32)))
+}
+
+func (o Outpoint) bytes() ([]byte, error) {
+	b := make([]byte, outpointLen)
+	if len(o.TxID) > 0 {
+		if len(o.TxID) != 32 {
+			return nil, fmt.Errorf("%w: %d bytes", ErrInvalidOutpoint, len(o.TxID))
+		}
+		copy(b, bt.ReverseBytes(o.TxID))
+	}
+	binary.LittleEndian.PutUint32(b[32:], o.Vout)
+
+	return b, nil
+}
+
+func newOutpoint(b []byte) Outpoint {
+	o := Outpoint{Vout: binary.LittleEndian.Uint32(b[32:])}
+	if !bytes.Equal(b[:32], make([]byte, 32)) {
+		o.TxID = bt.ReverseBytes(b[:32])
+	}
+	return o
+}
+
+// FT is the data part of a fungible token contract.
+type FT struct {
+	Name    string
+	Symbol  string
+	Decimal uint8
+
+	// Owner is the hash160 of the public key of the owner.
+	Owner  []byte
+	Amount uint64
+
+	GenesisHash []byte
+
+	// SensibleID is the genesis outpoint of the token, which is zero in the
+	// genesis contract itself.
+	SensibleID Outpoint
+}
+
+// IsGenesis returns true if this is the genesis contract of the token.
+func (f *FT) IsGenesis() bool {
+	return f.SensibleID.IsZero()
+}
+
+// NFT is the data part of a non-fungible token contract.
+type NFT struct {
+	// MetaIDOutpoint is the MetaID node describing the token, if any.
+	MetaIDOutpoint Outpoint
+
+	// Genesis is true if this is the genesis contract, from which the tokens of
+	// the collection are minted.
+	Genesis bool
+
+	// Owner is the hash160 of the public key of the owner.
+	Owner       []byte
+	TotalSupply uint64
+	TokenIndex  uint64
+
+	GenesisHash []byte
+	SensibleID  Outpoint
+}
+
+// Contract is a token contract locking script, split into its code part and its
+// decoded data part. Exactly one of FT and NFT is set.
+type Contract struct {
+	// Code is the code part of the script, including the final OP_RETURN.
+	Code         []byte
+	ProtoVersion uint32
+
+	FT  *FT
+	NFT *NFT
+}
+
+// IsFT returns true if the script is a fungible token contract.
+func IsFT(s *bscript.Script) bool {
+	c, err := NewContractFromScript(s)
+	return err == nil && c.FT != nil
+}
+
+// IsNFT returns true if the script is a non-fungible token contract.
+func IsNFT(s *bscript.Script) bool {
+	c, err := NewContractFromScript(s)
+	return err == nil && c.NFT != nil
+}
+
+// NewContractFromScript splits a token contract locking script into its code part
+// and data part, decoding the data part, returning ErrNotToken if the script is not
+// a recognised token contract.
+func NewContractFromScript(s *bscript.Script) (*Contract, error) {
+	cc, ok := tokenPattern.Match(s)
+	if !ok {
+		return nil, ErrNotToken
+	}
+	push, _ := cc.Get("data")
+	data := push.Data()
+	if len(data) < headerLen || string(data[len(data)-len(ProtoFlag):]) != ProtoFlag {
+		return nil, ErrNotToken
+	}
+
+	// The data push is the last element of the script, so the code is everything
+	// before its push opcode and length.
+	var prefixLen int
+	switch op := push.Elements[0].Op; op {
+	case bscript.OpPUSHDATA1:
+		prefixLen = 2
+	case bscript.OpPUSHDATA2:
+		prefixLen = 3
+	case bscript.OpPUSHDATA4:
+		prefixLen = 5
+	default:
+		prefixLen = 1
+	}
+
+	c := &Contract{
+		Code:         append([]byte{}, (*s)[:len(*s)-len(data)-prefixLen]...),
+		ProtoVersion: binary.LittleEndian.Uint32(data[len(data)-headerLen:]),
+	}
+
+	r := &reader{b: data}
+	switch Type(binary.LittleEndian.Uint32(data[len(data)-headerLen+4:])) {
+	case TypeFT:
+		if len(data) != ftDataLen {
+			return nil, fmt.Errorf("%w: ft data part is %d bytes", ErrNotToken, len(data))
+		}
+		c.FT = &FT{
+			Name:        r.text(NameLen),
+			Symbol:      r.text(SymbolLen),
+			Decimal:     r.next(1)[0],
+			Owner:       r.next(20),
+			Amount:      r.uint64(),
+			GenesisHash: r.next(20),
+			SensibleID:  newOutpoint(r.next(outpointLen)),
+		}
+	case TypeNFT:
+		if len(data) != nftDataLen {
+			return nil, fmt.Errorf("%w: nft data part is %d bytes", ErrNotToken, len(data))
+		}
+		c.NFT = &NFT{
+			MetaIDOutpoint: newOutpoint(r.next(outpointLen)),
+			Genesis:        r.next(1)[0] == 1,
+			Owner:          r.next(20),
+			TotalSupply:    r.uint64(),
+			TokenIndex:     r.uint64(),
+			GenesisHash:    r.next(20),
+			SensibleID:     newOutpoint(r.next(outpointLen)),
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported proto type", ErrNotToken)
+	}
+
+	return c, nil
+}
+
+// Type returns the type of the contract.
+func (c *Contract) Type() Type {
+	if c.NFT != nil {
+		return TypeNFT
+	}
+	return TypeFT
+}
+
+// CodeHash returns the hash160 of the code part, which identifies the contract.
+func (c *Contract) CodeHash() []byte {
+	return crypto.Hash160(c.Code)
+}
+
+// GenesisHash returns the genesis hash of the token.
+func (c *Contract) GenesisHash() []byte {
+	if c.NFT != nil {
+		return c.NFT.GenesisHash
+	}
+	return c.FT.GenesisHash
+}
+
+// SensibleID returns the genesis outpoint of the token.
+func (c *Contract) SensibleID() Outpoint {
+	if c.NFT != nil {
+		return c.NFT.SensibleID
+	}
+	return c.FT.SensibleID
+}
+
+// Owner returns the public key hash of the owner of the token.
+func (c *Contract) Owner() []byte {
+	if c.NFT != nil {
+		return c.NFT.Owner
+	}
+	return c.FT.Owner
+}
+
+// OwnerAddress returns the P2PKH address of the owner of the token on the network.
+func (c *Contract) OwnerAddress(net *chaincfg.Params) (*bscript.Address, error) {
+	return bscript.NewAddressFromPublicKeyHash(c.Owner(), net)
+}
+
+// LockingScript returns the locking script of the contract, being its code part
+// followed by a push of its encoded data part.
+func (c *Contract) LockingScript() (*bscript.Script, error) {
+	w := &bytes.Buffer{}
+	switch {
+	case c.FT != nil:
+		f := c.FT
+		if err := writeText(w, f.Name, NameLen); err != nil {
+			return nil, err
+		}
+		if err := writeText(w, f.Symbol, SymbolLen); err != nil {
+			return nil, err
+		}
+		w.WriteByte(f.Decimal)
+		if err := writeHash(w, f.Owner, ErrInvalidOwner); err != nil {
+			return nil, err
+		}
+		writeUint64(w, f.Amount)
+		if err := writeGenesisHash(w, f.GenesisHash); err != nil {
+			return nil, err
+		}
+		if err := writeOutpoint(w, f.SensibleID); err != nil {
+			return nil, err
+		}
+	case c.NFT != nil:
+		n := c.NFT
+		if err := writeOutpoint(w, n.MetaIDOutpoint); err != nil {
+			return nil, err
+		}
+		if n.Genesis {
+			w.WriteByte(1)
+		} else {
+			w.WriteByte(0)
+		}
+		if err := writeHash(w, n.Owner, ErrInvalidOwner); err != nil {
+			return nil, err
+		}
+		writeUint64(w, n.TotalSupply)
+		writeUint64(w, n.TokenIndex)
+		if err := writeGenesisHash(w, n.GenesisHash); err != nil {
+			return nil, err
+		}
+		if err := writeOutpoint(w, n.SensibleID); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrNotToken
+	}
+
+	header := make([]byte, 8)
+	binary.LittleEndian.PutUint32(header, c.ProtoVersion)
+	binary.LittleEndian.PutUint32(header[4:], uint32(c.Type()))
+	w.Write(header)
+	w.WriteString(ProtoFlag)
+
+	s := bscript.NewFromBytes(append([]byte{}, c.Code...))
+	if err := s.AppendPushData(w.Bytes()); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// NewFTTransferScript builds the locking script which transfers an amount of a
+// fungible token to the owner, from the contract being spent.
+func NewFTTransferScript(template *bscript.Script, owner []byte, amount uint64) (*bscript.Script, error) {
+	c, err := newContractOfType(template, TypeFT)
+	if err != nil {
+		return nil, err
+	}
+	if c.FT.IsGenesis() {
+		return nil, ErrIsGenesis
+	}
+	c.FT.Owner, c.FT.Amount = owner, amount
+
+	return c.LockingScript()
+}
+
+// NewFTMintScript builds the locking script which issues an amount of a fungible
+// token to the owner, from its genesis contract and the outpoint of the genesis
+// output. The genesis hash of the token is the hash160 of the genesis contract.
+func NewFTMintScript(genesis *bscript.Script, genesisOutpoint Outpoint, owner []byte,
+	amount uint64) (*bscript.Script, error) {
+	c, err := newContractOfType(genesis, TypeFT)
+	if err != nil {
+		return nil, err
+	}
+	if !c.FT.IsGenesis() {
+		return nil, ErrNotGenesis
+	}
+	c.FT.Owner, c.FT.Amount = owner, amount
+	c.FT.GenesisHash = crypto.Hash160(*genesis)
+	c.FT.SensibleID = genesisOutpoint
+
+	return c.LockingScript()
+}
+
+// NewNFTTransferScript builds the locking script which transfers a non-fungible
+// token to the owner, from the contract being spent.
+func NewNFTTransferScript(template *bscript.Script, owner []byte) (*bscript.Script, error) {
+	c, err := newContractOfType(template, TypeNFT)
+	if err != nil {
+		return nil, err
+	}
+	if c.NFT.Genesis {
+		return nil, ErrIsGenesis
+	}
+	c.NFT.Owner = owner
+
+	return c.LockingScript()
+}
+
+// NewNFTMintScript builds the locking script which mints the token of a collection
+// with the index to the owner, from its genesis contract and the outpoint of the
+// genesis output. The genesis hash of the token is the hash160 of the genesis contract.
+func NewNFTMintScript(genesis *bscript.Script, genesisOutpoint Outpoint, owner []byte,
+	tokenIndex uint64) (*bscript.Script, error) {
+	c, err := newContractOfType(genesis, TypeNFT)
+	if err != nil {
+		return nil, err
+	}
+	if !c.NFT.Genesis {
+		return nil, ErrNotGenesis
+	}
+	if tokenIndex >= c.NFT.TotalSupply {
+		return nil, fmt.Errorf("%w: %d of %d", ErrTokenIndex, tokenIndex, c.NFT.TotalSupply)
+	}
+	c.NFT.Genesis = false
+	c.NFT.Owner, c.NFT.TokenIndex = owner, tokenIndex
+	c.NFT.GenesisHash = crypto.Hash160(*genesis)
+	c.NFT.SensibleID = genesisOutpoint
+
+	return c.LockingScript()
+}
+
+func newContractOfType(s *bscript.Script, typ Type) (*Contract, error) {
+	c, err := NewContractFromScript(s)
+	if err != nil {
+		return nil, err
+	}
+	if c.Type() != typ {
+		return nil, ErrTypeMismatch
+	}
+
+	return c, nil
+}
+
+type reader struct {
+	b []byte
+}
+
+func (r *reader) next(n int) []byte {
+	b := append([]byte{}, r.b[:n]...)
+	r.b = r.b[n:]
+	return b
+}
+
+func (r *reader) text(n int) string {
+	return string(bytes.TrimRight(r.next(n), "\x00"))
+}
+
+func (r *reader) uint64() uint64 {
+	return binary.LittleEndian.Uint64(r.next(8))
+}
+
+func writeText(w *bytes.Buffer, s string, n int) error {
+	if len(s) > n {
+		return fmt.Errorf("%w: %q is over %d bytes", ErrFieldTooLong, s, n)
+	}
+	w.WriteString(s)
+	w.Write(make([]byte, n-len(s)))
+
+	return nil
+}
+
+func writeHash(w *bytes.Buffer, b []byte, errInvalid error) error {
+	if len(b) != 20 {
+		return fmt.Errorf("%w: %d bytes", errInvalid, len(b))
+	}
+	w.Write(b)
+
+	return nil
+}
+
+// writeGenesisHash writes the genesis hash, which is left as zeros until the
+// token is issued.
+func writeGenesisHash(w *bytes.Buffer, b []byte) error {
+	if len(b) == 0 {
+		w.Write(make([]byte, 20))
+		return nil
+	}
+	return writeHash(w, b, ErrInvalidGenesisHash)
+}
+
+func writeUint64(w *bytes.Buffer, n uint64) {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, n)
+	w.Write(b)
+}
+
+func writeOutpoint(w *bytes.Buffer, o Outpoint) error {
+	b, err := o.bytes()
+	if err != nil {
+		return err
+	}
+	w.Write(b)
+
+	return nil
+}
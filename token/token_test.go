@@ -0,0 +1,233 @@
+package token_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+	"github.com/mvc-labs/mvc-lib-go/keys/chaincfg"
+	"github.com/mvc-labs/mvc-lib-go/keys/crypto"
+	"github.com/mvc-labs/mvc-lib-go/token"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testOwner1 = crypto.Hash160([]byte("owner 1"))
+	testOwner2 = crypto.Hash160([]byte("owner 2"))
+
+	testGenesisOutpoint = token.Outpoint{
+		TxID: mustDecodeHex("45be95d2f2c64e99518ffbbce03fb15a7758f20ee5eecf0df07938d977add71d"),
+		Vout: 1,
+	}
+)
+
+func mustDecodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// testCode stands in for the code part of a token contract.
+func testCode(t *testing.T) []byte {
+	s, err := bscript.ParseASM("OP_DUP OP_HASH160 OP_SWAP OP_CAT OP_CHECKSIG OP_RETURN")
+	assert.NoError(t, err)
+	return *s
+}
+
+func testFTGenesis(t *testing.T) *bscript.Script {
+	s, err := (&token.Contract{
+		Code:         testCode(t),
+		ProtoVersion: 1,
+		FT: &token.FT{
+			Name:    "Test Token",
+			Symbol:  "TT",
+			Decimal: 8,
+			Owner:   testOwner1,
+		},
+	}).LockingScript()
+	assert.NoError(t, err)
+	return s
+}
+
+func testNFTGenesis(t *testing.T) *bscript.Script {
+	s, err := (&token.Contract{
+		Code:         testCode(t),
+		ProtoVersion: 1,
+		NFT: &token.NFT{
+			Genesis:     true,
+			Owner:       testOwner1,
+			TotalSupply: 10,
+		},
+	}).LockingScript()
+	assert.NoError(t, err)
+	return s
+}
+
+func TestNewContractFromScript(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ft genesis", func(t *testing.T) {
+		s := testFTGenesis(t)
+		assert.True(t, token.IsFT(s))
+		assert.False(t, token.IsNFT(s))
+
+		c, err := token.NewContractFromScript(s)
+		assert.NoError(t, err)
+		assert.Equal(t, token.TypeFT, c.Type())
+		assert.Equal(t, testCode(t), c.Code)
+		assert.Equal(t, uint32(1), c.ProtoVersion)
+		assert.Equal(t, "Test Token", c.FT.Name)
+		assert.Equal(t, "TT", c.FT.Symbol)
+		assert.Equal(t, uint8(8), c.FT.Decimal)
+		assert.True(t, c.FT.IsGenesis())
+
+		rt, err := c.LockingScript()
+		assert.NoError(t, err)
+		assert.Equal(t, s, rt)
+	})
+
+	t.Run("nft genesis", func(t *testing.T) {
+		s := testNFTGenesis(t)
+		assert.True(t, token.IsNFT(s))
+
+		c, err := token.NewContractFromScript(s)
+		assert.NoError(t, err)
+		assert.Equal(t, token.TypeNFT, c.Type())
+		assert.True(t, c.NFT.Genesis)
+		assert.Equal(t, uint64(10), c.NFT.TotalSupply)
+		assert.Equal(t, testOwner1, c.Owner())
+	})
+
+	t.Run("owner address", func(t *testing.T) {
+		c, err := token.NewContractFromScript(testFTGenesis(t))
+		assert.NoError(t, err)
+
+		a, err := c.OwnerAddress(&chaincfg.MainNet)
+		assert.NoError(t, err)
+		assert.Equal(t, hex.EncodeToString(testOwner1), a.PublicKeyHash)
+	})
+
+	t.Run("not tokens", func(t *testing.T) {
+		for _, asm := range []string{
+			"OP_DUP OP_HASH160 e2a623699e81b291c0327f408fea765d534baa2a OP_EQUALVERIFY OP_CHECKSIG",
+			"OP_FALSE OP_RETURN 'hello'",
+			"OP_TRUE OP_RETURN 'metacontract'",
+		} {
+			s, err := bscript.ParseASM(asm)
+			assert.NoError(t, err)
+			_, err = token.NewContractFromScript(s)
+			assert.ErrorIs(t, err, token.ErrNotToken)
+		}
+	})
+}
+
+func TestFT(t *testing.T) {
+	t.Parallel()
+
+	genesis := testFTGenesis(t)
+
+	minted, err := token.NewFTMintScript(genesis, testGenesisOutpoint, testOwner1, 1000)
+	assert.NoError(t, err)
+	c, err := token.NewContractFromScript(minted)
+	assert.NoError(t, err)
+	assert.False(t, c.FT.IsGenesis())
+	assert.Equal(t, uint64(1000), c.FT.Amount)
+	assert.Equal(t, crypto.Hash160(*genesis), c.FT.GenesisHash)
+	assert.Equal(t, testGenesisOutpoint, c.FT.SensibleID)
+	assert.Equal(t, testCode(t), c.Code)
+
+	transfer, err := token.NewFTTransferScript(minted, testOwner2, 400)
+	assert.NoError(t, err)
+	tc, err := token.NewContractFromScript(transfer)
+	assert.NoError(t, err)
+	assert.Equal(t, testOwner2, tc.FT.Owner)
+	assert.Equal(t, uint64(400), tc.FT.Amount)
+	assert.Equal(t, c.CodeHash(), tc.CodeHash())
+	assert.Equal(t, c.GenesisHash(), tc.GenesisHash())
+
+	_, err = token.NewFTTransferScript(genesis, testOwner2, 1)
+	assert.ErrorIs(t, err, token.ErrIsGenesis)
+	_, err = token.NewFTMintScript(minted, testGenesisOutpoint, testOwner2, 1)
+	assert.ErrorIs(t, err, token.ErrNotGenesis)
+	_, err = token.NewFTTransferScript(minted, testOwner2[1:], 1)
+	assert.ErrorIs(t, err, token.ErrInvalidOwner)
+	_, err = token.NewFTTransferScript(testNFTGenesis(t), testOwner2, 1)
+	assert.ErrorIs(t, err, token.ErrTypeMismatch)
+
+	_, err = (&token.Contract{Code: testCode(t), FT: &token.FT{
+		Name:  "a token name which is far too long to fit in forty bytes",
+		Owner: testOwner1,
+	}}).LockingScript()
+	assert.ErrorIs(t, err, token.ErrFieldTooLong)
+}
+
+func TestNFT(t *testing.T) {
+	t.Parallel()
+
+	genesis := testNFTGenesis(t)
+
+	minted, err := token.NewNFTMintScript(genesis, testGenesisOutpoint, testOwner1, 3)
+	assert.NoError(t, err)
+	c, err := token.NewContractFromScript(minted)
+	assert.NoError(t, err)
+	assert.False(t, c.NFT.Genesis)
+	assert.Equal(t, uint64(3), c.NFT.TokenIndex)
+	assert.Equal(t, testGenesisOutpoint, c.SensibleID())
+
+	transfer, err := token.NewNFTTransferScript(minted, testOwner2)
+	assert.NoError(t, err)
+	tc, err := token.NewContractFromScript(transfer)
+	assert.NoError(t, err)
+	assert.Equal(t, testOwner2, tc.NFT.Owner)
+	assert.Equal(t, uint64(3), tc.NFT.TokenIndex)
+
+	_, err = token.NewNFTMintScript(genesis, testGenesisOutpoint, testOwner1, 10)
+	assert.ErrorIs(t, err, token.ErrTokenIndex)
+	_, err = token.NewNFTTransferScript(genesis, testOwner2)
+	assert.ErrorIs(t, err, token.ErrIsGenesis)
+	_, err = token.NewNFTMintScript(minted, testGenesisOutpoint, testOwner1, 4)
+	assert.ErrorIs(t, err, token.ErrNotGenesis)
+}
+
+func TestBalances(t *testing.T) {
+	t.Parallel()
+
+	ftGenesis := testFTGenesis(t)
+	nftGenesis := testNFTGenesis(t)
+
+	ft1, err := token.NewFTMintScript(ftGenesis, testGenesisOutpoint, testOwner1, 1000)
+	assert.NoError(t, err)
+	ft2, err := token.NewFTTransferScript(ft1, testOwner1, 250)
+	assert.NoError(t, err)
+	ft3, err := token.NewFTTransferScript(ft1, testOwner2, 50)
+	assert.NoError(t, err)
+	nft1, err := token.NewNFTMintScript(nftGenesis, testGenesisOutpoint, testOwner1, 0)
+	assert.NoError(t, err)
+	nft2, err := token.NewNFTMintScript(nftGenesis, testGenesisOutpoint, testOwner1, 1)
+	assert.NoError(t, err)
+	p2pkh, err := bscript.NewP2PKHFromPubKeyHash(testOwner1)
+	assert.NoError(t, err)
+
+	utxos := bt.UTXOs{}
+	for _, s := range []*bscript.Script{ft1, nft1, p2pkh, ft2, ftGenesis, ft3, nft2} {
+		utxos = append(utxos, &bt.UTXO{LockingScript: s, Satoshis: 1})
+	}
+
+	bb := token.Balances(utxos, testOwner1)
+	assert.Len(t, bb, 2)
+
+	assert.Equal(t, token.TypeFT, bb[0].Type)
+	assert.Equal(t, "TT", bb[0].Symbol)
+	assert.Equal(t, uint64(1250), bb[0].Amount)
+	assert.Len(t, bb[0].UTXOs, 2)
+
+	assert.Equal(t, token.TypeNFT, bb[1].Type)
+	assert.Equal(t, uint64(2), bb[1].Amount)
+	assert.Equal(t, crypto.Hash160(*nftGenesis), bb[1].GenesisHash)
+
+	all := token.Balances(utxos, nil)
+	assert.Equal(t, uint64(1300), all[0].Amount)
+}
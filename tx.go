@@ -2,6 +2,7 @@ package bt
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"encoding/hex"
 	"io"
@@ -283,64 +284,31 @@ func (tx *Tx) IsCoinbase() bool {
 // TxIDBytes returns the transaction ID of the transaction as bytes
 // (which is also the transaction hash).
 func (tx *Tx) TxIDBytes() []byte {
+	if tx.Version >= 10 {
+		return tx.MvcTxIDBytes()
+	}
 	return ReverseBytes(crypto.Sha256d(tx.Bytes()))
 }
 
 // TxID returns the transaction ID of the transaction
 // (which is also the transaction hash).
 func (tx *Tx) TxID() string {
-	if tx.Version >= 10 {
-		return tx.MvcTxID()
-	}
-	return hex.EncodeToString(ReverseBytes(crypto.Sha256d(tx.Bytes())))
+	return hex.EncodeToString(tx.TxIDBytes())
 }
 
+// MvcTxID returns the MVC transaction ID of the transaction, as used
+// for txs of version 10 and above.
 func (tx *Tx) MvcTxID() string {
-	buf := make([]byte, 0)
-	inputsBuf := make([]byte, 0)
-	inputs2Buf := make([]byte, 0)
-	outputsBuf := make([]byte, 0)
-
-	v := make([]byte, 4)
-	binary.LittleEndian.PutUint32(v, tx.Version)
-	buf = append(buf, v...)
-
-	lt := make([]byte, 4)
-	binary.LittleEndian.PutUint32(lt, tx.LockTime)
-	buf = append(buf, lt...)
-
-	inS := make([]byte, 4)
-	binary.LittleEndian.PutUint32(inS, uint32(len(tx.Inputs)))
-	buf = append(buf, inS...)
-
-	outS := make([]byte, 4)
-	binary.LittleEndian.PutUint32(outS, uint32(len(tx.Outputs)))
-	buf = append(buf, outS...)
-
-	for _,in := range tx.Inputs {
-		inputsBuf = append(inputsBuf, ReverseBytes(in.PreviousTxID())...)
-		oi := make([]byte, 4)
-		binary.LittleEndian.PutUint32(oi, in.PreviousTxOutIndex)
-		inputsBuf = append(inputsBuf, oi...)
-
-		sq := make([]byte, 4)
-		binary.LittleEndian.PutUint32(sq, in.SequenceNumber)
-		inputsBuf = append(inputsBuf, sq...)
-
-		inputs2Buf = append(inputs2Buf, crypto.Sha256(*in.UnlockingScript)...)
-	}
-	buf = append(buf, crypto.Sha256(inputsBuf)...)
-	buf = append(buf, crypto.Sha256(inputs2Buf)...)
-
-	for _,out := range tx.Outputs {
-		st := make([]byte, 8)
-		binary.LittleEndian.PutUint64(st, out.Satoshis)
-		outputsBuf = append(outputsBuf, st...)
+	return hex.EncodeToString(tx.MvcTxIDBytes())
+}
 
-		outputsBuf = append(outputsBuf, crypto.Sha256(*out.LockingScript)...)
-	}
-	buf = append(buf, crypto.Sha256(outputsBuf)...)
-	return hex.EncodeToString(ReverseBytes(crypto.Sha256d(buf)))
+// MvcTxIDBytes returns the MVC transaction ID of the transaction as bytes.
+//
+// Rather than hashing the serialised tx, the inputs, unlocking scripts and outputs
+// are each hashed separately, and these digests are hashed along with the version,
+// locktime and input/output counts. See MvcTxIDComponents.
+func (tx *Tx) MvcTxIDBytes() []byte {
+	return tx.MvcTxIDComponents().TxID()
 }
 
 // String encodes the transaction into a hex string.
@@ -496,11 +464,15 @@ func (tx *Tx) SizeWithTypes() *TxSize {
 	}
 }
 
-// EstimateSize will return the size of tx in bytes and will add 107 bytes
-// to the unlocking script of any unsigned inputs (only P2PKH for now) found
-// to give a final size estimate of the tx size.
-func (tx *Tx) EstimateSize() (int, error) {
-	tempTx, err := tx.estimatedFinalTx()
+// EstimateSize will return the size of tx in bytes and will add the expected unlocking
+// script length to any unsigned inputs found to give a final size estimate of the tx size.
+//
+// The unlocking script length of each unsigned input is estimated by the first of the
+// provided bt.UnlockerGetters to return a bt.Unlocker implementing bt.UnlockerEstimator.
+// If none do, 107 bytes (sig + pubkey) are added for P2PKH inputs, with any other input
+// resulting in a bt.ErrUnsupportedScript.
+func (tx *Tx) EstimateSize(ugs ...UnlockerGetter) (int, error) {
+	tempTx, err := tx.estimatedFinalTx(context.Background(), ugs)
 	if err != nil {
 		return 0, err
 	}
@@ -509,11 +481,12 @@ func (tx *Tx) EstimateSize() (int, error) {
 }
 
 // EstimateSizeWithTypes will return the size of tx in bytes, including the
-// different data types (std/data/etc.), and will add 107 bytes to the unlocking
-// script of any unsigned inputs (only P2PKH for now) found to give a final size
-// estimate of the tx size.
-func (tx *Tx) EstimateSizeWithTypes() (*TxSize, error) {
-	tempTx, err := tx.estimatedFinalTx()
+// different data types (std/data/etc.), and will add the expected unlocking script
+// length to any unsigned inputs found to give a final size estimate of the tx size.
+//
+// See tx.EstimateSize(...) for how the unlocking script lengths are estimated.
+func (tx *Tx) EstimateSizeWithTypes(ugs ...UnlockerGetter) (*TxSize, error) {
+	tempTx, err := tx.estimatedFinalTx(context.Background(), ugs)
 	if err != nil {
 		return nil, err
 	}
@@ -521,22 +494,58 @@ func (tx *Tx) EstimateSizeWithTypes() (*TxSize, error) {
 	return tempTx.SizeWithTypes(), nil
 }
 
-func (tx *Tx) estimatedFinalTx() (*Tx, error) {
+// p2pkhEstimatedUnlockingScriptLength is the length in bytes of a P2PKH unlocking
+// script, being a pushed 72 byte sig and 33 byte pubkey.
+const p2pkhEstimatedUnlockingScriptLength = 1 + 72 + 1 + 33
+
+func (tx *Tx) estimatedFinalTx(ctx context.Context, ugs []UnlockerGetter) (*Tx, error) {
 	tempTx := tx.Clone()
 
 	for _, in := range tempTx.Inputs {
-		if !in.PreviousTxScript.IsP2PKH() {
-			return nil, ErrUnsupportedScript
+		if in.UnlockingScript != nil && len(*in.UnlockingScript) > 0 {
+			continue
 		}
-		if in.UnlockingScript == nil || len(*in.UnlockingScript) == 0 {
-			//nolint:lll // insert dummy p2pkh unlocking script (sig + pubkey)
-			dummyUnlockingScript, _ := hex.DecodeString("4830450221009c13cbcbb16f2cfedc7abf3a4af1c3fe77df1180c0e7eee30d9bcc53ebda39da02207b258005f1bc3cf9dffa06edb358d6db2bcfc87f50516fac8e3f4686fc2a03df412103107feff22788a1fc8357240bf450fd7bca4bd45d5f8bac63818c5a7b67b03876")
-			in.UnlockingScript = bscript.NewFromBytes(dummyUnlockingScript)
+
+		l, err := estimateUnlockingScriptLength(ctx, in.PreviousTxScript, ugs)
+		if err != nil {
+			return nil, err
 		}
+		// insert a dummy unlocking script of the expected length
+		in.UnlockingScript = bscript.NewFromBytes(make([]byte, l))
 	}
 	return tempTx, nil
 }
 
+// estimateUnlockingScriptLength returns the expected length of the unlocking script for
+// the locking script, using the first of the bt.UnlockerGetters able to estimate it.
+// Getters which error, or whose unlockers cannot estimate, are skipped, falling back
+// to the length of a P2PKH unlocking script.
+func estimateUnlockingScriptLength(ctx context.Context, lockingScript *bscript.Script,
+	ugs []UnlockerGetter) (int, error) {
+	if lockingScript == nil {
+		return 0, ErrUnsupportedScript
+	}
+
+	for _, ug := range ugs {
+		u, err := ug.Unlocker(ctx, lockingScript)
+		if err != nil {
+			continue
+		}
+		e, ok := u.(UnlockerEstimator)
+		if !ok {
+			continue
+		}
+		if l, err := e.EstimateUnlockingScriptLength(ctx, lockingScript); err == nil {
+			return l, nil
+		}
+	}
+
+	if !lockingScript.IsP2PKH() {
+		return 0, ErrUnsupportedScript
+	}
+	return p2pkhEstimatedUnlockingScriptLength, nil
+}
+
 // TxFees is returned when CalculateFee is called and contains
 // a breakdown of the fees including the total and the size breakdown of
 // the tx in bytes.
@@ -568,11 +577,13 @@ func (tx *Tx) IsFeePaidEnough(fees *FeeQuote) (bool, error) {
 }
 
 // EstimateIsFeePaidEnough will calculate the fees that this transaction is paying
-// including the individual fee types (std/data/etc.), and will add 107 bytes to the unlocking
-// script of any unsigned inputs (only P2PKH for now) found to give a final size
-// estimate of the tx size for fee calculation.
-func (tx *Tx) EstimateIsFeePaidEnough(fees *FeeQuote) (bool, error) {
-	tempTx, err := tx.estimatedFinalTx()
+// including the individual fee types (std/data/etc.), and will add the expected unlocking
+// script length to any unsigned inputs found to give a final size estimate of the tx
+// size for fee calculation.
+//
+// See tx.EstimateSize(...) for how the unlocking script lengths are estimated.
+func (tx *Tx) EstimateIsFeePaidEnough(fees *FeeQuote, ugs ...UnlockerGetter) (bool, error) {
+	tempTx, err := tx.estimatedFinalTx(context.Background(), ugs)
 	if err != nil {
 		return false, err
 	}
@@ -594,8 +605,10 @@ func (tx *Tx) EstimateIsFeePaidEnough(fees *FeeQuote) (bool, error) {
 // EstimateFeesPaid will estimate how big the tx will be when finalised
 // by estimating input unlocking scripts that have not yet been filled
 // including the individual fee types (std/data/etc.).
-func (tx *Tx) EstimateFeesPaid(fees *FeeQuote) (*TxFees, error) {
-	size, err := tx.EstimateSizeWithTypes()
+//
+// See tx.EstimateSize(...) for how the unlocking script lengths are estimated.
+func (tx *Tx) EstimateFeesPaid(fees *FeeQuote, ugs ...UnlockerGetter) (*TxFees, error) {
+	size, err := tx.EstimateSizeWithTypes(ugs...)
 	if err != nil {
 		return nil, err
 	}
@@ -622,11 +635,15 @@ func (tx *Tx) feesPaid(size *TxSize, fees *FeeQuote) (*TxFees, error) {
 
 }
 
-func (tx *Tx) estimateDeficit(fees *FeeQuote) (uint64, error) {
+func (tx *Tx) estimateDeficit(ctx context.Context, fees *FeeQuote, ugs []UnlockerGetter) (uint64, error) {
 	totalInputSatoshis := tx.TotalInputSatoshis()
 	totalOutputSatoshis := tx.TotalOutputSatoshis()
 
-	expFeesPaid, err := tx.EstimateFeesPaid(fees)
+	tempTx, err := tx.estimatedFinalTx(ctx, ugs)
+	if err != nil {
+		return 0, err
+	}
+	expFeesPaid, err := tx.feesPaid(tempTx.SizeWithTypes(), fees)
 	if err != nil {
 		return 0, err
 	}
@@ -0,0 +1,220 @@
+package bt_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+	"github.com/mvc-labs/mvc-lib-go/unlocker"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testP2PKHAddress = "1NRoySJ9Lvby6DuE2UQYnyT67AASwNZxGb"
+	// 1 of 1 bare multisig
+	testMultiSigScript = "51210279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f8179851ae"
+)
+
+// estimatingUnlocker estimates a fixed unlocking script length, failing to unlock anything.
+type estimatingUnlocker struct {
+	length int
+}
+
+func (e *estimatingUnlocker) UnlockingScript(context.Context, *bt.Tx, bt.UnlockerParams) (*bscript.Script, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (e *estimatingUnlocker) EstimateUnlockingScriptLength(context.Context, *bscript.Script) (int, error) {
+	return e.length, nil
+}
+
+type estimatingGetter struct {
+	length int
+}
+
+func (g *estimatingGetter) Unlocker(context.Context, *bscript.Script) (bt.Unlocker, error) {
+	return &estimatingUnlocker{length: g.length}, nil
+}
+
+// failingGetter fails to provide an unlocker for any locking script.
+type failingGetter struct{}
+
+func (g *failingGetter) Unlocker(context.Context, *bscript.Script) (bt.Unlocker, error) {
+	return nil, errors.New("no key for script")
+}
+
+func multiSigTx(t *testing.T, sats uint64) *bt.Tx {
+	tx := bt.NewTx()
+	assert.NoError(t, tx.From(
+		"45be95d2f2c64e99518ffbbce03fb15a7758f20ee5eecf0df07938d977add71d", 0, testMultiSigScript, sats,
+	))
+	assert.NoError(t, tx.PayToAddress(testP2PKHAddress, 1000))
+	return tx
+}
+
+func TestTx_PayToAddress(t *testing.T) {
+	t.Parallel()
+
+	tx := bt.NewTx()
+	assert.NoError(t, tx.PayToAddress(testP2PKHAddress, 1000))
+	assert.NoError(t, tx.PayToAddress("3QJmV3qfvL9SuYo34YihAf3sRCW3qSinyC", 2000))
+	assert.Error(t, tx.PayToAddress("3QJmV3qfvL9SuYo34YihAf3sRCW3qSinyD", 3000))
+
+	assert.Equal(t, 2, tx.OutputCount())
+	assert.True(t, tx.Outputs[0].LockingScript.IsP2PKH())
+	assert.True(t, tx.Outputs[1].LockingScript.IsP2SH())
+	assert.Equal(t, "a914f815b036d9bbbce5e9f2a00abd1bf3dc91e9551087", tx.Outputs[1].LockingScriptHexString())
+
+	// AddP2PKHOutputFromAddress only pays to P2PKH addresses.
+	assert.ErrorIs(t, tx.AddP2PKHOutputFromAddress("3QJmV3qfvL9SuYo34YihAf3sRCW3qSinyC", 2000), bscript.ErrNotP2PKH)
+}
+
+func TestTx_EstimateSize(t *testing.T) {
+	t.Parallel()
+
+	t.Run("p2pkh fallback", func(t *testing.T) {
+		tx := bt.NewTx()
+		assert.NoError(t, tx.From(
+			"45be95d2f2c64e99518ffbbce03fb15a7758f20ee5eecf0df07938d977add71d",
+			0,
+			"76a914eb0bd5edba389198e73f8efabddfc61666969ff788ac",
+			2000,
+		))
+		assert.NoError(t, tx.PayToAddress(testP2PKHAddress, 1000))
+
+		size, err := tx.EstimateSize()
+		assert.NoError(t, err)
+		assert.Equal(t, tx.Size()+107, size)
+
+		// unlocker.Simple estimates the same as the fallback.
+		withGetter, err := tx.EstimateSize(&unlocker.Getter{})
+		assert.NoError(t, err)
+		assert.Equal(t, size, withGetter)
+	})
+
+	t.Run("non p2pkh input without estimator", func(t *testing.T) {
+		_, err := multiSigTx(t, 2000).EstimateSize()
+		assert.True(t, errors.Is(err, bt.ErrUnsupportedScript))
+	})
+
+	t.Run("non p2pkh input with estimator", func(t *testing.T) {
+		tx := multiSigTx(t, 2000)
+
+		size, err := tx.EstimateSize(&estimatingGetter{length: 74})
+		assert.NoError(t, err)
+		assert.Equal(t, tx.Size()+74, size)
+
+		sizes, err := tx.EstimateSizeWithTypes(&estimatingGetter{length: 74})
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(size), sizes.TotalBytes)
+	})
+
+	t.Run("getters which fail are skipped", func(t *testing.T) {
+		tx := multiSigTx(t, 2000)
+
+		size, err := tx.EstimateSize(&failingGetter{}, &estimatingGetter{length: 74})
+		assert.NoError(t, err)
+		assert.Equal(t, tx.Size()+74, size)
+
+		_, err = tx.EstimateSize(&failingGetter{})
+		assert.True(t, errors.Is(err, bt.ErrUnsupportedScript))
+
+		p2pkh := bt.NewTx()
+		assert.NoError(t, p2pkh.From(
+			"45be95d2f2c64e99518ffbbce03fb15a7758f20ee5eecf0df07938d977add71d",
+			0,
+			"76a914eb0bd5edba389198e73f8efabddfc61666969ff788ac",
+			2000,
+		))
+		size, err = p2pkh.EstimateSize(&failingGetter{})
+		assert.NoError(t, err)
+		assert.Equal(t, p2pkh.Size()+107, size)
+	})
+
+	t.Run("input without previous tx script", func(t *testing.T) {
+		tx := bt.NewTx()
+		assert.NoError(t, tx.From(
+			"45be95d2f2c64e99518ffbbce03fb15a7758f20ee5eecf0df07938d977add71d",
+			0,
+			"76a914eb0bd5edba389198e73f8efabddfc61666969ff788ac",
+			2000,
+		))
+		tx.Inputs[0].PreviousTxScript = nil
+
+		_, err := tx.EstimateSize(&unlocker.Getter{}, &estimatingGetter{length: 74})
+		assert.True(t, errors.Is(err, bt.ErrUnsupportedScript))
+	})
+
+	t.Run("signed inputs are not estimated", func(t *testing.T) {
+		tx := multiSigTx(t, 2000)
+		tx.Inputs[0].UnlockingScript = bscript.NewFromBytes([]byte{0x00, 0x01, 0x02})
+
+		size, err := tx.EstimateSize()
+		assert.NoError(t, err)
+		assert.Equal(t, tx.Size(), size)
+	})
+}
+
+func TestTx_EstimateFeesPaid(t *testing.T) {
+	t.Parallel()
+
+	tx := multiSigTx(t, 2000)
+
+	small, err := tx.EstimateFeesPaid(bt.NewFeeQuote(), &estimatingGetter{length: 74})
+	assert.NoError(t, err)
+	large, err := tx.EstimateFeesPaid(bt.NewFeeQuote(), &estimatingGetter{length: 1074})
+	assert.NoError(t, err)
+
+	// 1000 extra bytes at the default 0.5 sat/byte, plus 2 bytes for the larger varint.
+	assert.Equal(t, small.TotalFeePaid+501, large.TotalFeePaid)
+}
+
+func TestTx_Change_Estimator(t *testing.T) {
+	t.Parallel()
+
+	tx := multiSigTx(t, 2000)
+	assert.True(t, errors.Is(tx.ChangeToAddress(testP2PKHAddress, bt.NewFeeQuote()), bt.ErrUnsupportedScript))
+
+	assert.NoError(t, tx.ChangeToAddress(testP2PKHAddress, bt.NewFeeQuote(), &estimatingGetter{length: 74}))
+	assert.Equal(t, 2, tx.OutputCount())
+
+	// Once signed with an unlocking script of the estimated length, the fee is exactly covered.
+	tx.Inputs[0].UnlockingScript = bscript.NewFromBytes(make([]byte, 74))
+	ok, err := tx.IsFeePaidEnough(bt.NewFeeQuote())
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	fees, err := tx.EstimateFeesPaid(bt.NewFeeQuote())
+	assert.NoError(t, err)
+	assert.Equal(t, fees.TotalFeePaid, tx.TotalInputSatoshis()-tx.TotalOutputSatoshis())
+}
+
+func TestTx_Fund_Estimator(t *testing.T) {
+	t.Parallel()
+
+	utxos := func(ctx context.Context, deficit uint64) ([]*bt.UTXO, error) {
+		s, err := bscript.NewFromHexString(testMultiSigScript)
+		if err != nil {
+			return nil, err
+		}
+		return []*bt.UTXO{{
+			TxID:          make([]byte, 32),
+			LockingScript: s,
+			Satoshis:      deficit + 1000,
+		}}, nil
+	}
+
+	tx := bt.NewTx()
+	assert.NoError(t, tx.PayToAddress(testP2PKHAddress, 1000))
+
+	err := tx.Fund(context.Background(), bt.NewFeeQuote(), utxos)
+	assert.True(t, errors.Is(err, bt.ErrUnsupportedScript))
+
+	assert.NoError(t, tx.Fund(context.Background(), bt.NewFeeQuote(), utxos, &estimatingGetter{length: 74}))
+
+	ok, err := tx.EstimateIsFeePaidEnough(bt.NewFeeQuote(), &estimatingGetter{length: 74})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
@@ -11,22 +11,25 @@ const (
 
 // ChangeToAddress calculates the amount of fees needed to cover the transaction
 // and adds the leftover change in a new P2PKH output using the address provided.
-func (tx *Tx) ChangeToAddress(addr string, f *FeeQuote) error {
+func (tx *Tx) ChangeToAddress(addr string, f *FeeQuote, ugs ...UnlockerGetter) error {
 	s, err := bscript.NewP2PKHFromAddress(addr)
 	if err != nil {
 		return err
 	}
 
-	return tx.Change(s, f)
+	return tx.Change(s, f, ugs...)
 }
 
 // Change calculates the amount of fees needed to cover the transaction
 //  and adds the leftover change in a new output using the script provided.
-func (tx *Tx) Change(s *bscript.Script, f *FeeQuote) error {
+//
+// The fees of any unsigned inputs are estimated using the provided bt.UnlockerGetters,
+// as described in tx.EstimateSize(...).
+func (tx *Tx) Change(s *bscript.Script, f *FeeQuote, ugs ...UnlockerGetter) error {
 	if _, _, err := tx.change(f, &changeOutput{
 		lockingScript: s,
 		newOutput:     true,
-	}); err != nil {
+	}, ugs); err != nil {
 		return err
 	}
 	return nil
@@ -34,11 +37,11 @@ func (tx *Tx) Change(s *bscript.Script, f *FeeQuote) error {
 
 // ChangeToExistingOutput will calculate fees and add them to an output at the index specified (0 based).
 // If an invalid index is supplied and error is returned.
-func (tx *Tx) ChangeToExistingOutput(index uint, f *FeeQuote) error {
+func (tx *Tx) ChangeToExistingOutput(index uint, f *FeeQuote, ugs ...UnlockerGetter) error {
 	if int(index) > tx.OutputCount()-1 {
 		return ErrOutputNoExist
 	}
-	available, hasChange, err := tx.change(f, nil)
+	available, hasChange, err := tx.change(f, nil, ugs)
 	if err != nil {
 		return err
 	}
@@ -55,7 +58,7 @@ type changeOutput struct {
 
 // change will return the amount of satoshis to add to an input after fees are removed.
 // True will be returned if change is required for this tx.
-func (tx *Tx) change(f *FeeQuote, output *changeOutput) (uint64, bool, error) {
+func (tx *Tx) change(f *FeeQuote, output *changeOutput, ugs []UnlockerGetter) (uint64, bool, error) {
 	inputAmount := tx.TotalInputSatoshis()
 	outputAmount := tx.TotalOutputSatoshis()
 	if inputAmount < outputAmount {
@@ -63,7 +66,7 @@ func (tx *Tx) change(f *FeeQuote, output *changeOutput) (uint64, bool, error) {
 	}
 
 	available := inputAmount - outputAmount
-	size, err := tx.EstimateSizeWithTypes()
+	size, err := tx.EstimateSizeWithTypes(ugs...)
 	if err != nil {
 		return 0, false, err
 	}
@@ -112,6 +112,11 @@ func (tx *Tx) FromUTXOs(utxos ...*UTXO) error {
 //
 // If insufficient utxos are provided from the UTXOGetterFunc, a bt.ErrInsufficientFunds is returned.
 //
+// The fees of the inputs added are estimated using the provided bt.UnlockerGetters, as described
+// in tx.EstimateSize(...). These should be the same bt.UnlockerGetters later used to sign the tx.
+//
+// To choose which of a known set of utxos to fund the tx with, see tx.FundWithSelector(...).
+//
 // Example usage:
 //
 //	if err := tx.Fund(ctx, bt.NewFeeQuote(), func(ctx context.Context, deficit satoshis) ([]*bt.UTXO, error) {
@@ -133,8 +138,8 @@ func (tx *Tx) FromUTXOs(utxos ...*UTXO) error {
 //	    if errors.Is(err, bt.ErrInsufficientFunds) { /* handle */ }
 //	    return err
 //	}
-func (tx *Tx) Fund(ctx context.Context, fq *FeeQuote, next UTXOGetterFunc) error {
-	deficit, err := tx.estimateDeficit(fq)
+func (tx *Tx) Fund(ctx context.Context, fq *FeeQuote, next UTXOGetterFunc, ugs ...UnlockerGetter) error {
+	deficit, err := tx.estimateDeficit(ctx, fq, ugs)
 	if err != nil {
 		return err
 	}
@@ -152,7 +157,7 @@ func (tx *Tx) Fund(ctx context.Context, fq *FeeQuote, next UTXOGetterFunc) error
 			return err
 		}
 
-		deficit, err = tx.estimateDeficit(fq)
+		deficit, err = tx.estimateDeficit(ctx, fq, ugs)
 		if err != nil {
 			return err
 		}
@@ -207,8 +207,21 @@ func (tx *Tx) PayTo(script *bscript.Script, satoshis uint64) error {
 	return tx.AddP2PKHOutputFromScript(script, satoshis)
 }
 
-// PayToAddress creates a new P2PKH output from a BitCoin address (base58)
-// and the satoshis amount and adds that to the transaction.
+// PayToAddress creates a new P2PKH or P2SH output, depending on the kind of
+// BitCoin address (base58), and the satoshis amount and adds that to the transaction.
 func (tx *Tx) PayToAddress(addr string, satoshis uint64) error {
-	return tx.AddP2PKHOutputFromAddress(addr, satoshis)
+	a, err := bscript.NewAddressFromString(addr)
+	if err != nil {
+		return err
+	}
+	s, err := a.LockingScript()
+	if err != nil {
+		return err
+	}
+
+	tx.AddOutput(&Output{
+		Satoshis:      satoshis,
+		LockingScript: s,
+	})
+	return nil
 }
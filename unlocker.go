@@ -21,6 +21,16 @@ type Unlocker interface {
 	UnlockingScript(ctx context.Context, tx *Tx, up UnlockerParams) (uscript *bscript.Script, err error)
 }
 
+// UnlockerEstimator is an optional interface which a bt.Unlocker can implement to report
+// the expected length of the unlocking scripts it builds. It allows the size, and so
+// the fees, of a tx to be estimated before it is signed, for inputs which are not P2PKH.
+//
+// Estimators are consulted via the bt.UnlockerGetter provided to the likes of
+// tx.EstimateSize(...), tx.Change(...) and tx.Fund(...).
+type UnlockerEstimator interface {
+	EstimateUnlockingScriptLength(ctx context.Context, lockingScript *bscript.Script) (int, error)
+}
+
 // UnlockerGetter interfaces getting an unlocker for a given output/locking script.
 type UnlockerGetter interface {
 	Unlocker(ctx context.Context, lockingScript *bscript.Script) (Unlocker, error)
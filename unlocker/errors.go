@@ -0,0 +1,12 @@
+package unlocker
+
+import "errors"
+
+// Sentinel errors reported by the unlockers.
+var (
+	ErrUnsupportedScript    = errors.New("locking script not supported by unlocker")
+	ErrNotEnoughKeys        = errors.New("not enough private keys for the multisig locking script")
+	ErrRedeemScriptMismatch = errors.New("redeem script does not hash to the p2sh locking script")
+	ErrSecretMismatch       = errors.New("secret does not hash to the hash puzzle locking script")
+	ErrNoUnlocker           = errors.New("no unlocker provided for the redeem script")
+)
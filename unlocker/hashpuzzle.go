@@ -0,0 +1,92 @@
+package unlocker
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+	"github.com/mvc-labs/mvc-lib-go/keys/bec"
+	"github.com/mvc-labs/mvc-lib-go/keys/crypto"
+	"github.com/mvc-labs/mvc-lib-go/sighash"
+)
+
+// HashPuzzle implements the `bt.Unlocker` interface. It unlocks the hash puzzle locking
+// scripts created by tx.AddHashPuzzleOutput(...),
+// `OP_HASH160 <secret hash> OP_EQUALVERIFY OP_DUP OP_HASH160 <pubkey hash> OP_EQUALVERIFY OP_CHECKSIG`,
+// with the Secret and a signature from a bec PrivateKey.
+//
+// It also implements `bt.UnlockerGetter`, returning itself, so can be passed
+// directly to tx.FillAllInputs(...).
+type HashPuzzle struct {
+	Secret     []byte
+	PrivateKey *bec.PrivateKey
+}
+
+// Unlocker returns the `*unlocker.HashPuzzle` itself.
+func (h *HashPuzzle) Unlocker(ctx context.Context, lockingScript *bscript.Script) (bt.Unlocker, error) {
+	return h, nil
+}
+
+// UnlockingScript creates the unlocking script `<sig> <pubkey> <secret>` for the given input.
+func (h *HashPuzzle) UnlockingScript(ctx context.Context, tx *bt.Tx, params bt.UnlockerParams) (*bscript.Script, error) {
+	if params.SigHashFlags == 0 {
+		params.SigHashFlags = sighash.AllForkID
+	}
+
+	if err := h.checkSecret(tx.Inputs[params.InputIdx].PreviousTxScript); err != nil {
+		return nil, err
+	}
+
+	sig, err := signInput(tx, params, h.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &bscript.Script{}
+	if err = s.AppendPushDataArray([][]byte{sig, h.PrivateKey.PubKey().SerialiseCompressed(), h.Secret}); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// EstimateUnlockingScriptLength returns the expected length of the unlocking script,
+// being a pushed signature, compressed public key and the Secret.
+func (h *HashPuzzle) EstimateUnlockingScriptLength(ctx context.Context, lockingScript *bscript.Script) (int, error) {
+	if err := h.checkSecret(lockingScript); err != nil {
+		return 0, err
+	}
+
+	return estimatedSigPushLength + 1 + 33 + bscript.MinPushSize(h.Secret), nil
+}
+
+func (h *HashPuzzle) checkSecret(lockingScript *bscript.Script) error {
+	if !isHashPuzzle(lockingScript) {
+		return ErrUnsupportedScript
+	}
+	if !bytes.Equal((*lockingScript)[2:22], crypto.Hash160(h.Secret)) {
+		return ErrSecretMismatch
+	}
+
+	return nil
+}
+
+// isHashPuzzle returns true if the locking script matches those created by
+// tx.AddHashPuzzleOutput(...).
+func isHashPuzzle(lockingScript *bscript.Script) bool {
+	if lockingScript == nil {
+		return false
+	}
+
+	b := []byte(*lockingScript)
+	return len(b) == 48 &&
+		b[0] == bscript.OpHASH160 &&
+		b[1] == bscript.OpDATA20 &&
+		b[22] == bscript.OpEQUALVERIFY &&
+		b[23] == bscript.OpDUP &&
+		b[24] == bscript.OpHASH160 &&
+		b[25] == bscript.OpDATA20 &&
+		b[46] == bscript.OpEQUALVERIFY &&
+		b[47] == bscript.OpCHECKSIG
+}
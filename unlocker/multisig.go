@@ -0,0 +1,108 @@
+package unlocker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+	"github.com/mvc-labs/mvc-lib-go/keys/bec"
+	"github.com/mvc-labs/mvc-lib-go/sighash"
+)
+
+// MultiSig implements the `bt.Unlocker` interface. It unlocks m-of-n bare multisig
+// locking scripts, `OP_m <pubkey>... OP_n OP_CHECKMULTISIG`, collecting signatures
+// from each of the PrivateKeys found in the locking script.
+//
+// The PrivateKeys can be provided in any order, the signatures are placed in the
+// order their public keys appear in the locking script, as OP_CHECKMULTISIG expects.
+// Only the first m matching keys are used.
+//
+// It also implements `bt.UnlockerGetter`, returning itself, so can be passed
+// directly to tx.FillAllInputs(...).
+type MultiSig struct {
+	PrivateKeys []*bec.PrivateKey
+}
+
+// Unlocker returns the `*unlocker.MultiSig` itself.
+func (m *MultiSig) Unlocker(ctx context.Context, lockingScript *bscript.Script) (bt.Unlocker, error) {
+	return m, nil
+}
+
+// UnlockingScript creates the unlocking script `OP_0 <sig>...` for the given input.
+// If fewer than m of the PrivateKeys are found in the locking script, an
+// unlocker.ErrNotEnoughKeys is returned.
+func (m *MultiSig) UnlockingScript(ctx context.Context, tx *bt.Tx, params bt.UnlockerParams) (*bscript.Script, error) {
+	if params.SigHashFlags == 0 {
+		params.SigHashFlags = sighash.AllForkID
+	}
+
+	required, pubKeys, err := parseMultiSig(tx.Inputs[params.InputIdx].PreviousTxScript)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*bec.PrivateKey, 0, required)
+	for _, pubKey := range pubKeys {
+		if len(keys) == required {
+			break
+		}
+		for _, pk := range m.PrivateKeys {
+			if bytes.Equal(pubKey, pk.PubKey().SerialiseCompressed()) ||
+				bytes.Equal(pubKey, pk.PubKey().SerialiseUncompressed()) {
+				keys = append(keys, pk)
+				break
+			}
+		}
+	}
+	if len(keys) < required {
+		return nil, ErrNotEnoughKeys
+	}
+
+	sh, err := tx.CalcInputSignatureHash(params.InputIdx, params.SigHashFlags)
+	if err != nil {
+		return nil, err
+	}
+
+	// OP_CHECKMULTISIG pops one more item than it uses, so a dummy OP_0 leads.
+	s := &bscript.Script{}
+	_ = s.AppendOpcodes(bscript.OpZERO)
+	for _, pk := range keys {
+		sig, err := pk.Sign(sh)
+		if err != nil {
+			return nil, err
+		}
+		if err = s.AppendPushData(append(sig.Serialise(), uint8(params.SigHashFlags))); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// EstimateUnlockingScriptLength returns the expected length of the unlocking script,
+// being the leading OP_0 followed by m pushed signatures.
+func (m *MultiSig) EstimateUnlockingScriptLength(ctx context.Context, lockingScript *bscript.Script) (int, error) {
+	required, _, err := parseMultiSig(lockingScript)
+	if err != nil {
+		return 0, err
+	}
+
+	return 1 + required*estimatedSigPushLength, nil
+}
+
+// parseMultiSig returns the number of signatures required by, and the public keys
+// of, a bare multisig locking script.
+func parseMultiSig(lockingScript *bscript.Script) (int, [][]byte, error) {
+	if lockingScript == nil {
+		return 0, nil, ErrUnsupportedScript
+	}
+
+	required, pubKeys, err := lockingScript.MultiSig()
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: %v", ErrUnsupportedScript, err)
+	}
+
+	return required, pubKeys, nil
+}
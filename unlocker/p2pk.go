@@ -0,0 +1,57 @@
+package unlocker
+
+import (
+	"context"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+	"github.com/mvc-labs/mvc-lib-go/keys/bec"
+	"github.com/mvc-labs/mvc-lib-go/sighash"
+)
+
+// P2PK implements the `bt.Unlocker` interface. It unlocks P2PK locking scripts,
+// `<pubkey> OP_CHECKSIG`, with a signature from a bec PrivateKey.
+//
+// It also implements `bt.UnlockerGetter`, returning itself, so can be passed
+// directly to tx.FillAllInputs(...).
+type P2PK struct {
+	PrivateKey *bec.PrivateKey
+}
+
+// Unlocker returns the `*unlocker.P2PK` itself.
+func (p *P2PK) Unlocker(ctx context.Context, lockingScript *bscript.Script) (bt.Unlocker, error) {
+	return p, nil
+}
+
+// UnlockingScript creates the unlocking script `<sig>` for the given input.
+func (p *P2PK) UnlockingScript(ctx context.Context, tx *bt.Tx, params bt.UnlockerParams) (*bscript.Script, error) {
+	if params.SigHashFlags == 0 {
+		params.SigHashFlags = sighash.AllForkID
+	}
+
+	if !tx.Inputs[params.InputIdx].PreviousTxScript.IsP2PK() {
+		return nil, ErrUnsupportedScript
+	}
+
+	sig, err := signInput(tx, params, p.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &bscript.Script{}
+	if err = s.AppendPushData(sig); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// EstimateUnlockingScriptLength returns the expected length of the unlocking script,
+// being a single pushed signature.
+func (p *P2PK) EstimateUnlockingScriptLength(ctx context.Context, lockingScript *bscript.Script) (int, error) {
+	if !lockingScript.IsP2PK() {
+		return 0, ErrUnsupportedScript
+	}
+
+	return estimatedSigPushLength, nil
+}
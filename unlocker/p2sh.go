@@ -0,0 +1,96 @@
+package unlocker
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+	"github.com/mvc-labs/mvc-lib-go/keys/crypto"
+	"github.com/mvc-labs/mvc-lib-go/sighash"
+)
+
+// P2SH implements the `bt.Unlocker` interface. It unlocks P2SH locking scripts,
+// `OP_HASH160 <hash> OP_EQUAL`, by unlocking the RedeemScript with the provided
+// RedeemUnlocker, then appending the RedeemScript itself.
+//
+// The RedeemUnlocker signs as though the RedeemScript were the locking script being
+// spent, so for a multisig RedeemScript, for example, an `*unlocker.MultiSig`
+// should be provided.
+//
+// It also implements `bt.UnlockerGetter`, returning itself, so can be passed
+// directly to tx.FillAllInputs(...).
+type P2SH struct {
+	RedeemScript   *bscript.Script
+	RedeemUnlocker bt.Unlocker
+}
+
+// Unlocker returns the `*unlocker.P2SH` itself.
+func (p *P2SH) Unlocker(ctx context.Context, lockingScript *bscript.Script) (bt.Unlocker, error) {
+	return p, nil
+}
+
+// UnlockingScript creates the unlocking script `<redeem script unlocking script> <redeem script>`
+// for the given input.
+func (p *P2SH) UnlockingScript(ctx context.Context, tx *bt.Tx, params bt.UnlockerParams) (*bscript.Script, error) {
+	if params.SigHashFlags == 0 {
+		params.SigHashFlags = sighash.AllForkID
+	}
+
+	if err := p.checkRedeemScript(tx.Inputs[params.InputIdx].PreviousTxScript); err != nil {
+		return nil, err
+	}
+	if p.RedeemUnlocker == nil {
+		return nil, ErrNoUnlocker
+	}
+
+	// The redeem script is the script code signed over, so it stands in for the
+	// locking script while the redeem script is being unlocked.
+	txCopy := tx.Clone()
+	txCopy.Inputs[params.InputIdx].PreviousTxScript = p.RedeemScript
+
+	s, err := p.RedeemUnlocker.UnlockingScript(ctx, txCopy, params)
+	if err != nil {
+		return nil, err
+	}
+
+	uscript := bscript.NewFromBytes(append([]byte{}, *s...))
+	if err = uscript.AppendPushData(*p.RedeemScript); err != nil {
+		return nil, err
+	}
+
+	return uscript, nil
+}
+
+// EstimateUnlockingScriptLength returns the expected length of the unlocking script,
+// being that of the RedeemUnlocker followed by the pushed RedeemScript.
+//
+// The RedeemUnlocker must implement `bt.UnlockerEstimator`, otherwise an
+// unlocker.ErrUnsupportedScript is returned.
+func (p *P2SH) EstimateUnlockingScriptLength(ctx context.Context, lockingScript *bscript.Script) (int, error) {
+	if err := p.checkRedeemScript(lockingScript); err != nil {
+		return 0, err
+	}
+
+	e, ok := p.RedeemUnlocker.(bt.UnlockerEstimator)
+	if !ok {
+		return 0, ErrUnsupportedScript
+	}
+	l, err := e.EstimateUnlockingScriptLength(ctx, p.RedeemScript)
+	if err != nil {
+		return 0, err
+	}
+
+	return l + bscript.MinPushSize(*p.RedeemScript), nil
+}
+
+func (p *P2SH) checkRedeemScript(lockingScript *bscript.Script) error {
+	if lockingScript == nil || !lockingScript.IsP2SH() {
+		return ErrUnsupportedScript
+	}
+	if p.RedeemScript == nil || !bytes.Equal((*lockingScript)[2:22], crypto.Hash160(*p.RedeemScript)) {
+		return ErrRedeemScriptMismatch
+	}
+
+	return nil
+}
@@ -17,11 +17,21 @@ type Getter struct {
 	PrivateKey *bec.PrivateKey
 }
 
-// Unlocker builds a new `*unlocker.Local` with the same private key
-// as the calling `*local.Getter`.
+// Unlocker builds a new `bt.Unlocker` with the same private key as the calling
+// `*unlocker.Getter`, suited to the locking script: an `*unlocker.P2PK` for P2PK,
+// an `*unlocker.MultiSig` for bare multisig, and an `*unlocker.Simple` otherwise.
 //
 // For an example implementation, see `examples/unlocker_getter/`.
 func (g *Getter) Unlocker(ctx context.Context, lockingScript *bscript.Script) (bt.Unlocker, error) {
+	if lockingScript != nil {
+		switch lockingScript.ScriptType() {
+		case bscript.ScriptTypePubKey:
+			return &P2PK{PrivateKey: g.PrivateKey}, nil
+		case bscript.ScriptTypeMultiSig:
+			return &MultiSig{PrivateKeys: []*bec.PrivateKey{g.PrivateKey}}, nil
+		}
+	}
+
 	return &Simple{PrivateKey: g.PrivateKey}, nil
 }
 
@@ -70,3 +80,33 @@ func (l *Simple) UnlockingScript(ctx context.Context, tx *bt.Tx, params bt.Unloc
 
 	return nil, errors.New("currently only p2pkh supported")
 }
+
+// EstimateUnlockingScriptLength returns the expected length of the P2PKH unlocking script
+// built by the `*unlocker.Simple`, being a pushed 72 byte sig and 33 byte compressed pubkey.
+func (l *Simple) EstimateUnlockingScriptLength(ctx context.Context, lockingScript *bscript.Script) (int, error) {
+	if !lockingScript.IsP2PKH() {
+		return 0, errors.New("currently only p2pkh supported")
+	}
+
+	return estimatedSigPushLength + 1 + 33, nil
+}
+
+// estimatedSigPushLength is the length in bytes of a pushed signature, being a
+// DER signature of up to 72 bytes with the sighash flag appended.
+const estimatedSigPushLength = 1 + 72
+
+// signInput signs the signature hash of the input with the private key, returning
+// the DER signature with the sighash flag appended.
+func signInput(tx *bt.Tx, params bt.UnlockerParams, pk *bec.PrivateKey) ([]byte, error) {
+	sh, err := tx.CalcInputSignatureHash(params.InputIdx, params.SigHashFlags)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := pk.Sign(sh)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(sig.Serialise(), uint8(params.SigHashFlags)), nil
+}
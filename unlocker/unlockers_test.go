@@ -0,0 +1,208 @@
+package unlocker_test
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/mvc-labs/mvc-lib-go"
+	"github.com/mvc-labs/mvc-lib-go/bscript"
+	"github.com/mvc-labs/mvc-lib-go/bscript/interpreter"
+	"github.com/mvc-labs/mvc-lib-go/keys/bec"
+	"github.com/mvc-labs/mvc-lib-go/keys/crypto"
+	"github.com/mvc-labs/mvc-lib-go/unlocker"
+	"github.com/stretchr/testify/assert"
+)
+
+func newKeys(t *testing.T, n int) []*bec.PrivateKey {
+	keys := make([]*bec.PrivateKey, n)
+	for i := range keys {
+		pk, err := bec.NewPrivateKey(bec.S256())
+		assert.NoError(t, err)
+		keys[i] = pk
+	}
+	return keys
+}
+
+func p2pkScript(t *testing.T, pk *bec.PrivateKey) *bscript.Script {
+	s := &bscript.Script{}
+	assert.NoError(t, s.AppendPushData(pk.PubKey().SerialiseCompressed()))
+	assert.NoError(t, s.AppendOpcodes(bscript.OpCHECKSIG))
+	return s
+}
+
+func multiSigScript(t *testing.T, m int, keys []*bec.PrivateKey) *bscript.Script {
+	s := &bscript.Script{}
+	assert.NoError(t, s.AppendOpcodes(bscript.OpONE+byte(m-1)))
+	for _, pk := range keys {
+		assert.NoError(t, s.AppendPushData(pk.PubKey().SerialiseCompressed()))
+	}
+	assert.NoError(t, s.AppendOpcodes(bscript.OpONE+byte(len(keys)-1), bscript.OpCHECKMULTISIG))
+	return s
+}
+
+func p2shScript(t *testing.T, redeemScript *bscript.Script) *bscript.Script {
+	s := &bscript.Script{}
+	assert.NoError(t, s.AppendOpcodes(bscript.OpHASH160))
+	assert.NoError(t, s.AppendPushData(crypto.Hash160(*redeemScript)))
+	assert.NoError(t, s.AppendOpcodes(bscript.OpEQUAL))
+	return s
+}
+
+// spendingTx builds a tx spending an output locked by the locking script.
+func spendingTx(t *testing.T, lockingScript *bscript.Script) *bt.Tx {
+	tx := bt.NewTx()
+	assert.NoError(t, tx.From(
+		"45be95d2f2c64e99518ffbbce03fb15a7758f20ee5eecf0df07938d977add71d", 0, lockingScript.String(), 10000,
+	))
+	assert.NoError(t, tx.PayToAddress("1NRoySJ9Lvby6DuE2UQYnyT67AASwNZxGb", 9000))
+	return tx
+}
+
+func execute(tx *bt.Tx, oo ...interpreter.ExecutionOptionFunc) error {
+	return interpreter.NewEngine().Execute(append([]interpreter.ExecutionOptionFunc{
+		interpreter.WithTx(tx, 0, &bt.Output{
+			Satoshis:      tx.Inputs[0].PreviousTxSatoshis,
+			LockingScript: tx.Inputs[0].PreviousTxScript,
+		}),
+		interpreter.WithForkID(),
+	}, oo...)...)
+}
+
+// fillAndAssertEstimate fills the inputs of the tx, checking the size estimated
+// beforehand is an upper bound of the signed size, within a few bytes to allow for
+// shorter DER signatures.
+func fillAndAssertEstimate(t *testing.T, ug bt.UnlockerGetter, tx *bt.Tx) {
+	estimated, err := tx.EstimateSize(ug)
+	assert.NoError(t, err)
+
+	assert.NoError(t, tx.FillAllInputs(context.Background(), ug))
+	assert.GreaterOrEqual(t, estimated, tx.Size())
+	assert.LessOrEqual(t, estimated-tx.Size(), 8)
+}
+
+func TestP2PK(t *testing.T) {
+	t.Parallel()
+
+	keys := newKeys(t, 2)
+
+	t.Run("unlocks", func(t *testing.T) {
+		tx := spendingTx(t, p2pkScript(t, keys[0]))
+		u := &unlocker.P2PK{PrivateKey: keys[0]}
+
+		unsigned := tx.Clone()
+		fillAndAssertEstimate(t, u, tx)
+		assert.NoError(t, execute(tx, interpreter.WithAfterGenesis()))
+
+		// The getter picks the P2PK unlocker for P2PK locking scripts.
+		assert.NoError(t, unsigned.FillAllInputs(context.Background(), &unlocker.Getter{PrivateKey: keys[0]}))
+		assert.NoError(t, execute(unsigned, interpreter.WithAfterGenesis()))
+	})
+
+	t.Run("wrong key", func(t *testing.T) {
+		tx := spendingTx(t, p2pkScript(t, keys[0]))
+		assert.NoError(t, tx.FillAllInputs(context.Background(), &unlocker.P2PK{PrivateKey: keys[1]}))
+		assert.Error(t, execute(tx, interpreter.WithAfterGenesis()))
+	})
+
+	t.Run("unsupported script", func(t *testing.T) {
+		tx := spendingTx(t, multiSigScript(t, 1, keys))
+		err := tx.FillAllInputs(context.Background(), &unlocker.P2PK{PrivateKey: keys[0]})
+		assert.True(t, errors.Is(err, unlocker.ErrUnsupportedScript))
+	})
+}
+
+func TestMultiSig(t *testing.T) {
+	t.Parallel()
+
+	keys := newKeys(t, 3)
+
+	t.Run("2 of 3 with keys out of script order", func(t *testing.T) {
+		tx := spendingTx(t, multiSigScript(t, 2, keys))
+		u := &unlocker.MultiSig{PrivateKeys: []*bec.PrivateKey{keys[2], keys[0]}}
+
+		fillAndAssertEstimate(t, u, tx)
+		assert.NoError(t, execute(tx, interpreter.WithAfterGenesis()))
+	})
+
+	t.Run("more keys than required", func(t *testing.T) {
+		tx := spendingTx(t, multiSigScript(t, 2, keys))
+		assert.NoError(t, tx.FillAllInputs(context.Background(), &unlocker.MultiSig{PrivateKeys: keys}))
+		assert.NoError(t, execute(tx, interpreter.WithAfterGenesis()))
+	})
+
+	t.Run("1 of 3 via getter", func(t *testing.T) {
+		tx := spendingTx(t, multiSigScript(t, 1, keys))
+		assert.NoError(t, tx.FillAllInputs(context.Background(), &unlocker.Getter{PrivateKey: keys[1]}))
+		assert.NoError(t, execute(tx, interpreter.WithAfterGenesis()))
+	})
+
+	t.Run("not enough keys", func(t *testing.T) {
+		tx := spendingTx(t, multiSigScript(t, 2, keys))
+		err := tx.FillAllInputs(context.Background(), &unlocker.MultiSig{
+			PrivateKeys: []*bec.PrivateKey{keys[1], newKeys(t, 1)[0]},
+		})
+		assert.True(t, errors.Is(err, unlocker.ErrNotEnoughKeys))
+	})
+}
+
+func TestP2SH(t *testing.T) {
+	t.Parallel()
+
+	keys := newKeys(t, 3)
+	redeemScript := multiSigScript(t, 2, keys)
+
+	t.Run("unlocks multisig redeem script", func(t *testing.T) {
+		tx := spendingTx(t, p2shScript(t, redeemScript))
+		u := &unlocker.P2SH{
+			RedeemScript:   redeemScript,
+			RedeemUnlocker: &unlocker.MultiSig{PrivateKeys: keys[1:]},
+		}
+
+		fillAndAssertEstimate(t, u, tx)
+		assert.NoError(t, execute(tx, interpreter.WithP2SH()))
+	})
+
+	t.Run("redeem script mismatch", func(t *testing.T) {
+		tx := spendingTx(t, p2shScript(t, redeemScript))
+		err := tx.FillAllInputs(context.Background(), &unlocker.P2SH{
+			RedeemScript:   multiSigScript(t, 1, keys),
+			RedeemUnlocker: &unlocker.MultiSig{PrivateKeys: keys},
+		})
+		assert.True(t, errors.Is(err, unlocker.ErrRedeemScriptMismatch))
+	})
+
+	t.Run("no redeem unlocker", func(t *testing.T) {
+		tx := spendingTx(t, p2shScript(t, redeemScript))
+		err := tx.FillAllInputs(context.Background(), &unlocker.P2SH{RedeemScript: redeemScript})
+		assert.True(t, errors.Is(err, unlocker.ErrNoUnlocker))
+	})
+}
+
+func TestHashPuzzle(t *testing.T) {
+	t.Parallel()
+
+	key := newKeys(t, 1)[0]
+	pkh := crypto.Hash160(key.PubKey().SerialiseCompressed())
+
+	puzzleTx := func(t *testing.T) *bt.Tx {
+		prev := bt.NewTx()
+		assert.NoError(t, prev.AddHashPuzzleOutput("open sesame", hex.EncodeToString(pkh), 10000))
+		return spendingTx(t, prev.Outputs[0].LockingScript)
+	}
+
+	t.Run("unlocks", func(t *testing.T) {
+		tx := puzzleTx(t)
+		u := &unlocker.HashPuzzle{Secret: []byte("open sesame"), PrivateKey: key}
+
+		fillAndAssertEstimate(t, u, tx)
+		assert.NoError(t, execute(tx, interpreter.WithAfterGenesis()))
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		tx := puzzleTx(t)
+		err := tx.FillAllInputs(context.Background(), &unlocker.HashPuzzle{Secret: []byte("open barley"), PrivateKey: key})
+		assert.True(t, errors.Is(err, unlocker.ErrSecretMismatch))
+	})
+}